@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"7h3-3mp7y-m4n/kubectl-graph/pkg/explore"
+	"7h3-3mp7y-m4n/kubectl-graph/pkg/format"
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+var exploreCmd = &cobra.Command{
+	Use:   "explore [resource-type] [resource-name]",
+	Short: "Browse a resource and its dependencies in a full-screen terminal UI",
+	Long: `Collect the graph of a resource and browse it: an expandable tree on the
+left, the selected resource's details, labels, conditions, relationships
+or raw YAML on the right.
+
+Keys: arrows or hjkl move and open or close, / searches, n and N jump
+between matches, u shows only unhealthy resources, tab switches between
+details and YAML, J and K scroll, r collects the graph again, q quits.`,
+	Args: cobra.ExactArgs(2),
+	Run:  runExplore,
+}
+
+func init() {
+	exploreCmd.Flags().StringVarP(&namespace, "namespace", "n", "default", "Namespace of the resource")
+	exploreCmd.Flags().StringVar(&kubeconfig, "kubeconfig", "", "Path to kubeconfig file (defaults to ~/.kube/config)")
+}
+
+func runExplore(cmd *cobra.Command, args []string) {
+	if !term.IsTerminal(int(os.Stdin.Fd())) || !term.IsTerminal(int(os.Stdout.Fd())) {
+		exitWithError("explore needs a terminal", fmt.Errorf("use 'kubectl graph inspect' for non-interactive output"))
+	}
+	resourceType := args[0]
+	resourceName := args[1]
+	collect := func() (*format.Graph, error) {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		return collectGraph(ctx, resourceType, resourceName)
+	}
+	resourceGraph, err := collect()
+	if err != nil {
+		exitWithError("failed to collect resources", err)
+	}
+	if err := explore.New(resourceGraph, collect).Run(os.Stdin, os.Stdout); err != nil {
+		exitWithError("explore failed", err)
+	}
+}
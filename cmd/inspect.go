@@ -6,55 +6,230 @@ import (
 	"7h3-3mp7y-m4n/kubectl-graph/pkg/format"
 	"context"
 	"fmt"
+	"os"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
+	"golang.org/x/term"
 )
 
 var (
 	namespace  string
 	output     string
 	kubeconfig string
+	now        string
+	sortBy     string
+	showLabels bool
+	labelCols  []string
+	outputFile string
+	outputDir  string
+	colorMode  string
+	watch      bool
 )
 
 var inspectCmd = &cobra.Command{
 	Use:   "inspect [resource-type] [resource-name]",
 	Short: "Inspect a Kubernetes resource and its dependencies",
-	Args:  cobra.ExactArgs(2),
-	Run:   runInspect,
+	Args: func(cmd *cobra.Command, args []string) error {
+		// A snapshot knows the resource it was taken of, and manifests
+		// may hold a single dataset.
+		if (fromSnapshot != "" || len(filenames) > 0) && len(args) == 0 {
+			return nil
+		}
+		return cobra.ExactArgs(2)(cmd, args)
+	},
+	Run: runInspect,
 }
 
 func init() {
 	inspectCmd.Flags().StringVarP(&namespace, "namespace", "n", "default", "Namespace of the resource")
-	inspectCmd.Flags().StringVarP(&output, "output", "o", "table", "Output format: table|tree|json")
 	inspectCmd.Flags().StringVar(&kubeconfig, "kubeconfig", "", "Path to kubeconfig file (defaults to ~/.kube/config)")
+	addOutputFlags(inspectCmd)
+	inspectCmd.Flags().BoolVarP(&watch, "watch", "w", false, "Keep watching the resources and render the graph again on every change; without a terminal, print changes as JSON Lines")
+	inspectCmd.Flags().StringVar(&fromSnapshot, "from-snapshot", "", "Build the graph from an archive written by 'kubectl graph snapshot' instead of the cluster")
+	inspectCmd.Flags().StringSliceVarP(&filenames, "filename", "f", nil, "Build the graph from manifest files or directories, or - for stdin, instead of the cluster; what controllers create at runtime is marked as not yet present")
+	inspectCmd.MarkFlagsMutuallyExclusive("watch", "output-dir")
+	inspectCmd.MarkFlagsMutuallyExclusive("watch", "from-snapshot", "filename")
+	inspectCmd.MarkFlagsMutuallyExclusive("kubeconfig", "from-snapshot", "filename")
 }
 
 func runInspect(cmd *cobra.Command, args []string) {
-	resourceType := args[0]
-	resourceName := args[1]
-	if output != "table" && output != "tree" && output != "json" {
-		exitWithError("invalid output format", fmt.Errorf("must be one of: table, tree, json"))
+	var resourceType, resourceName string
+	if len(args) == 2 {
+		resourceType, resourceName = args[0], args[1]
 	}
+	if watch {
+		runWatch(resourceType, resourceName)
+		return
+	}
+	formatter := newFormatter()
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
-	k8sClient, err := client.NewClient(kubeconfig)
-	if err != nil {
-		exitWithError("failed to create kubernetes client", err)
-	}
-	var c collector.Collector
-	switch resourceType {
-	case "dataset":
-		c = collector.NewDatasetCollector(k8sClient)
+	var resourceGraph *format.Graph
+	var err error
+	switch {
+	case fromSnapshot != "":
+		resourceGraph, err = collectFromSnapshot(ctx, cmd, resourceType, resourceName)
+	case len(filenames) > 0:
+		resourceGraph, err = collectFromManifests(ctx, resourceType, resourceName)
 	default:
-		exitWithError("unsupported resource type", fmt.Errorf("type '%s' not supported yet", resourceType))
+		resourceGraph, err = collectGraph(ctx, resourceType, resourceName)
 	}
-	resourceGraph, err := c.Collect(ctx, namespace, resourceName)
 	if err != nil {
 		exitWithError("failed to collect resources", err)
 	}
-	formatter := format.NewFormatter(output)
-	if err := formatter.Format(resourceGraph); err != nil {
+	if err := writeGraph(formatter, resourceGraph); err != nil {
 		exitWithError("failed to format results", err)
 	}
 }
+
+// outputUsage lists the registered formats for --help.
+func outputUsage() string {
+	var usages []string
+	for _, info := range format.Formatters() {
+		usages = append(usages, info.Usage())
+	}
+	return fmt.Sprintf("Output format: %s, or NAME to run %sNAME from PATH", strings.Join(usages, "|"), format.ExternalFormatterPrefix)
+}
+
+// addOutputFlags registers the flags newFormatter reads.
+func addOutputFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&output, "output", "o", "table", outputUsage())
+	cmd.Flags().StringVar(&now, "now", "", "RFC3339 time to measure ages against (defaults to the collection time)")
+	cmd.Flags().StringVar(&sortBy, "sort-by", "", "Sort table rows by a column header (e.g. AGE) or a JSONPath expression (e.g. .details.restarts)")
+	cmd.Flags().BoolVar(&showLabels, "show-labels", false, "Show all labels as the last column of tables")
+	cmd.Flags().StringSliceVarP(&labelCols, "label-columns", "L", nil, "Label keys to show as table columns")
+	cmd.Flags().StringVar(&outputFile, "output-file", "", "Write the output to a file instead of stdout")
+	cmd.Flags().StringVar(&outputDir, "output-dir", "", "Write the output as several files in a directory, for formats that support it such as csv")
+	cmd.MarkFlagsMutuallyExclusive("output-file", "output-dir")
+	cmd.Flags().StringVar(&colorMode, "color", "auto", "Colorize output: auto|always|never. auto colors terminals unless NO_COLOR is set")
+}
+
+// outputIsTerminal reports whether output goes to a terminal rather than
+// to a pipe or a file.
+func outputIsTerminal() bool {
+	return outputFile == "" && outputDir == "" && term.IsTerminal(int(os.Stdout.Fd()))
+}
+
+// newFormatter builds the formatter selected by the output flags, exiting
+// on invalid values so that nothing is collected in vain.
+func newFormatter() format.Formatter {
+	return newFormatterWith(formatOptions())
+}
+
+// formatOptions returns the formatter options selected by the output
+// flags, exiting on invalid values.
+func formatOptions() format.Options {
+	opts := format.Options{
+		SortBy:       sortBy,
+		ShowLabels:   showLabels,
+		LabelColumns: labelCols,
+	}
+	// Colors and emoji are for people; pipes and files get plain text.
+	tty := outputIsTerminal()
+	opts.Color = useColor(tty)
+	opts.Emoji = tty
+	// Tables fit the terminal; COLUMNS overrides its width, or sets one for
+	// output that is not a terminal.
+	if tty {
+		if width, _, err := term.GetSize(int(os.Stdout.Fd())); err == nil {
+			opts.Width = width
+		}
+	}
+	if columns, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && columns > 0 {
+		opts.Width = columns
+	}
+	if now != "" {
+		t, err := time.Parse(time.RFC3339, now)
+		if err != nil {
+			exitWithError("invalid --now", err)
+		}
+		opts.Now = t
+	}
+	return opts
+}
+
+// useColor applies --color to output that goes to a terminal or not,
+// exiting on invalid values.
+func useColor(tty bool) bool {
+	switch colorMode {
+	case "always":
+		return true
+	case "never":
+		return false
+	case "auto":
+		return tty && os.Getenv("NO_COLOR") == ""
+	}
+	exitWithError("invalid --color", fmt.Errorf("must be one of auto, always or never, got '%s'", colorMode))
+	return false
+}
+
+// newFormatterWith builds the formatter selected by --output with opts.
+func newFormatterWith(opts format.Options) format.Formatter {
+	formatter, err := format.NewFormatter(output, opts)
+	if err != nil {
+		exitWithError("invalid output format", err)
+	}
+	if _, ok := formatter.(format.DirFormatter); outputDir != "" && !ok {
+		exitWithError("invalid --output-dir", fmt.Errorf("output format '%s' writes a single file", output))
+	}
+	return formatter
+}
+
+// writeGraph formats g to --output-dir or --output-file, or to stdout when
+// neither is set.
+func writeGraph(formatter format.Formatter, g *format.Graph) error {
+	if outputDir != "" {
+		return formatter.(format.DirFormatter).FormatDir(outputDir, g)
+	}
+	if outputFile == "" {
+		return formatter.Format(os.Stdout, g)
+	}
+	f, err := os.Create(outputFile)
+	if err != nil {
+		return err
+	}
+	if err := formatter.Format(f, g); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// collectGraph builds the graph rooted at the named resource.
+func collectGraph(ctx context.Context, resourceType, resourceName string) (*format.Graph, error) {
+	k8sClient, c, err := connect(resourceType)
+	if err != nil {
+		return nil, err
+	}
+	return collectWith(ctx, k8sClient, c, resourceName)
+}
+
+// connect creates the client from --kubeconfig and the collector for
+// resourceType.
+func connect(resourceType string) (*client.Client, collector.Collector, error) {
+	k8sClient, err := client.NewClient(kubeconfig)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create kubernetes client: %w", err)
+	}
+	c, err := collector.New(k8sClient, resourceType)
+	if err != nil {
+		return nil, nil, err
+	}
+	return k8sClient, c, nil
+}
+
+// collectWith collects the graph of the named resource with c and records
+// where it was collected from.
+func collectWith(ctx context.Context, k8sClient *client.Client, c collector.Collector, resourceName string) (*format.Graph, error) {
+	g, err := c.Collect(ctx, namespace, resourceName)
+	if err != nil {
+		return nil, err
+	}
+	g.Metadata.Cluster = k8sClient.Cluster
+	g.Metadata.Context = k8sClient.Context
+	g.Metadata.ToolVersion = version
+	return g, nil
+}
@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"7h3-3mp7y-m4n/kubectl-graph/pkg/client"
+	"7h3-3mp7y-m4n/kubectl-graph/pkg/collector"
+	"7h3-3mp7y-m4n/kubectl-graph/pkg/format"
+	"7h3-3mp7y-m4n/kubectl-graph/pkg/inventory"
+	"7h3-3mp7y-m4n/kubectl-graph/pkg/manifest"
+	"context"
+	"fmt"
+	"os"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+var filenames []string
+
+// collectFromManifests builds the graph of the named resource from the
+// objects of the -f manifests, with the same collectors as a cluster,
+// then adds what controllers will create once they are applied. Without
+// a resource, the manifests must hold exactly one dataset.
+func collectFromManifests(ctx context.Context, resourceType, resourceName string) (*format.Graph, error) {
+	objs, err := manifest.Load(filenames, os.Stdin, namespace)
+	if err != nil {
+		return nil, err
+	}
+	if resourceType == "" {
+		var datasets []*unstructured.Unstructured
+		for _, u := range objs {
+			if gvk := u.GroupVersionKind(); gvk.Group == inventory.FluidGroup && gvk.Kind == "Dataset" {
+				datasets = append(datasets, u)
+			}
+		}
+		if len(datasets) != 1 {
+			return nil, fmt.Errorf("the manifests hold %d datasets, name the resource to inspect", len(datasets))
+		}
+		resourceType, resourceName, namespace = "dataset", datasets[0].GetName(), datasets[0].GetNamespace()
+	}
+	k8sClient, err := client.NewInMemory(objs)
+	if err != nil {
+		return nil, err
+	}
+	c, err := collector.New(k8sClient, resourceType)
+	if err != nil {
+		return nil, err
+	}
+	g, err := c.Collect(ctx, namespace, resourceName)
+	if err != nil {
+		return nil, err
+	}
+	if p, ok := c.(collector.Planner); ok {
+		if err := p.Plan(ctx, namespace, resourceName, g); err != nil {
+			return nil, err
+		}
+	}
+	g.Metadata.ToolVersion = version
+	return g, nil
+}
@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"7h3-3mp7y-m4n/kubectl-graph/pkg/client"
+	"7h3-3mp7y-m4n/kubectl-graph/pkg/inventory"
+	"7h3-3mp7y-m4n/kubectl-graph/pkg/orphans"
+	"context"
+	"slices"
+	"time"
+
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var allNamespaces bool
+
+var orphansCmd = &cobra.Command{
+	Use:   "orphans",
+	Short: "Find dangling Fluid and Kubernetes resources",
+	Long: `Scan a namespace, or the whole cluster with -A, for leftovers:
+
+  - pods and PVCs labeled fluid.io/dataset=X where dataset X does not exist
+  - Fluid runtimes with no dataset of the same name
+  - ReplicaSets scaled to zero
+  - Services whose selector matches no pods
+  - PVCs no pod mounts
+  - PersistentVolumes in the Released state
+
+Use -o json to feed the list to cleanup automation. Nothing is deleted.`,
+	Example: `  kubectl graph orphans -n prod
+  kubectl graph orphans -A -o json | jq -r '.categories[] | select(.name == "datasetPods") | .items[] | "pod/\(.name) -n \(.namespace)"'`,
+	Args: cobra.NoArgs,
+	Run:  runOrphans,
+}
+
+func init() {
+	orphansCmd.Flags().StringVarP(&namespace, "namespace", "n", "default", "Namespace to scan")
+	orphansCmd.Flags().BoolVarP(&allNamespaces, "all-namespaces", "A", false, "Scan all namespaces")
+	orphansCmd.Flags().StringVar(&kubeconfig, "kubeconfig", "", "Path to kubeconfig file (defaults to ~/.kube/config)")
+	addReportFlags(orphansCmd)
+}
+
+// orphanResources are the resources the orphans checks read.
+var orphanResources = []schema.GroupResource{
+	{Resource: "pods"},
+	{Resource: "persistentvolumeclaims"},
+	{Resource: "persistentvolumes"},
+	{Resource: "services"},
+	{Group: "apps", Resource: "replicasets"},
+}
+
+func runOrphans(cmd *cobra.Command, args []string) {
+	checkReportOutput()
+	ns := namespace
+	if allNamespaces {
+		ns = ""
+	}
+	k8sClient, err := client.NewClient(kubeconfig)
+	if err != nil {
+		exitWithError("failed to create kubernetes client", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+	inv, err := inventory.LoadMatching(ctx, k8sClient, ns, func(r schema.GroupResource, _ bool) bool {
+		return r.Group == inventory.FluidGroup || slices.Contains(orphanResources, r)
+	})
+	if err != nil {
+		exitWithError("failed to list resources", err)
+	}
+	if err := writeReport(orphans.Find(inv, ns)); err != nil {
+		exitWithError("failed to write report", err)
+	}
+}
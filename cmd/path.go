@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"7h3-3mp7y-m4n/kubectl-graph/pkg/client"
+	"7h3-3mp7y-m4n/kubectl-graph/pkg/inventory"
+	"7h3-3mp7y-m4n/kubectl-graph/pkg/relations"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var pathCmd = &cobra.Command{
+	Use:   "path TYPE/NAME TYPE/NAME",
+	Short: "Explain how two resources are connected",
+	Long: `Print the shortest chain of relationships between two resources, with the
+kind of each relationship and the field it comes from: owner references,
+Ingress backends, Service selectors, EndpointSlice targets, volume claims
+and bindings, and Fluid dataset runtimes and labels.
+
+Recorded references are preferred over selector matches, so a Service
+reaches its pods through its EndpointSlices when they exist. Relationships
+are followed in both directions. When there is no path, the broken
+references around both resources are listed, such as a selector that
+matches no pods or a claim that does not exist.`,
+	Example: `  kubectl graph path ingress/web pod/web-abc
+  kubectl graph path dataset/demo pod/app-0 -n fluid -o json`,
+	Args: cobra.ExactArgs(2),
+	Run:  runPath,
+}
+
+func init() {
+	pathCmd.Flags().StringVarP(&namespace, "namespace", "n", "default", "Namespace of the resources")
+	pathCmd.Flags().StringVar(&kubeconfig, "kubeconfig", "", "Path to kubeconfig file (defaults to ~/.kube/config)")
+	addReportFlags(pathCmd)
+}
+
+func runPath(cmd *cobra.Command, args []string) {
+	checkReportOutput()
+	k8sClient, err := client.NewClient(kubeconfig)
+	if err != nil {
+		exitWithError("failed to create kubernetes client", err)
+	}
+	var resources [2]struct {
+		gvr  schema.GroupVersionResource
+		name string
+	}
+	for i, arg := range args {
+		resourceType, resourceName := parseResourceArgs([]string{arg})
+		gvr, err := inventory.Resolve(k8sClient, resourceType)
+		if err != nil {
+			exitWithError("invalid resource", err)
+		}
+		resources[i].gvr, resources[i].name = gvr, resourceName
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+	inv, err := inventory.Load(ctx, k8sClient, namespace, "persistentvolumes", resources[0].gvr.Resource, resources[1].gvr.Resource)
+	if err != nil {
+		exitWithError("failed to list resources", err)
+	}
+	var objs [2]*inventory.Object
+	for i, res := range resources {
+		o, ok := inv.Lookup(res.gvr.GroupResource(), namespace, res.name)
+		if !ok {
+			o, ok = inv.Lookup(res.gvr.GroupResource(), "", res.name)
+		}
+		if !ok {
+			exitWithError("resource not found", fmt.Errorf("%s '%s' not found in namespace '%s'", res.gvr.GroupResource(), res.name, namespace))
+		}
+		objs[i] = o
+	}
+	if err := writeReport(relations.Path(inv, objs[0], objs[1])); err != nil {
+		exitWithError("failed to write report", err)
+	}
+}
@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"7h3-3mp7y-m4n/kubectl-graph/pkg/query"
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var queryCmd = &cobra.Command{
+	Use:   "query [resource-type]/[resource-name] [expression]",
+	Short: "Select resources from a collected graph with a query expression",
+	Long: `Collect the graph rooted at a resource and print the nodes matching an expression.
+
+Terms select nodes by kind and attributes, arrows follow edges of any type
+('->', '<-') or of a given type ('-volumeMount->', '<-labelSelector-'),
+functions expand a selection and '|' filters the result. Only the matching
+nodes are printed, the root included only if it matches:
+
+  Pod[status!=Running] <-labelSelector- Dataset
+  PVC <- Pod
+  descendants(Runtime) | kind=PVC
+  *[label.app=web, name=~'^web-']`,
+	Example: `  kubectl graph query dataset/imagenet 'Pod[status!=Running] <-labelSelector- Dataset'
+  kubectl graph query dataset/imagenet 'Pod[status!=Running] <- Dataset' -o name
+  kubectl graph query dataset/imagenet 'descendants(Runtime) | kind=PVC' -o json`,
+	Args: cobra.ExactArgs(2),
+	Run:  runQuery,
+}
+
+func init() {
+	queryCmd.Flags().StringVarP(&namespace, "namespace", "n", "default", "Namespace of the resource")
+	queryCmd.Flags().StringVar(&kubeconfig, "kubeconfig", "", "Path to kubeconfig file (defaults to ~/.kube/config)")
+	addOutputFlags(queryCmd)
+}
+
+func runQuery(cmd *cobra.Command, args []string) {
+	resourceType, resourceName, ok := strings.Cut(args[0], "/")
+	if !ok || resourceName == "" {
+		exitWithError("invalid resource", fmt.Errorf("expected TYPE/NAME, got '%s'", args[0]))
+	}
+	formatter := newFormatter()
+	q, err := query.Parse(args[1])
+	if err != nil {
+		exitWithError("invalid query", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	resourceGraph, err := collectGraph(ctx, resourceType, resourceName)
+	if err != nil {
+		exitWithError("failed to collect resources", err)
+	}
+	result := resourceGraph.Subgraph(q.Eval(resourceGraph))
+	if err := writeGraph(formatter, result); err != nil {
+		exitWithError("failed to format results", err)
+	}
+}
@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"7h3-3mp7y-m4n/kubectl-graph/pkg/format"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+var renderCmd = &cobra.Command{
+	Use:   "render [file]",
+	Short: "Render a graph previously saved with -o json or -o yaml",
+	Long: `Read a graph document written by -o json or -o yaml, validate it against the schema
+printed by 'kubectl graph schema' and render it in another output format.
+The document is read from stdin when no file or '-' is given.`,
+	Args: cobra.MaximumNArgs(1),
+	Run:  runRender,
+}
+
+func init() {
+	addOutputFlags(renderCmd)
+}
+
+func runRender(cmd *cobra.Command, args []string) {
+	formatter := newFormatter()
+	var in io.Reader = os.Stdin
+	if len(args) == 1 && args[0] != "-" {
+		f, err := os.Open(args[0])
+		if err != nil {
+			exitWithError("failed to open graph document", err)
+		}
+		defer f.Close()
+		in = f
+	}
+	doc, err := format.ReadDocument(in)
+	if err != nil {
+		exitWithError("failed to read graph document", err)
+	}
+	resourceGraph, err := doc.Graph()
+	if err != nil {
+		exitWithError("invalid graph document", err)
+	}
+	if err := writeGraph(formatter, resourceGraph); err != nil {
+		exitWithError("failed to format results", err)
+	}
+}
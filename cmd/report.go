@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/yaml"
+)
+
+// report is the result of a command that prints findings rather than a
+// graph, such as impact.
+type report interface {
+	// Print writes the report for people, in color when colored is set.
+	Print(w io.Writer, colored bool) error
+}
+
+// reportOutput is the -o value of report commands.
+var reportOutput string
+
+// addReportFlags registers the flags writeReport reads.
+func addReportFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&reportOutput, "output", "o", "", "Output format: json|yaml. The default is a report for people")
+	cmd.Flags().StringVar(&colorMode, "color", "auto", "Colorize output: auto|always|never. auto colors terminals unless NO_COLOR is set")
+}
+
+// checkReportOutput exits on an invalid -o before anything is collected.
+func checkReportOutput() {
+	switch reportOutput {
+	case "", "json", "yaml":
+	default:
+		exitWithError("invalid output format", fmt.Errorf("must be json or yaml, got '%s'", reportOutput))
+	}
+}
+
+// writeReport writes r to stdout as selected by -o.
+func writeReport(r report) error {
+	switch reportOutput {
+	case "json":
+		data, err := json.MarshalIndent(r, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Println(string(data))
+		return err
+	case "yaml":
+		data, err := yaml.Marshal(r)
+		if err != nil {
+			return err
+		}
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+	return r.Print(os.Stdout, useColor(outputIsTerminal()))
+}
@@ -7,9 +7,13 @@ import (
 	"github.com/spf13/cobra"
 )
 
+// version is set at build time with -ldflags "-X 7h3-3mp7y-m4n/kubectl-graph/cmd.version=...".
+var version = "dev"
+
 var rootCmd = &cobra.Command{
-	Use:   "kubectl-graph",
-	Short: "Test Attempt for Visualize Kubernetes resource relationships",
+	Use:     "kubectl-graph",
+	Short:   "Test Attempt for Visualize Kubernetes resource relationships",
+	Version: version,
 }
 
 func Execute() error {
@@ -18,6 +22,15 @@ func Execute() error {
 
 func init() {
 	rootCmd.AddCommand(inspectCmd)
+	rootCmd.AddCommand(exploreCmd)
+	rootCmd.AddCommand(impactCmd)
+	rootCmd.AddCommand(orphansCmd)
+	rootCmd.AddCommand(pathCmd)
+	rootCmd.AddCommand(queryCmd)
+	rootCmd.AddCommand(renderCmd)
+	rootCmd.AddCommand(schemaCmd)
+	rootCmd.AddCommand(serveCmd)
+	rootCmd.AddCommand(snapshotCmd)
 }
 
 func exitWithError(msg string, err error) {
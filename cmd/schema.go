@@ -0,0 +1,24 @@
+package cmd
+
+import (
+	"7h3-3mp7y-m4n/kubectl-graph/pkg/format"
+	"encoding/json"
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+var schemaCmd = &cobra.Command{
+	Use:   "schema",
+	Short: "Print the JSON Schema of the graph document written by -o json",
+	Args:  cobra.NoArgs,
+	Run:   runSchema,
+}
+
+func runSchema(cmd *cobra.Command, args []string) {
+	data, err := json.MarshalIndent(format.Schema(), "", "  ")
+	if err != nil {
+		exitWithError("failed to generate schema", err)
+	}
+	fmt.Println(string(data))
+}
@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"7h3-3mp7y-m4n/kubectl-graph/pkg/client"
+	"7h3-3mp7y-m4n/kubectl-graph/pkg/server"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	servePort    int
+	serveAddress string
+)
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serve resource graphs over a local HTTP API",
+	Long: `Serve the graphs of resources over HTTP, collected from the cluster on
+every request:
+
+  GET /api/v1/graph/{type}/{namespace}/{name}?format=json|dot|svg|mermaid|...
+
+The index page at / has a form to browse graphs. The server listens on
+localhost unless --address says otherwise; it has no authentication and
+reads the cluster with your credentials.`,
+	Args: cobra.NoArgs,
+	Run:  runServe,
+}
+
+func init() {
+	serveCmd.Flags().IntVarP(&servePort, "port", "p", 8080, "Port to listen on")
+	serveCmd.Flags().StringVar(&serveAddress, "address", "127.0.0.1", "Address to listen on")
+	serveCmd.Flags().StringVar(&kubeconfig, "kubeconfig", "", "Path to kubeconfig file (defaults to ~/.kube/config)")
+}
+
+func runServe(cmd *cobra.Command, args []string) {
+	k8sClient, err := client.NewClient(kubeconfig)
+	if err != nil {
+		exitWithError("failed to create kubernetes client", err)
+	}
+	srv := &http.Server{
+		Addr:              net.JoinHostPort(serveAddress, strconv.Itoa(servePort)),
+		Handler:           server.New(k8sClient, version),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	listener, err := net.Listen("tcp", srv.Addr)
+	if err != nil {
+		exitWithError("failed to listen", err)
+	}
+	fmt.Fprintf(os.Stderr, "Serving graphs on http://%s/\n", listener.Addr())
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		srv.Shutdown(shutdownCtx)
+	}()
+	if err := srv.Serve(listener); !errors.Is(err, http.ErrServerClosed) {
+		exitWithError("server failed", err)
+	}
+}
@@ -0,0 +1,126 @@
+package cmd
+
+import (
+	"7h3-3mp7y-m4n/kubectl-graph/pkg/collector"
+	"7h3-3mp7y-m4n/kubectl-graph/pkg/format"
+	"7h3-3mp7y-m4n/kubectl-graph/pkg/snapshot"
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	snapshotOutput string
+	fromSnapshot   string
+)
+
+var snapshotCmd = &cobra.Command{
+	Use:   "snapshot [resource-type] [resource-name]",
+	Short: "Save a resource graph and its objects to an archive for offline inspection",
+	Long: `Collect the graph of a resource and write a gzipped tar archive holding
+the graph, the YAML of every object in it, the events about those objects
+and where and when they were collected.
+
+Objects are sanitized before they are written: managed fields, the last
+applied configuration and the data of Secrets are removed, and so are the
+values of fields and env entries whose name looks like a credential, such
+as passwords, tokens and access keys. metadata.json lists every field that
+was redacted.
+
+Read the archive back without a cluster with 'kubectl graph inspect
+--from-snapshot', in any output format.`,
+	Example: `  kubectl graph snapshot dataset demo -n fluid -o snapshot.tar.gz
+  kubectl graph inspect --from-snapshot snapshot.tar.gz -o svg`,
+	Args: cobra.ExactArgs(2),
+	Run:  runSnapshot,
+}
+
+func init() {
+	snapshotCmd.Flags().StringVarP(&namespace, "namespace", "n", "default", "Namespace of the resource")
+	snapshotCmd.Flags().StringVar(&kubeconfig, "kubeconfig", "", "Path to kubeconfig file (defaults to ~/.kube/config)")
+	snapshotCmd.Flags().StringVarP(&snapshotOutput, "output", "o", "snapshot.tar.gz", "Archive to write, or - for stdout")
+}
+
+func runSnapshot(cmd *cobra.Command, args []string) {
+	resourceType := args[0]
+	resourceName := args[1]
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	k8sClient, c, err := connect(resourceType)
+	if err != nil {
+		exitWithError("failed to collect resources", err)
+	}
+	resourceGraph, err := collectWith(ctx, k8sClient, c, resourceName)
+	if err != nil {
+		exitWithError("failed to collect resources", err)
+	}
+	s, err := snapshot.Take(ctx, k8sClient, resourceGraph, resourceType, namespace, resourceName)
+	if err != nil {
+		exitWithError("failed to collect events", err)
+	}
+
+	if snapshotOutput == "-" {
+		if err := s.Write(os.Stdout); err != nil {
+			exitWithError("failed to write archive", err)
+		}
+		return
+	}
+	f, err := os.Create(snapshotOutput)
+	if err != nil {
+		exitWithError("failed to create archive", err)
+	}
+	if err := s.Write(f); err != nil {
+		f.Close()
+		exitWithError("failed to write archive", err)
+	}
+	if err := f.Close(); err != nil {
+		exitWithError("failed to write archive", err)
+	}
+	fmt.Fprintf(os.Stderr, "Wrote %d objects and %d events to %s, %d fields redacted\n",
+		s.Metadata.Objects, s.Metadata.Events, snapshotOutput, len(s.Metadata.Redacted))
+}
+
+// collectFromSnapshot builds the graph of the named resource from the
+// objects of the --from-snapshot archive, with the same collectors as a
+// cluster. Without a resource, the graph the archive was taken of is
+// built again, in the namespace it was taken in unless -n says otherwise.
+func collectFromSnapshot(ctx context.Context, cmd *cobra.Command, resourceType, resourceName string) (*format.Graph, error) {
+	f, err := os.Open(fromSnapshot)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	s, err := snapshot.Read(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", fromSnapshot, err)
+	}
+	if resourceType == "" {
+		resourceType, resourceName = s.Metadata.ResourceType, s.Metadata.Name
+	}
+	if !cmd.Flags().Changed("namespace") {
+		namespace = s.Metadata.Namespace
+	}
+	k8sClient, err := s.Client()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load %s: %w", fromSnapshot, err)
+	}
+	c, err := collector.New(k8sClient, resourceType)
+	if err != nil {
+		return nil, err
+	}
+	g, err := c.Collect(ctx, namespace, resourceName)
+	if err != nil {
+		return nil, err
+	}
+	// Ages are measured against the time the snapshot was taken.
+	g.Metadata = format.Metadata{
+		Cluster:     s.Metadata.Cluster,
+		Context:     s.Metadata.Context,
+		CollectedAt: s.Metadata.CollectedAt,
+		ToolVersion: s.Metadata.ToolVersion,
+	}
+	return g, nil
+}
@@ -0,0 +1,161 @@
+package cmd
+
+import (
+	"7h3-3mp7y-m4n/kubectl-graph/pkg/collector"
+	"7h3-3mp7y-m4n/kubectl-graph/pkg/format"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// watchDebounce is how long inspect --watch waits after a change for more
+// to arrive before collecting again. Changes come in bursts, such as a pod
+// going through its phases as it starts.
+const watchDebounce = 200 * time.Millisecond
+
+// runWatch implements inspect --watch. On a terminal it redraws the tree
+// on every change, marking the resources that changed; otherwise it writes
+// one JSON object per added, updated or deleted node or edge, starting
+// with the whole graph as additions.
+func runWatch(resourceType, resourceName string) {
+	opts := formatOptions()
+	tty := outputIsTerminal()
+	if tty {
+		// Fail on an invalid format before watching anything.
+		newFormatterWith(opts)
+	}
+	k8sClient, c, err := connect(resourceType)
+	if err != nil {
+		exitWithError("failed to collect resources", err)
+	}
+	watcher, ok := c.(collector.Watcher)
+	if !ok {
+		exitWithError("failed to watch resources", fmt.Errorf("type '%s' cannot be watched", resourceType))
+	}
+
+	out := io.Writer(os.Stdout)
+	if outputFile != "" {
+		f, err := os.Create(outputFile)
+		if err != nil {
+			exitWithError("failed to open --output-file", err)
+		}
+		defer f.Close()
+		out = f
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	changed := make(chan struct{}, 1)
+	watchErr := make(chan error, 1)
+	go func() {
+		watchErr <- watcher.Watch(ctx, namespace, resourceName, func() {
+			select {
+			case changed <- struct{}{}:
+			default:
+			}
+		})
+	}()
+
+	var prev *format.Graph
+	for {
+		collectCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+		g, err := collectWith(collectCtx, k8sClient, c, resourceName)
+		cancel()
+		if ctx.Err() != nil {
+			return
+		}
+		// A deleted root leaves an empty graph, so that its removal is
+		// reported like any other.
+		if apierrors.IsNotFound(err) {
+			g, err = format.NewGraph(nil), nil
+		}
+		if err == nil {
+			if tty {
+				err = drawWatchFrame(out, opts, resourceType, resourceName, prev, g)
+			} else {
+				err = writeChanges(out, format.Diff(prev, g))
+			}
+			if err != nil {
+				exitWithError("failed to format results", err)
+			}
+			prev = g
+		} else if tty {
+			fmt.Fprintf(out, "\x1b[H\x1b[2J%s\n\nfailed to collect resources: %v\n", watchTitle(resourceType, resourceName, time.Now()), err)
+		} else {
+			fmt.Fprintf(os.Stderr, "failed to collect resources: %v\n", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case err := <-watchErr:
+			if err != nil {
+				exitWithError("failed to watch resources", err)
+			}
+			return
+		case <-changed:
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(watchDebounce):
+		}
+		select {
+		case <-changed:
+		default:
+		}
+	}
+}
+
+func watchTitle(resourceType, resourceName string, at time.Time) string {
+	return fmt.Sprintf("Watching %s %s/%s, updated %s. Press Ctrl-C to stop.",
+		resourceType, namespace, resourceName, at.Local().Format("15:04:05"))
+}
+
+// drawWatchFrame clears the terminal and renders g with the nodes that
+// changed since prev highlighted. The first frame highlights nothing.
+func drawWatchFrame(out io.Writer, opts format.Options, resourceType, resourceName string, prev, g *format.Graph) error {
+	changes := format.Diff(prev, g)
+	opts.Highlight = make(map[string]bool)
+	if prev != nil {
+		for _, change := range changes {
+			if change.Node != nil && change.Op != format.ChangeDelete {
+				opts.Highlight[change.Node.ID] = true
+			}
+		}
+	}
+
+	// The frame is drawn at once to avoid flicker.
+	var buf bytes.Buffer
+	buf.WriteString("\x1b[H\x1b[2J")
+	buf.WriteString(watchTitle(resourceType, resourceName, g.Metadata.CollectedAt))
+	if prev != nil {
+		fmt.Fprintf(&buf, " %d changes.", len(changes))
+	}
+	buf.WriteString("\n")
+	if g.Root == nil {
+		fmt.Fprintf(&buf, "\n%s %s/%s not found\n", resourceType, namespace, resourceName)
+	} else if err := newFormatterWith(opts).Format(&buf, g); err != nil {
+		return err
+	}
+	_, err := out.Write(buf.Bytes())
+	return err
+}
+
+// writeChanges writes changes as JSON Lines.
+func writeChanges(out io.Writer, changes []format.Change) error {
+	enc := json.NewEncoder(out)
+	for _, change := range changes {
+		if err := enc.Encode(change); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -11,12 +11,18 @@ import (
 )
 
 type Client struct {
-	Client        *kubernetes.Clientset
+	// Client and DynamicClient are interfaces so that the fake clients of
+	// client-go can stand in for a cluster, see NewFromClients.
+	Client        kubernetes.Interface
 	DynamicClient dynamic.Interface
+	// Context and Cluster name the kubeconfig entries in use. Both are
+	// empty when running in-cluster.
+	Context string
+	Cluster string
 }
 
 func NewClient(kubeconfig string) (*Client, error) {
-	config, err := getConfig(kubeconfig)
+	config, source, err := getConfig(kubeconfig)
 	if err != nil {
 		return nil, err
 	}
@@ -28,23 +34,47 @@ func NewClient(kubeconfig string) (*Client, error) {
 	if err != nil {
 		return nil, err
 	}
-	return &Client{
+	c := &Client{
 		Client:        clientset,
 		DynamicClient: dynamicClient,
-	}, nil
+	}
+	if source != "" {
+		c.Context, c.Cluster = currentContext(source)
+	}
+	return c, nil
 }
 
-func getConfig(kubeconfig string) (*rest.Config, error) {
+// NewFromClients wraps existing clients, such as the fakes from
+// k8s.io/client-go/kubernetes/fake and k8s.io/client-go/dynamic/fake.
+func NewFromClients(clientset kubernetes.Interface, dynamicClient dynamic.Interface) *Client {
+	return &Client{Client: clientset, DynamicClient: dynamicClient}
+}
+
+// getConfig returns the REST config and the kubeconfig path it was read
+// from, which is empty for in-cluster configuration.
+func getConfig(kubeconfig string) (*rest.Config, string, error) {
 	if config, err := rest.InClusterConfig(); err == nil {
-		return config, nil
+		return config, "", nil
 	}
 	if kubeconfig == "" {
 		home, err := os.UserHomeDir()
 		if err != nil {
-			return nil, err
+			return nil, "", err
 		}
 		kubeconfig = filepath.Join(home, ".kube", "config")
 	}
 
-	return clientcmd.BuildConfigFromFlags("", kubeconfig)
+	config, err := clientcmd.BuildConfigFromFlags("", kubeconfig)
+	return config, kubeconfig, err
+}
+
+func currentContext(kubeconfig string) (string, string) {
+	raw, err := clientcmd.LoadFromFile(kubeconfig)
+	if err != nil {
+		return "", ""
+	}
+	if ctx, ok := raw.Contexts[raw.CurrentContext]; ok {
+		return raw.CurrentContext, ctx.Cluster
+	}
+	return raw.CurrentContext, ""
 }
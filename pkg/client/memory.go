@@ -0,0 +1,75 @@
+package client
+
+import (
+	"fmt"
+	"sort"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/kubernetes/scheme"
+)
+
+// NewInMemory returns a client serving objs from memory instead of a
+// cluster, so that collectors can run against a snapshot or manifests.
+// Every object is served by the dynamic client, and the ones of built-in
+// kinds by the typed client as well. Discovery lists the resources of the
+// objects, named as the fake clients name them. Writes only change the
+// memory.
+func NewInMemory(objs []*unstructured.Unstructured) (*Client, error) {
+	var typed, dynamic []runtime.Object
+	listKinds := make(map[schema.GroupVersionResource]string)
+	resources := make(map[schema.GroupVersion]map[string]metav1.APIResource)
+	for _, u := range objs {
+		gvk := u.GroupVersionKind()
+		if gvk.Kind == "" || gvk.Version == "" {
+			return nil, fmt.Errorf("object '%s' has no apiVersion or kind", u.GetName())
+		}
+		gvr, _ := meta.UnsafeGuessKindToResource(gvk)
+		listKinds[gvr] = gvk.Kind + "List"
+		if resources[gvr.GroupVersion()] == nil {
+			resources[gvr.GroupVersion()] = make(map[string]metav1.APIResource)
+		}
+		resources[gvr.GroupVersion()][gvr.Resource] = metav1.APIResource{
+			Name:       gvr.Resource,
+			Kind:       gvk.Kind,
+			Namespaced: u.GetNamespace() != "",
+			Verbs:      metav1.Verbs{"get", "list", "watch"},
+		}
+		dynamic = append(dynamic, u.DeepCopy())
+
+		obj, err := scheme.Scheme.New(gvk)
+		if err != nil {
+			// Custom resources, such as Fluid's, are only served by the
+			// dynamic client.
+			continue
+		}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, obj); err != nil {
+			return nil, fmt.Errorf("invalid %s '%s': %w", gvk.Kind, u.GetName(), err)
+		}
+		typed = append(typed, obj)
+	}
+
+	clientset := fake.NewClientset(typed...)
+	discovery := clientset.Discovery().(*fakediscovery.FakeDiscovery)
+	for gv, byName := range resources {
+		list := &metav1.APIResourceList{GroupVersion: gv.String()}
+		for _, r := range byName {
+			list.APIResources = append(list.APIResources, r)
+		}
+		sort.Slice(list.APIResources, func(i, j int) bool {
+			return list.APIResources[i].Name < list.APIResources[j].Name
+		})
+		discovery.Resources = append(discovery.Resources, list)
+	}
+	sort.Slice(discovery.Resources, func(i, j int) bool {
+		return discovery.Resources[i].GroupVersion < discovery.Resources[j].GroupVersion
+	})
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), listKinds, dynamic...)
+	return NewFromClients(clientset, dynamicClient), nil
+}
@@ -0,0 +1,49 @@
+package collector
+
+import (
+	"7h3-3mp7y-m4n/kubectl-graph/pkg/format"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func convertConfigMapToResource(cm *corev1.ConfigMap) *format.Resource {
+	return &format.Resource{
+		Type:              format.ResourceTypeConfigMap,
+		Kind:              "ConfigMap",
+		Name:              cm.Name,
+		Namespace:         cm.Namespace,
+		Status:            "Active", // ConfigMaps don't have a phase
+		CreationTimestamp: cm.CreationTimestamp.Time,
+		DeletionTimestamp: timePtr(cm.DeletionTimestamp),
+		Details: []format.Detail{
+			format.IntDetail("keys", int64(len(cm.Data)+len(cm.BinaryData)), "", format.DetailPriorityPrimary),
+		},
+		Labels: cm.Labels,
+		Object: objectOf(cm, "v1", "ConfigMap"),
+	}
+}
+
+func convertSecretToResource(secret *corev1.Secret) *format.Resource {
+	// The graph only needs to know the Secret exists, so its values are
+	// not kept.
+	object := objectOf(secret, "v1", "Secret")
+	unstructured.RemoveNestedField(object, "data")
+	unstructured.RemoveNestedField(object, "stringData")
+
+	return &format.Resource{
+		Type:              format.ResourceTypeSecret,
+		Kind:              "Secret",
+		Name:              secret.Name,
+		Namespace:         secret.Namespace,
+		Status:            "Active", // Secrets don't have a phase
+		CreationTimestamp: secret.CreationTimestamp.Time,
+		DeletionTimestamp: timePtr(secret.DeletionTimestamp),
+		Details: []format.Detail{
+			format.IntDetail("keys", int64(len(secret.Data)), "", format.DetailPriorityPrimary),
+			format.StringDetail("type", string(secret.Type), format.DetailPrioritySecondary),
+		},
+		Labels: secret.Labels,
+		Object: object,
+	}
+}
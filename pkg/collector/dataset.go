@@ -5,7 +5,9 @@ import (
 	"7h3-3mp7y-m4n/kubectl-graph/pkg/format"
 	"context"
 	"fmt"
+	"time"
 
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -19,12 +21,33 @@ type Collector interface {
 	Collect(ctx context.Context, namespace, name string) (*format.Graph, error)
 }
 
+// New returns the collector for graphs rooted at a resource of the given
+// type, such as "dataset".
+func New(c *client.Client, resourceType string) (Collector, error) {
+	t, _ := format.ParseResourceType(resourceType)
+	switch t {
+	case format.ResourceTypeDataset:
+		return NewDatasetCollector(c), nil
+	default:
+		return nil, fmt.Errorf("type '%s' not supported yet", resourceType)
+	}
+}
+
 var datasetGVR = schema.GroupVersionResource{
 	Group:    "data.fluid.io",
 	Version:  "v1alpha1",
 	Resource: "datasets",
 }
 
+// runtimeGVRs are the Fluid runtimes a dataset can be bound to. A bound
+// runtime has the dataset's name.
+var runtimeGVRs = []schema.GroupVersionResource{
+	{Group: "data.fluid.io", Version: "v1alpha1", Resource: "alluxioruntimes"},
+	{Group: "data.fluid.io", Version: "v1alpha1", Resource: "jindoruntimes"},
+	{Group: "data.fluid.io", Version: "v1alpha1", Resource: "juicefsruntimes"},
+	{Group: "data.fluid.io", Version: "v1alpha1", Resource: "goosefsruntimes"},
+}
+
 // DatasetCollector collects Fluid dataset resources
 type DatasetCollector struct {
 	client *client.Client
@@ -44,32 +67,60 @@ func (dc *DatasetCollector) Collect(ctx context.Context, namespace, name string)
 	// Create graph with dataset as root
 	g := format.NewGraph(dataset)
 
+	selector := fmt.Sprintf("%s=%s", fluidDatasetLabel, name)
+
 	// Collect runtime
 	runtime, err := dc.getRuntime(ctx, namespace, name)
 	if err == nil && runtime != nil {
 		g.AddResource(runtime)
-		g.AddEdge(dataset, runtime, "owns")
+		g.AddEdge(dataset, runtime, format.EdgeTypeRuntimeBinding, fmt.Sprintf("metadata.name=%s", name))
 	}
 
-	// Collect pods
-	pods, err := dc.getPods(ctx, namespace, name)
+	// Volumes, ConfigMaps, Secrets and nodes are shared, so each is read
+	// once however many objects refer to it.
+	related := make(map[string]*format.Resource)
+
+	// Collect PVCs and the volumes they are bound to
+	claims := make(map[string]*format.Resource)
+	pvcs, err := dc.getPVCs(ctx, namespace, name)
 	if err == nil {
-		for _, pod := range pods {
-			g.AddResource(pod)
-			if runtime != nil {
-				g.AddEdge(runtime, pod, "manages")
-			} else {
-				g.AddEdge(dataset, pod, "owns")
+		for i := range pvcs {
+			pvc := convertPVCToResource(&pvcs[i])
+			g.AddResource(pvc)
+			g.AddEdge(dataset, pvc, format.EdgeTypeLabelSelector, selector)
+			claims[pvc.Name] = pvc
+			if pv := dc.getRelated(ctx, g, related, format.ResourceTypePV, "", pvcs[i].Spec.VolumeName); pv != nil {
+				g.AddEdge(pvc, pv, format.EdgeTypeVolumeBinding, "spec.volumeName")
 			}
 		}
 	}
 
-	// Collect PVCs
-	pvcs, err := dc.getPVCs(ctx, namespace, name)
+	// Collect pods
+	pods, err := dc.getPods(ctx, namespace, name)
 	if err == nil {
-		for _, pvc := range pvcs {
-			g.AddResource(pvc)
-			g.AddEdge(dataset, pvc, "references")
+		for i := range pods {
+			pod := convertPodToResource(&pods[i])
+			g.AddResource(pod)
+			// The label names the dataset, so the edge starts there even
+			// when the runtime's controllers created the pod.
+			g.AddEdge(dataset, pod, format.EdgeTypeLabelSelector, selector)
+			for j, vol := range pods[i].Spec.Volumes {
+				if vol.PersistentVolumeClaim == nil {
+					continue
+				}
+				if pvc, ok := claims[vol.PersistentVolumeClaim.ClaimName]; ok {
+					g.AddEdge(pod, pvc, format.EdgeTypeVolumeMount,
+						fmt.Sprintf("spec.volumes[%d].persistentVolumeClaim.claimName", j))
+				}
+			}
+			for _, ref := range configReferences(&pods[i]) {
+				if res := dc.getRelated(ctx, g, related, ref.resourceType, namespace, ref.name); res != nil {
+					g.AddEdge(pod, res, format.EdgeTypeConfigRef, ref.path)
+				}
+			}
+			if node := dc.getRelated(ctx, g, related, format.ResourceTypeNode, "", pods[i].Spec.NodeName); node != nil {
+				g.AddEdge(pod, node, format.EdgeTypeScheduledOn, "spec.nodeName")
+			}
 		}
 	}
 
@@ -78,11 +129,7 @@ func (dc *DatasetCollector) Collect(ctx context.Context, namespace, name string)
 	if err == nil {
 		for _, svc := range services {
 			g.AddResource(svc)
-			if runtime != nil {
-				g.AddEdge(runtime, svc, "exposes")
-			} else {
-				g.AddEdge(dataset, svc, "owns")
-			}
+			g.AddEdge(dataset, svc, format.EdgeTypeLabelSelector, selector)
 		}
 	}
 
@@ -101,14 +148,7 @@ func (dc *DatasetCollector) getDataset(ctx context.Context, namespace, name stri
 }
 
 func (dc *DatasetCollector) getRuntime(ctx context.Context, namespace, name string) (*format.Resource, error) {
-	runtimeTypes := []schema.GroupVersionResource{
-		{Group: "data.fluid.io", Version: "v1alpha1", Resource: "alluxioruntimes"},
-		{Group: "data.fluid.io", Version: "v1alpha1", Resource: "jindoruntimes"},
-		{Group: "data.fluid.io", Version: "v1alpha1", Resource: "juicefsruntimes"},
-		{Group: "data.fluid.io", Version: "v1alpha1", Resource: "goosefsruntimes"},
-	}
-
-	for _, gvr := range runtimeTypes {
+	for _, gvr := range runtimeGVRs {
 		obj, err := dc.client.DynamicClient.Resource(gvr).
 			Namespace(namespace).
 			Get(ctx, name, metav1.GetOptions{})
@@ -120,7 +160,7 @@ func (dc *DatasetCollector) getRuntime(ctx context.Context, namespace, name stri
 	return nil, fmt.Errorf("no runtime found")
 }
 
-func (dc *DatasetCollector) getPods(ctx context.Context, namespace, datasetName string) ([]*format.Resource, error) {
+func (dc *DatasetCollector) getPods(ctx context.Context, namespace, datasetName string) ([]corev1.Pod, error) {
 	labelSelector := fmt.Sprintf("%s=%s", fluidDatasetLabel, datasetName)
 	podList, err := dc.client.Client.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{
 		LabelSelector: labelSelector,
@@ -129,15 +169,10 @@ func (dc *DatasetCollector) getPods(ctx context.Context, namespace, datasetName
 		return nil, err
 	}
 
-	pods := make([]*format.Resource, 0, len(podList.Items))
-	for _, pod := range podList.Items {
-		pods = append(pods, convertPodToResource(&pod))
-	}
-
-	return pods, nil
+	return podList.Items, nil
 }
 
-func (dc *DatasetCollector) getPVCs(ctx context.Context, namespace, datasetName string) ([]*format.Resource, error) {
+func (dc *DatasetCollector) getPVCs(ctx context.Context, namespace, datasetName string) ([]corev1.PersistentVolumeClaim, error) {
 	labelSelector := fmt.Sprintf("%s=%s", fluidDatasetLabel, datasetName)
 	pvcList, err := dc.client.Client.CoreV1().PersistentVolumeClaims(namespace).List(ctx, metav1.ListOptions{
 		LabelSelector: labelSelector,
@@ -146,12 +181,7 @@ func (dc *DatasetCollector) getPVCs(ctx context.Context, namespace, datasetName
 		return nil, err
 	}
 
-	pvcs := make([]*format.Resource, 0, len(pvcList.Items))
-	for _, pvc := range pvcList.Items {
-		pvcs = append(pvcs, convertPVCToResource(&pvc))
-	}
-
-	return pvcs, nil
+	return pvcList.Items, nil
 }
 
 func (dc *DatasetCollector) getServices(ctx context.Context, namespace, datasetName string) ([]*format.Resource, error) {
@@ -171,23 +201,70 @@ func (dc *DatasetCollector) getServices(ctx context.Context, namespace, datasetN
 	return services, nil
 }
 
+// getRelated returns the resource of type t that an object refers to by
+// name, adding it to g the first time it is read. Resources that cannot be
+// read, such as Secrets the user may not get, are left out of the graph.
+func (dc *DatasetCollector) getRelated(ctx context.Context, g *format.Graph, related map[string]*format.Resource,
+	t format.ResourceType, namespace, name string) *format.Resource {
+	if name == "" {
+		return nil
+	}
+	key := string(t) + "/" + namespace + "/" + name
+	if res, ok := related[key]; ok {
+		return res
+	}
+
+	var res *format.Resource
+	core := dc.client.Client.CoreV1()
+	switch t {
+	case format.ResourceTypePV:
+		if pv, err := core.PersistentVolumes().Get(ctx, name, metav1.GetOptions{}); err == nil {
+			res = convertPVToResource(pv)
+		}
+	case format.ResourceTypeConfigMap:
+		if cm, err := core.ConfigMaps(namespace).Get(ctx, name, metav1.GetOptions{}); err == nil {
+			res = convertConfigMapToResource(cm)
+		}
+	case format.ResourceTypeSecret:
+		if secret, err := core.Secrets(namespace).Get(ctx, name, metav1.GetOptions{}); err == nil {
+			res = convertSecretToResource(secret)
+		}
+	case format.ResourceTypeNode:
+		if node, err := core.Nodes().Get(ctx, name, metav1.GetOptions{}); err == nil {
+			res = convertNodeToResource(node)
+		}
+	}
+	related[key] = res
+	if res != nil {
+		g.AddResource(res)
+	}
+	return res
+}
+
 func convertDatasetToResource(obj *unstructured.Unstructured) *format.Resource {
 	status, _, _ := unstructured.NestedMap(obj.Object, "status")
 	phase, _, _ := unstructured.NestedString(status, "phase")
 	ufsTotal, _, _ := unstructured.NestedString(status, "ufsTotal")
 	cached, _, _ := unstructured.NestedString(status, "cacheStates", "cached")
+	cachedPercentage, _, _ := unstructured.NestedString(status, "cacheStates", "cachedPercentage")
 
 	return &format.Resource{
-		Type:      format.ResourceTypeDataset,
-		Name:      obj.GetName(),
-		Namespace: obj.GetNamespace(),
-		Status:    phase,
-		Age:       getAge(obj.GetCreationTimestamp().Time),
-		Details: map[string]interface{}{
-			"ufsTotal": ufsTotal,
-			"cached":   cached,
+		Type:              format.ResourceTypeDataset,
+		Kind:              obj.GetKind(),
+		APIGroup:          obj.GroupVersionKind().Group,
+		Name:              obj.GetName(),
+		Namespace:         obj.GetNamespace(),
+		Status:            phase,
+		CreationTimestamp: obj.GetCreationTimestamp().Time,
+		DeletionTimestamp: timePtr(obj.GetDeletionTimestamp()),
+		Details: []format.Detail{
+			format.StringDetail("ufsTotal", ufsTotal, format.DetailPriorityPrimary),
+			format.StringDetail("cached", cached, format.DetailPriorityPrimary),
+			format.StringDetail("cachedPercentage", cachedPercentage, format.DetailPriorityPrimary),
 		},
-		Labels: obj.GetLabels(),
+		Labels:     obj.GetLabels(),
+		Conditions: convertUnstructuredConditions(status),
+		Object:     objectOf(obj, "", ""),
 	}
 }
 
@@ -201,15 +278,46 @@ func convertRuntimeToResource(obj *unstructured.Unstructured) *format.Resource {
 	runtimeType := obj.GetKind()
 
 	return &format.Resource{
-		Type:      format.ResourceTypeRuntime,
-		Name:      obj.GetName(),
-		Namespace: obj.GetNamespace(),
-		Status:    phase,
-		Age:       getAge(obj.GetCreationTimestamp().Time),
-		Details: map[string]interface{}{
-			"type":     runtimeType,
-			"replicas": replicas,
+		Type:              format.ResourceTypeRuntime,
+		Kind:              runtimeType,
+		APIGroup:          obj.GroupVersionKind().Group,
+		Name:              obj.GetName(),
+		Namespace:         obj.GetNamespace(),
+		Status:            phase,
+		CreationTimestamp: obj.GetCreationTimestamp().Time,
+		DeletionTimestamp: timePtr(obj.GetDeletionTimestamp()),
+		Details: []format.Detail{
+			format.StringDetail("type", runtimeType, format.DetailPriorityPrimary),
+			format.IntDetail("replicas", replicas, "", format.DetailPrioritySecondary),
 		},
-		Labels: obj.GetLabels(),
+		Labels:     obj.GetLabels(),
+		Conditions: convertUnstructuredConditions(status),
+		Object:     objectOf(obj, "", ""),
+	}
+}
+
+// convertUnstructuredConditions reads status.conditions from a custom resource.
+func convertUnstructuredConditions(status map[string]interface{}) []format.Condition {
+	items, _, _ := unstructured.NestedSlice(status, "conditions")
+	conditions := make([]format.Condition, 0, len(items))
+	for _, item := range items {
+		cond, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		condType, _, _ := unstructured.NestedString(cond, "type")
+		condStatus, _, _ := unstructured.NestedString(cond, "status")
+		reason, _, _ := unstructured.NestedString(cond, "reason")
+		message, _, _ := unstructured.NestedString(cond, "message")
+		transition, _, _ := unstructured.NestedString(cond, "lastTransitionTime")
+		lastTransition, _ := time.Parse(time.RFC3339, transition)
+		conditions = append(conditions, format.Condition{
+			Type:               condType,
+			Status:             condStatus,
+			Reason:             reason,
+			Message:            message,
+			LastTransitionTime: lastTransition,
+		})
 	}
+	return conditions
 }
@@ -0,0 +1,201 @@
+package collector
+
+import (
+	"7h3-3mp7y-m4n/kubectl-graph/pkg/client"
+	"7h3-3mp7y-m4n/kubectl-graph/pkg/format"
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+)
+
+const fluidObjects = `apiVersion: data.fluid.io/v1alpha1
+kind: Dataset
+metadata: {name: imagenet, namespace: default}
+status: {phase: Bound}
+---
+apiVersion: data.fluid.io/v1alpha1
+kind: AlluxioRuntime
+metadata: {name: imagenet, namespace: default}
+status: {phase: Ready}
+---
+apiVersion: v1
+kind: PersistentVolumeClaim
+metadata:
+  name: imagenet
+  namespace: default
+  labels: {fluid.io/dataset: imagenet}
+spec: {volumeName: default-imagenet}
+---
+apiVersion: v1
+kind: PersistentVolume
+metadata: {name: default-imagenet}
+spec: {persistentVolumeReclaimPolicy: Retain}
+status: {phase: Bound}
+---
+apiVersion: v1
+kind: Pod
+metadata:
+  name: imagenet-worker-0
+  namespace: default
+  labels: {fluid.io/dataset: imagenet}
+spec:
+  nodeName: node-a
+  volumes:
+  - {name: data, persistentVolumeClaim: {claimName: imagenet}}
+  - {name: config, configMap: {name: imagenet-config}}
+  - {name: missing, configMap: {name: absent, optional: true}}
+  containers:
+  - name: worker
+    envFrom:
+    - configMapRef: {name: imagenet-config}
+    env:
+    - {name: HOME, value: /root}
+    - name: ACCESS_KEY
+      valueFrom: {secretKeyRef: {name: imagenet-secret, key: key, optional: true}}
+---
+apiVersion: v1
+kind: ConfigMap
+metadata: {name: imagenet-config, namespace: default}
+data: {fuse: "true"}
+---
+apiVersion: v1
+kind: Secret
+metadata: {name: imagenet-secret, namespace: default}
+data: {key: c2VjcmV0}
+---
+apiVersion: v1
+kind: Node
+metadata: {name: node-a}
+status:
+  conditions:
+  - {type: Ready, status: "True"}
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: imagenet-master-0
+  namespace: default
+  labels: {fluid.io/dataset: imagenet}
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata: {name: train, namespace: default}
+spec:
+  template:
+    metadata:
+      labels: {fluid.io/dataset: imagenet}
+    spec:
+      containers: [{name: train, image: trainer}]`
+
+func testClient(t *testing.T) *client.Client {
+	t.Helper()
+	var objs []*unstructured.Unstructured
+	for _, doc := range strings.Split(fluidObjects, "\n---\n") {
+		data, err := yaml.YAMLToJSON([]byte(doc))
+		if err != nil {
+			t.Fatal(err)
+		}
+		u := &unstructured.Unstructured{}
+		if err := u.UnmarshalJSON(data); err != nil {
+			t.Fatal(err)
+		}
+		objs = append(objs, u)
+	}
+	c, err := client.NewInMemory(objs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func edgeStrings(g *format.Graph) []string {
+	var out []string
+	for _, e := range g.Edges {
+		s := e.From.ID() + " -" + string(e.Type) + "-> " + e.To.ID()
+		if e.Planned {
+			s += " (planned)"
+		}
+		out = append(out, s)
+	}
+	return out
+}
+
+func TestCollectDataset(t *testing.T) {
+	dc := NewDatasetCollector(testClient(t))
+	g, err := dc.Collect(context.Background(), "default", "imagenet")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pv := (&format.Resource{Type: format.ResourceTypePV, Name: "default-imagenet"}).ID()
+	node := (&format.Resource{Type: format.ResourceTypeNode, Name: "node-a"}).ID()
+	// Labeled objects hang off the dataset their label names, not off the
+	// runtime, which has no selector.
+	want := []string{
+		"Dataset/default/imagenet -runtimeBinding-> Runtime/default/imagenet",
+		"Dataset/default/imagenet -labelSelector-> PersistentVolumeClaim/default/imagenet",
+		"PersistentVolumeClaim/default/imagenet -volumeBinding-> " + pv,
+		"Dataset/default/imagenet -labelSelector-> Pod/default/imagenet-worker-0",
+		"Pod/default/imagenet-worker-0 -volumeMount-> PersistentVolumeClaim/default/imagenet",
+		"Pod/default/imagenet-worker-0 -configRef-> ConfigMap/default/imagenet-config",
+		"Pod/default/imagenet-worker-0 -configRef-> ConfigMap/default/imagenet-config",
+		"Pod/default/imagenet-worker-0 -configRef-> Secret/default/imagenet-secret",
+		"Pod/default/imagenet-worker-0 -scheduledOn-> " + node,
+		"Dataset/default/imagenet -labelSelector-> Service/default/imagenet-master-0",
+	}
+	if got := edgeStrings(g); !slices.Equal(got, want) {
+		t.Errorf("edges =\n  %s\nwant\n  %s", strings.Join(got, "\n  "), strings.Join(want, "\n  "))
+	}
+
+	if err := dc.Plan(context.Background(), "default", "imagenet", g); err != nil {
+		t.Fatal(err)
+	}
+	want = append(want,
+		"Deployment/default/train -ownerReference-> Pod/default/train-* (planned)",
+		"Dataset/default/imagenet -labelSelector-> Pod/default/train-* (planned)",
+	)
+	if got := edgeStrings(g); !slices.Equal(got, want) {
+		t.Errorf("planned edges =\n  %s\nwant\n  %s", strings.Join(got, "\n  "), strings.Join(want, "\n  "))
+	}
+}
+
+func TestCollectEvidence(t *testing.T) {
+	g, err := NewDatasetCollector(testClient(t)).Collect(context.Background(), "default", "imagenet")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, e := range g.Edges {
+		if e.Type == format.EdgeTypeConfigRef || e.Type == format.EdgeTypeScheduledOn || e.Type == format.EdgeTypeVolumeBinding {
+			got = append(got, e.Evidence)
+		}
+	}
+	want := []string{
+		"spec.volumeName",
+		"spec.volumes[1].configMap.name",
+		"spec.containers[0].envFrom[0].configMapRef.name",
+		"spec.containers[0].env[1].valueFrom.secretKeyRef.name (optional)",
+		"spec.nodeName",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("evidence =\n  %s\nwant\n  %s", strings.Join(got, "\n  "), strings.Join(want, "\n  "))
+	}
+
+	for _, res := range g.Nodes() {
+		if res.Type == format.ResourceTypeSecret {
+			if _, ok := res.Object["data"]; ok {
+				t.Errorf("secret %s keeps its data", res.Name)
+			}
+		}
+	}
+}
+
+func TestCollectMissingDataset(t *testing.T) {
+	_, err := NewDatasetCollector(testClient(t)).Collect(context.Background(), "default", "missing")
+	if err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("Collect = %v, want a not found error", err)
+	}
+}
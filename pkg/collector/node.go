@@ -0,0 +1,51 @@
+package collector
+
+import (
+	"7h3-3mp7y-m4n/kubectl-graph/pkg/format"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+func convertNodeToResource(node *corev1.Node) *format.Resource {
+	status := ""
+	conditions := make([]format.Condition, 0)
+	for _, cond := range node.Status.Conditions {
+		if cond.Type == corev1.NodeReady {
+			status = "NotReady"
+			if cond.Status == corev1.ConditionTrue {
+				status = "Ready"
+			}
+		}
+		conditions = append(conditions, format.Condition{
+			Type:               string(cond.Type),
+			Status:             string(cond.Status),
+			Reason:             cond.Reason,
+			Message:            cond.Message,
+			LastTransitionTime: cond.LastTransitionTime.Time,
+		})
+	}
+
+	internalIP := ""
+	for _, addr := range node.Status.Addresses {
+		if addr.Type == corev1.NodeInternalIP {
+			internalIP = addr.Address
+			break
+		}
+	}
+
+	return &format.Resource{
+		Type:              format.ResourceTypeNode,
+		Kind:              "Node",
+		Name:              node.Name,
+		Status:            status,
+		CreationTimestamp: node.CreationTimestamp.Time,
+		DeletionTimestamp: timePtr(node.DeletionTimestamp),
+		Details: []format.Detail{
+			format.StringDetail("version", node.Status.NodeInfo.KubeletVersion, format.DetailPriorityPrimary),
+			format.StringDetail("internalIP", internalIP, format.DetailPrioritySecondary),
+		},
+		Labels:     node.Labels,
+		Conditions: conditions,
+		Object:     objectOf(node, "v1", "Node"),
+	}
+}
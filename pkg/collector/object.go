@@ -0,0 +1,31 @@
+package collector
+
+import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// objectOf returns obj in unstructured form for format.Resource.Object.
+// Typed objects read through a list have no apiVersion and kind, so they
+// are set from the arguments. Managed fields are dropped; they are noise
+// to anyone reading the object.
+func objectOf(obj interface{}, apiVersion, kind string) map[string]interface{} {
+	var object map[string]interface{}
+	if u, ok := obj.(*unstructured.Unstructured); ok {
+		object = runtime.DeepCopyJSON(u.Object)
+	} else {
+		var err error
+		object, err = runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+		if err != nil {
+			return nil
+		}
+	}
+	if apiVersion != "" {
+		object["apiVersion"] = apiVersion
+	}
+	if kind != "" {
+		object["kind"] = kind
+	}
+	unstructured.RemoveNestedField(object, "metadata", "managedFields")
+	return object
+}
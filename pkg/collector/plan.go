@@ -0,0 +1,89 @@
+package collector
+
+import (
+	"7h3-3mp7y-m4n/kubectl-graph/pkg/format"
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Planner is implemented by collectors that can complete a graph built
+// from manifests, before anything is applied.
+type Planner interface {
+	// Plan adds to g, collected for the named resource, the resources that
+	// controllers will create from the collected objects, marked as
+	// planned, and the workloads they will belong to.
+	Plan(ctx context.Context, namespace, name string, g *format.Graph) error
+}
+
+// Plan adds the claim Fluid creates for a dataset with a runtime, and the
+// pods of the workloads whose template is labeled for the dataset or
+// mounts one of its claims.
+func (dc *DatasetCollector) Plan(ctx context.Context, namespace, name string, g *format.Graph) error {
+	selector := fmt.Sprintf("%s=%s", fluidDatasetLabel, name)
+	runtimes := g.Resources[format.ResourceTypeRuntime]
+	claims := make(map[string]*format.Resource)
+	for _, pvc := range g.Resources[format.ResourceTypePVC] {
+		claims[pvc.Name] = pvc
+	}
+	// Fluid names the claim after the dataset once the runtime is ready.
+	if _, ok := claims[name]; !ok && len(runtimes) > 0 {
+		pvc := &format.Resource{
+			Type:      format.ResourceTypePVC,
+			Kind:      "PersistentVolumeClaim",
+			Name:      name,
+			Namespace: namespace,
+			Status:    format.StatusNotYetPresent,
+			Labels:    map[string]string{fluidDatasetLabel: name},
+			Planned:   true,
+		}
+		g.AddResource(pvc)
+		g.AddEdge(g.Root, pvc, format.EdgeTypeLabelSelector, selector)
+		claims[name] = pvc
+	}
+
+	workloads, err := dc.getWorkloads(ctx, namespace)
+	if err != nil {
+		return fmt.Errorf("failed to list workloads: %w", err)
+	}
+	for _, wl := range workloads {
+		labeled := wl.template.Labels[fluidDatasetLabel] == name
+		mounts := false
+		for _, vol := range wl.template.Spec.Volumes {
+			if vol.PersistentVolumeClaim != nil && claims[vol.PersistentVolumeClaim.ClaimName] != nil {
+				mounts = true
+			}
+		}
+		if !labeled && !mounts {
+			continue
+		}
+		g.AddResource(wl.resource)
+
+		// Pod names are generated, so the pods of a workload are shown
+		// as one.
+		pod := convertPodToResource(&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: wl.resource.Name + "-*", Namespace: namespace, Labels: wl.template.Labels},
+			Spec:       wl.template.Spec,
+		})
+		pod.Status = format.StatusNotYetPresent
+		pod.Planned = true
+		pod.Object = nil
+		g.AddResource(pod)
+		g.AddEdge(wl.resource, pod, format.EdgeTypeOwnerReference, wl.ownerEvidence)
+		if labeled {
+			g.AddEdge(g.Root, pod, format.EdgeTypeLabelSelector, selector)
+		}
+		for j, vol := range wl.template.Spec.Volumes {
+			if vol.PersistentVolumeClaim == nil {
+				continue
+			}
+			if pvc, ok := claims[vol.PersistentVolumeClaim.ClaimName]; ok {
+				g.AddEdge(pod, pvc, format.EdgeTypeVolumeMount,
+					fmt.Sprintf("spec.volumes[%d].persistentVolumeClaim.claimName", j))
+			}
+		}
+	}
+	return nil
+}
@@ -2,9 +2,11 @@ package collector
 
 import (
 	"7h3-3mp7y-m4n/kubectl-graph/pkg/format"
+	"fmt"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 func convertPodToResource(pod *corev1.Pod) *format.Resource {
@@ -16,30 +18,113 @@ func convertPodToResource(pod *corev1.Pod) *format.Resource {
 	conditions := make([]format.Condition, 0)
 	for _, cond := range pod.Status.Conditions {
 		conditions = append(conditions, format.Condition{
-			Type:    string(cond.Type),
-			Status:  string(cond.Status),
-			Reason:  cond.Reason,
-			Message: cond.Message,
+			Type:               string(cond.Type),
+			Status:             string(cond.Status),
+			Reason:             cond.Reason,
+			Message:            cond.Message,
+			LastTransitionTime: cond.LastTransitionTime.Time,
 		})
 	}
 
 	return &format.Resource{
-		Type:      format.ResourceTypePod,
-		Name:      pod.Name,
-		Namespace: pod.Namespace,
-		Status:    string(pod.Status.Phase),
-		Age:       getAge(pod.CreationTimestamp.Time),
-		Details: map[string]interface{}{
-			"node":     pod.Spec.NodeName,
-			"restarts": restarts,
-			"hostIP":   pod.Status.HostIP,
-			"podIP":    pod.Status.PodIP,
+		Type:              format.ResourceTypePod,
+		Kind:              "Pod",
+		Name:              pod.Name,
+		Namespace:         pod.Namespace,
+		Status:            string(pod.Status.Phase),
+		CreationTimestamp: pod.CreationTimestamp.Time,
+		DeletionTimestamp: timePtr(pod.DeletionTimestamp),
+		Details: []format.Detail{
+			format.IntDetail("restarts", int64(restarts), "", format.DetailPriorityPrimary),
+			format.StringDetail("node", pod.Spec.NodeName, format.DetailPrioritySecondary),
+			format.StringDetail("hostIP", pod.Status.HostIP, format.DetailPrioritySecondary),
+			format.StringDetail("podIP", pod.Status.PodIP, format.DetailPrioritySecondary),
 		},
 		Labels:     pod.Labels,
 		Conditions: conditions,
+		Object:     objectOf(pod, "v1", "Pod"),
 	}
 }
 
-func getAge(t time.Time) time.Duration {
-	return time.Since(t)
+// configReference is a ConfigMap or Secret a pod refers to, and the field
+// path of the reference.
+type configReference struct {
+	resourceType format.ResourceType
+	name         string
+	path         string
+}
+
+// configReferences returns the ConfigMaps and Secrets pod refers to from
+// its volumes and from the envFrom and env of its containers. Optional
+// references are marked as such in their path.
+func configReferences(pod *corev1.Pod) []configReference {
+	var refs []configReference
+	add := func(t format.ResourceType, name string, optional *bool, path string, args ...interface{}) {
+		if name == "" {
+			return
+		}
+		path = fmt.Sprintf(path, args...)
+		if optional != nil && *optional {
+			path += " (optional)"
+		}
+		refs = append(refs, configReference{resourceType: t, name: name, path: path})
+	}
+
+	for i, vol := range pod.Spec.Volumes {
+		if cm := vol.ConfigMap; cm != nil {
+			add(format.ResourceTypeConfigMap, cm.Name, cm.Optional, "spec.volumes[%d].configMap.name", i)
+		}
+		if s := vol.Secret; s != nil {
+			add(format.ResourceTypeSecret, s.SecretName, s.Optional, "spec.volumes[%d].secret.secretName", i)
+		}
+		if vol.Projected == nil {
+			continue
+		}
+		for j, src := range vol.Projected.Sources {
+			if cm := src.ConfigMap; cm != nil {
+				add(format.ResourceTypeConfigMap, cm.Name, cm.Optional, "spec.volumes[%d].projected.sources[%d].configMap.name", i, j)
+			}
+			if s := src.Secret; s != nil {
+				add(format.ResourceTypeSecret, s.Name, s.Optional, "spec.volumes[%d].projected.sources[%d].secret.name", i, j)
+			}
+		}
+	}
+
+	for _, list := range []struct {
+		field      string
+		containers []corev1.Container
+	}{
+		{"initContainers", pod.Spec.InitContainers},
+		{"containers", pod.Spec.Containers},
+	} {
+		for i, c := range list.containers {
+			for j, from := range c.EnvFrom {
+				if ref := from.ConfigMapRef; ref != nil {
+					add(format.ResourceTypeConfigMap, ref.Name, ref.Optional, "spec.%s[%d].envFrom[%d].configMapRef.name", list.field, i, j)
+				}
+				if ref := from.SecretRef; ref != nil {
+					add(format.ResourceTypeSecret, ref.Name, ref.Optional, "spec.%s[%d].envFrom[%d].secretRef.name", list.field, i, j)
+				}
+			}
+			for j, env := range c.Env {
+				if env.ValueFrom == nil {
+					continue
+				}
+				if ref := env.ValueFrom.ConfigMapKeyRef; ref != nil {
+					add(format.ResourceTypeConfigMap, ref.Name, ref.Optional, "spec.%s[%d].env[%d].valueFrom.configMapKeyRef.name", list.field, i, j)
+				}
+				if ref := env.ValueFrom.SecretKeyRef; ref != nil {
+					add(format.ResourceTypeSecret, ref.Name, ref.Optional, "spec.%s[%d].env[%d].valueFrom.secretKeyRef.name", list.field, i, j)
+				}
+			}
+		}
+	}
+	return refs
+}
+
+func timePtr(t *metav1.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	return &t.Time
 }
@@ -18,16 +18,42 @@ func convertPVCToResource(pvc *corev1.PersistentVolumeClaim) *format.Resource {
 	}
 
 	return &format.Resource{
-		Type:      format.ResourceTypePVC,
-		Name:      pvc.Name,
-		Namespace: pvc.Namespace,
-		Status:    string(pvc.Status.Phase),
-		Age:       getAge(pvc.CreationTimestamp.Time),
-		Details: map[string]interface{}{
-			"volumeName": pvc.Spec.VolumeName,
-			"capacity":   capacity,
-			"requested":  requested,
+		Type:              format.ResourceTypePVC,
+		Kind:              "PersistentVolumeClaim",
+		Name:              pvc.Name,
+		Namespace:         pvc.Namespace,
+		Status:            string(pvc.Status.Phase),
+		CreationTimestamp: pvc.CreationTimestamp.Time,
+		DeletionTimestamp: timePtr(pvc.DeletionTimestamp),
+		Details: []format.Detail{
+			format.StringDetail("capacity", capacity, format.DetailPriorityPrimary),
+			format.StringDetail("requested", requested, format.DetailPrioritySecondary),
+			format.StringDetail("volumeName", pvc.Spec.VolumeName, format.DetailPrioritySecondary),
 		},
 		Labels: pvc.Labels,
+		Object: objectOf(pvc, "v1", "PersistentVolumeClaim"),
+	}
+}
+
+func convertPVToResource(pv *corev1.PersistentVolume) *format.Resource {
+	capacity := ""
+	if cap, ok := pv.Spec.Capacity[corev1.ResourceStorage]; ok {
+		capacity = cap.String()
+	}
+
+	return &format.Resource{
+		Type:              format.ResourceTypePV,
+		Kind:              "PersistentVolume",
+		Name:              pv.Name,
+		Status:            string(pv.Status.Phase),
+		CreationTimestamp: pv.CreationTimestamp.Time,
+		DeletionTimestamp: timePtr(pv.DeletionTimestamp),
+		Details: []format.Detail{
+			format.StringDetail("capacity", capacity, format.DetailPriorityPrimary),
+			format.StringDetail("reclaimPolicy", string(pv.Spec.PersistentVolumeReclaimPolicy), format.DetailPriorityPrimary),
+			format.StringDetail("storageClass", pv.Spec.StorageClassName, format.DetailPrioritySecondary),
+		},
+		Labels: pv.Labels,
+		Object: objectOf(pv, "v1", "PersistentVolume"),
 	}
 }
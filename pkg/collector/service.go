@@ -17,16 +17,19 @@ func convertServiceToResource(svc *corev1.Service) *format.Resource {
 	}
 
 	return &format.Resource{
-		Type:      format.ResourceTypeService,
-		Name:      svc.Name,
-		Namespace: svc.Namespace,
-		Status:    "Active", // Services don't have a phase
-		Age:       getAge(svc.CreationTimestamp.Time),
-		Details: map[string]interface{}{
-			"type":      string(svc.Spec.Type),
-			"clusterIP": svc.Spec.ClusterIP,
-			"ports":     ports,
+		Type:              format.ResourceTypeService,
+		Kind:              "Service",
+		Name:              svc.Name,
+		Namespace:         svc.Namespace,
+		Status:            "Active", // Services don't have a phase
+		CreationTimestamp: svc.CreationTimestamp.Time,
+		DeletionTimestamp: timePtr(svc.DeletionTimestamp),
+		Details: []format.Detail{
+			format.StringDetail("ports", ports, format.DetailPriorityPrimary),
+			format.StringDetail("type", string(svc.Spec.Type), format.DetailPrioritySecondary),
+			format.StringDetail("clusterIP", svc.Spec.ClusterIP, format.DetailPrioritySecondary),
 		},
 		Labels: svc.Labels,
+		Object: objectOf(svc, "v1", "Service"),
 	}
 }
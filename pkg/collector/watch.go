@@ -0,0 +1,113 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync/atomic"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/tools/cache"
+)
+
+// Watcher is implemented by collectors that can report changes to the
+// objects their graph is built from.
+type Watcher interface {
+	// Watch calls onChange whenever one of the objects behind the graph
+	// of the named resource is added, updated or deleted, until ctx is
+	// done. Changes are not reported until the initial state is synced.
+	Watch(ctx context.Context, namespace, name string, onChange func()) error
+}
+
+// watchResync is how often informers replay their cache. Replays are
+// updates without changes, which callers diffing graphs ignore.
+const watchResync = 10 * time.Minute
+
+// Watch runs informers for the dataset, its runtime and the pods, PVCs
+// and services labeled with the dataset's name.
+func (dc *DatasetCollector) Watch(ctx context.Context, namespace, name string, onChange func()) error {
+	var synced atomic.Bool
+	handler := cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(interface{}) { notify(&synced, onChange) },
+		UpdateFunc: func(interface{}, interface{}) { notify(&synced, onChange) },
+		DeleteFunc: func(interface{}) { notify(&synced, onChange) },
+	}
+
+	selector := fmt.Sprintf("%s=%s", fluidDatasetLabel, name)
+	core := informers.NewSharedInformerFactoryWithOptions(dc.client.Client, watchResync,
+		informers.WithNamespace(namespace),
+		informers.WithTweakListOptions(func(opts *metav1.ListOptions) { opts.LabelSelector = selector }))
+	for _, informer := range []cache.SharedIndexInformer{
+		core.Core().V1().Pods().Informer(),
+		core.Core().V1().PersistentVolumeClaims().Informer(),
+		core.Core().V1().Services().Informer(),
+	} {
+		if _, err := informer.AddEventHandler(handler); err != nil {
+			return err
+		}
+	}
+
+	// Runtimes whose CRD is not installed would make their informer retry
+	// forever, so only the served ones are watched.
+	gvrs, err := dc.servedRuntimeGVRs()
+	if err != nil {
+		return fmt.Errorf("failed to discover runtimes: %w", err)
+	}
+	gvrs = append(gvrs, datasetGVR)
+	crds := dynamicinformer.NewFilteredDynamicSharedInformerFactory(dc.client.DynamicClient, watchResync, namespace,
+		func(opts *metav1.ListOptions) {
+			opts.FieldSelector = fields.OneTermEqualSelector("metadata.name", name).String()
+		})
+	for _, gvr := range gvrs {
+		if _, err := crds.ForResource(gvr).Informer().AddEventHandler(handler); err != nil {
+			return err
+		}
+	}
+
+	// The informers stop when ctx is done, or on return when syncing fails.
+	ctx, cancel := context.WithCancel(ctx)
+	core.Start(ctx.Done())
+	crds.Start(ctx.Done())
+	defer core.Shutdown()
+	defer crds.Shutdown()
+	defer cancel()
+	for informer, ok := range core.WaitForCacheSync(ctx.Done()) {
+		if !ok {
+			return fmt.Errorf("failed to sync %v", informer)
+		}
+	}
+	for gvr, ok := range crds.WaitForCacheSync(ctx.Done()) {
+		if !ok {
+			return fmt.Errorf("failed to sync %s", gvr.Resource)
+		}
+	}
+	synced.Store(true)
+	<-ctx.Done()
+	return nil
+}
+
+// servedRuntimeGVRs returns the runtimes the API server serves.
+func (dc *DatasetCollector) servedRuntimeGVRs() ([]schema.GroupVersionResource, error) {
+	list, err := dc.client.Client.Discovery().ServerResourcesForGroupVersion(datasetGVR.GroupVersion().String())
+	if err != nil {
+		return nil, err
+	}
+	var gvrs []schema.GroupVersionResource
+	for _, gvr := range runtimeGVRs {
+		if slices.ContainsFunc(list.APIResources, func(r metav1.APIResource) bool { return r.Name == gvr.Resource }) {
+			gvrs = append(gvrs, gvr)
+		}
+	}
+	return gvrs, nil
+}
+
+func notify(synced *atomic.Bool, onChange func()) {
+	if synced.Load() {
+		onChange()
+	}
+}
@@ -0,0 +1,95 @@
+package collector
+
+import (
+	"7h3-3mp7y-m4n/kubectl-graph/pkg/format"
+	"context"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestWatch(t *testing.T) {
+	c := testClient(t)
+	dc := NewDatasetCollector(c)
+	prev, err := dc.Collect(context.Background(), "default", "imagenet")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	changed := make(chan struct{}, 1)
+	done := make(chan error, 1)
+	go func() {
+		done <- dc.Watch(ctx, "default", "imagenet", func() {
+			select {
+			case changed <- struct{}{}:
+			default:
+			}
+		})
+	}()
+
+	// Changes before the informers sync are not reported, so pods are
+	// created until one is.
+	pods := c.Client.CoreV1().Pods("default")
+	timeout := time.After(10 * time.Second)
+	var created []string
+	for notified := false; !notified; {
+		name := fmt.Sprintf("imagenet-fuse-%d", len(created))
+		pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "default",
+			Labels:    map[string]string{fluidDatasetLabel: "imagenet"},
+		}}
+		if _, err := pods.Create(ctx, pod, metav1.CreateOptions{}); err != nil {
+			t.Fatal(err)
+		}
+		created = append(created, "Pod/default/"+name)
+		select {
+		case <-changed:
+			notified = true
+		case <-time.After(50 * time.Millisecond):
+		case err := <-done:
+			t.Fatalf("Watch returned early: %v", err)
+		case <-timeout:
+			t.Fatal("no change reported")
+		}
+	}
+
+	next, err := dc.Collect(context.Background(), "default", "imagenet")
+	if err != nil {
+		t.Fatal(err)
+	}
+	changes := format.Diff(prev, next)
+	for _, id := range created {
+		if !slices.ContainsFunc(changes, func(c format.Change) bool {
+			return c.Op == format.ChangeAdd && c.Node != nil && c.Node.ID == id
+		}) {
+			t.Errorf("changes %v do not add %s", changes, id)
+		}
+		if !slices.ContainsFunc(changes, func(c format.Change) bool {
+			return c.Op == format.ChangeAdd && c.Edge != nil && c.Edge.To == id && c.Edge.Type == format.EdgeTypeLabelSelector
+		}) {
+			t.Errorf("changes %v do not add the labelSelector edge to %s", changes, id)
+		}
+	}
+	for _, c := range changes {
+		if c.Op != format.ChangeAdd {
+			t.Errorf("unexpected %s change of %+v %+v", c.Op, c.Node, c.Edge)
+		}
+	}
+
+	cancel()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Watch = %v, want nil after cancel", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("Watch did not return after cancel")
+	}
+}
@@ -0,0 +1,133 @@
+package collector
+
+import (
+	"7h3-3mp7y-m4n/kubectl-graph/pkg/format"
+	"context"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// workload is a controller that creates pods from a template.
+type workload struct {
+	resource *format.Resource
+	template corev1.PodTemplateSpec
+	// ownerEvidence records how the pods are owned by the workload.
+	ownerEvidence string
+}
+
+// getWorkloads returns the Deployments, StatefulSets, DaemonSets and Jobs
+// in namespace.
+func (dc *DatasetCollector) getWorkloads(ctx context.Context, namespace string) ([]workload, error) {
+	var workloads []workload
+	deployments, err := dc.client.Client.AppsV1().Deployments(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for i := range deployments.Items {
+		d := &deployments.Items[i]
+		res := convertWorkloadToResource(d, &d.ObjectMeta, format.ResourceTypeDeployment, "Deployment", "apps",
+			replicasOf(d.Spec.Replicas), d.Status.ReadyReplicas, d.Status.ObservedGeneration)
+		workloads = append(workloads, workload{res, d.Spec.Template, "metadata.ownerReferences, through a ReplicaSet"})
+	}
+
+	statefulSets, err := dc.client.Client.AppsV1().StatefulSets(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for i := range statefulSets.Items {
+		s := &statefulSets.Items[i]
+		res := convertWorkloadToResource(s, &s.ObjectMeta, format.ResourceTypeStatefulSet, "StatefulSet", "apps",
+			replicasOf(s.Spec.Replicas), s.Status.ReadyReplicas, s.Status.ObservedGeneration)
+		workloads = append(workloads, workload{res, s.Spec.Template, "metadata.ownerReferences"})
+	}
+
+	daemonSets, err := dc.client.Client.AppsV1().DaemonSets(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for i := range daemonSets.Items {
+		d := &daemonSets.Items[i]
+		res := convertWorkloadToResource(d, &d.ObjectMeta, format.ResourceTypeDaemonSet, "DaemonSet", "apps",
+			d.Status.DesiredNumberScheduled, d.Status.NumberReady, d.Status.ObservedGeneration)
+		workloads = append(workloads, workload{res, d.Spec.Template, "metadata.ownerReferences"})
+	}
+
+	jobs, err := dc.client.Client.BatchV1().Jobs(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for i := range jobs.Items {
+		j := &jobs.Items[i]
+		res := convertWorkloadToResource(j, &j.ObjectMeta, format.ResourceTypeJob, "Job", "batch",
+			replicasOf(j.Spec.Parallelism), ptrValue(j.Status.Ready), 0)
+		res.Status = jobStatus(j)
+		workloads = append(workloads, workload{res, j.Spec.Template, "metadata.ownerReferences"})
+	}
+	return workloads, nil
+}
+
+// convertWorkloadToResource converts a workload with the given number of
+// desired and ready pods. Workloads whose controller has not seen them yet,
+// such as ones read from manifests, have no status.
+func convertWorkloadToResource(obj interface{}, meta *metav1.ObjectMeta, t format.ResourceType, kind, group string,
+	desired, ready int32, observedGeneration int64) *format.Resource {
+	status := ""
+	switch {
+	case observedGeneration == 0:
+	case ready >= desired:
+		status = "Ready"
+	default:
+		status = "Progressing"
+	}
+	return &format.Resource{
+		Type:              t,
+		Kind:              kind,
+		APIGroup:          group,
+		Name:              meta.Name,
+		Namespace:         meta.Namespace,
+		Status:            status,
+		CreationTimestamp: meta.CreationTimestamp.Time,
+		DeletionTimestamp: timePtr(meta.DeletionTimestamp),
+		Details: []format.Detail{
+			format.IntDetail("replicas", int64(desired), "", format.DetailPriorityPrimary),
+			format.IntDetail("ready", int64(ready), "", format.DetailPriorityPrimary),
+		},
+		Labels: meta.Labels,
+		Object: objectOf(obj, group+"/v1", kind),
+	}
+}
+
+func jobStatus(job *batchv1.Job) string {
+	for _, cond := range job.Status.Conditions {
+		if cond.Status != corev1.ConditionTrue {
+			continue
+		}
+		switch cond.Type {
+		case batchv1.JobComplete:
+			return "Complete"
+		case batchv1.JobFailed:
+			return "Failed"
+		}
+	}
+	if job.Status.Active > 0 {
+		return "Running"
+	}
+	return ""
+}
+
+// replicasOf returns the replicas of a spec, which default to one.
+func replicasOf(replicas *int32) int32 {
+	if replicas == nil {
+		return 1
+	}
+	return *replicas
+}
+
+func ptrValue(v *int32) int32 {
+	if v == nil {
+		return 0
+	}
+	return *v
+}
@@ -0,0 +1,317 @@
+// Package explore implements the full-screen terminal UI behind
+// 'kubectl graph explore': a tree of the graph on the left and the
+// selected resource on the right.
+package explore
+
+import (
+	"7h3-3mp7y-m4n/kubectl-graph/pkg/format"
+	"fmt"
+	"os"
+	"time"
+
+	"golang.org/x/term"
+)
+
+// RefreshFunc collects the graph again.
+type RefreshFunc func() (*format.Graph, error)
+
+type pane int
+
+const (
+	paneDetails pane = iota
+	paneYAML
+)
+
+// Explorer holds the state of the UI.
+type Explorer struct {
+	tree    tree
+	refresh RefreshFunc
+
+	// cursor is the path of the selected row.
+	cursor string
+	// top is the first tree row on screen and scroll the first line of
+	// the detail pane.
+	top, scroll int
+	pane        pane
+
+	unhealthyOnly bool
+	// search is the last query; searching is set while one is typed.
+	search    string
+	searching bool
+	input     string
+
+	message       string
+	width, height int
+	// clock returns the current time, for ages in graphs without a
+	// collection time.
+	clock func() time.Time
+}
+
+func New(g *format.Graph, refresh RefreshFunc) *Explorer {
+	e := &Explorer{
+		tree:    tree{graph: g, expanded: make(map[string]bool)},
+		refresh: refresh,
+		clock:   time.Now,
+	}
+	if rows := e.rows(); len(rows) > 0 {
+		e.cursor = rows[0].path
+	}
+	return e
+}
+
+// Run takes over the terminal until the user quits. in and out must be
+// the terminal.
+func (e *Explorer) Run(in, out *os.File) error {
+	fd := int(in.Fd())
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		return err
+	}
+	defer term.Restore(fd, state)
+	// Alternate screen, hidden cursor.
+	fmt.Fprint(out, "\x1b[?1049h\x1b[?25l")
+	defer fmt.Fprint(out, "\x1b[?25h\x1b[?1049l")
+
+	// The reader goroutine stays blocked on the terminal after Run
+	// returns; the process exits right after.
+	input := make(chan []byte)
+	go readInput(in, input)
+	var keys keyDecoder
+	// Resizes are polled rather than signaled, as SIGWINCH does not exist
+	// everywhere.
+	ticker := time.NewTicker(250 * time.Millisecond)
+	defer ticker.Stop()
+
+	e.resize(out)
+	e.draw(out)
+	for {
+		select {
+		case b, ok := <-input:
+			if !ok {
+				return nil
+			}
+			for _, key := range keys.decode(b) {
+				switch e.handle(key) {
+				case actionQuit:
+					return nil
+				case actionRefresh:
+					e.message = "Refreshing..."
+					e.draw(out)
+					e.doRefresh()
+				}
+			}
+			e.draw(out)
+		case <-ticker.C:
+			if e.resize(out) {
+				e.draw(out)
+			}
+		}
+	}
+}
+
+// resize reads the terminal size and reports whether it changed.
+func (e *Explorer) resize(out *os.File) bool {
+	w, h, err := term.GetSize(int(out.Fd()))
+	if err != nil || (w == e.width && h == e.height) {
+		return false
+	}
+	e.width, e.height = w, h
+	return true
+}
+
+func (e *Explorer) draw(out *os.File) {
+	fmt.Fprint(out, e.render())
+}
+
+func (e *Explorer) doRefresh() {
+	if e.refresh == nil {
+		e.message = "Refresh is not available"
+		return
+	}
+	g, err := e.refresh()
+	if err != nil {
+		e.message = fmt.Sprintf("Refresh failed: %v", err)
+		return
+	}
+	e.tree.graph = g
+	e.message = fmt.Sprintf("Refreshed at %s", e.clock().Format("15:04:05"))
+}
+
+// now returns the reference time for ages: the collection time of the
+// graph when it is known, as the graph shows the cluster at that moment.
+func (e *Explorer) now() time.Time {
+	if t := e.tree.graph.Metadata.CollectedAt; !t.IsZero() {
+		return t
+	}
+	return e.clock()
+}
+
+type action int
+
+const (
+	actionNone action = iota
+	actionQuit
+	actionRefresh
+)
+
+// handle applies one key press.
+func (e *Explorer) handle(key string) action {
+	if e.searching {
+		e.handleSearchKey(key)
+		return actionNone
+	}
+	e.message = ""
+	rows := e.rows()
+	i := e.cursorIndex(rows)
+	switch key {
+	case "q", keyCtrlC:
+		return actionQuit
+	case keyUp, "k":
+		e.moveTo(rows, i-1)
+	case keyDown, "j":
+		e.moveTo(rows, i+1)
+	case keyHome, "g":
+		e.moveTo(rows, 0)
+	case keyEnd, "G":
+		e.moveTo(rows, len(rows)-1)
+	case keyRight, "l", keyEnter:
+		if i >= 0 && rows[i].hasChildren {
+			if rows[i].expanded && key != keyEnter {
+				e.moveTo(rows, i+1)
+			} else {
+				e.tree.expanded[rows[i].path] = !rows[i].expanded
+			}
+		}
+	case keyLeft, "h":
+		if i < 0 {
+			break
+		}
+		if rows[i].expanded {
+			e.tree.expanded[rows[i].path] = false
+		} else {
+			// Jump to the parent.
+			for j := i - 1; j >= 0; j-- {
+				if rows[j].depth < rows[i].depth {
+					e.moveTo(rows, j)
+					break
+				}
+			}
+		}
+	case keyTab:
+		e.pane = (e.pane + 1) % 2
+		e.scroll = 0
+	case keyPageDown, "J":
+		e.scroll += max(e.height/2, 1)
+	case keyPageUp, "K":
+		e.scroll = max(e.scroll-max(e.height/2, 1), 0)
+	case "u":
+		e.unhealthyOnly = !e.unhealthyOnly
+		if e.unhealthyOnly {
+			e.tree.keep = unhealthy
+			e.message = "Showing unhealthy resources and their ancestors"
+		} else {
+			e.tree.keep = nil
+		}
+	case "/":
+		e.searching = true
+		e.input = ""
+	case "n":
+		e.findNext(1)
+	case "N":
+		e.findNext(-1)
+	case "r":
+		return actionRefresh
+	case "?":
+		e.message = helpText
+	}
+	return actionNone
+}
+
+func (e *Explorer) handleSearchKey(key string) {
+	switch key {
+	case keyEnter:
+		e.searching = false
+		e.search = e.input
+		e.findNext(0)
+	case keyEscape, keyCtrlC:
+		e.searching = false
+	case keyBackspace:
+		if r := []rune(e.input); len(r) > 0 {
+			e.input = string(r[:len(r)-1])
+		}
+	default:
+		if len([]rune(key)) == 1 {
+			e.input += key
+		}
+	}
+}
+
+// findNext selects the next row matching the search in direction dir,
+// starting with the current row when dir is 0, and expands the tree down
+// to it.
+func (e *Explorer) findNext(dir int) {
+	if e.search == "" {
+		return
+	}
+	all := e.tree.rows(true)
+	start := 0
+	for i, r := range all {
+		if r.path == e.cursor {
+			start = i
+			break
+		}
+	}
+	step := dir
+	if step == 0 {
+		step = 1
+	}
+	for n := 0; n < len(all); n++ {
+		i := ((start+dir+n*step)%len(all) + len(all)) % len(all)
+		if matches(all[i].res, e.search) {
+			e.tree.reveal(all[i].path)
+			e.cursor = all[i].path
+			e.scroll = 0
+			return
+		}
+	}
+	e.message = fmt.Sprintf("No match for %q", e.search)
+}
+
+func (e *Explorer) rows() []row {
+	return e.tree.rows(false)
+}
+
+// cursorIndex returns the index of the selected row. When it is no longer
+// visible, the first row showing the same resource, or else the first row, is
+// selected instead.
+func (e *Explorer) cursorIndex(rows []row) int {
+	if len(rows) == 0 {
+		return -1
+	}
+	for i, r := range rows {
+		if r.path == e.cursor {
+			return i
+		}
+	}
+	for i, r := range rows {
+		if len(e.cursor) >= len(r.res.ID()) && e.cursor[len(e.cursor)-len(r.res.ID()):] == r.res.ID() {
+			e.cursor = r.path
+			return i
+		}
+	}
+	e.cursor = rows[0].path
+	return 0
+}
+
+func (e *Explorer) moveTo(rows []row, i int) {
+	if len(rows) == 0 {
+		return
+	}
+	i = min(max(i, 0), len(rows)-1)
+	if rows[i].path != e.cursor {
+		e.cursor = rows[i].path
+		e.scroll = 0
+	}
+}
+
+const helpText = "↑↓/jk move  ←→/hl close/open  / search  n/N next/prev  u unhealthy  tab yaml  J/K scroll  r refresh  q quit"
@@ -0,0 +1,292 @@
+package explore
+
+import (
+	"7h3-3mp7y-m4n/kubectl-graph/pkg/format"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+var collectedAt = time.Date(2024, 5, 3, 12, 0, 0, 0, time.UTC)
+
+// testGraph is a dataset with a runtime and two pods sharing a claim, one
+// of them crashing.
+func testGraph() *format.Graph {
+	ds := &format.Resource{Type: format.ResourceTypeDataset, Name: "imagenet", Namespace: "default", Status: "Bound"}
+	rt := &format.Resource{Type: format.ResourceTypeRuntime, Name: "imagenet", Namespace: "default", Status: "Ready"}
+	w0 := &format.Resource{Type: format.ResourceTypePod, Name: "worker-0", Namespace: "default", Status: "Running"}
+	w1 := &format.Resource{Type: format.ResourceTypePod, Name: "worker-1", Namespace: "default", Status: "CrashLoopBackOff"}
+	pvc := &format.Resource{Type: format.ResourceTypePVC, Name: "imagenet", Namespace: "default", Status: "Bound"}
+	g := format.NewGraph(ds)
+	g.Metadata.CollectedAt = collectedAt
+	for _, res := range []*format.Resource{rt, w0, w1, pvc} {
+		g.AddResource(res)
+	}
+	g.AddEdge(ds, rt, format.EdgeTypeRuntimeBinding, "same name")
+	g.AddEdge(ds, w0, format.EdgeTypeLabelSelector, "fluid.io/dataset=imagenet")
+	g.AddEdge(ds, w1, format.EdgeTypeLabelSelector, "fluid.io/dataset=imagenet")
+	g.AddEdge(w0, pvc, format.EdgeTypeVolumeMount, "spec.volumes[0].persistentVolumeClaim.claimName")
+	g.AddEdge(w1, pvc, format.EdgeTypeVolumeMount, "spec.volumes[0].persistentVolumeClaim.claimName")
+	return g
+}
+
+// rowStrings returns the rows as indented IDs, marking collapsed rows with
+// children with a "+" and the selected row with a "*".
+func rowStrings(e *Explorer) []string {
+	rows := e.rows()
+	out := make([]string, len(rows))
+	for i, r := range rows {
+		s := strings.Repeat("  ", r.depth) + r.res.ID()
+		if r.hasChildren && !r.expanded {
+			s += " +"
+		}
+		if r.path == e.cursor {
+			s += " *"
+		}
+		out[i] = s
+	}
+	return out
+}
+
+func press(e *Explorer, keys ...string) {
+	for _, key := range keys {
+		e.handle(key)
+	}
+}
+
+func TestRows(t *testing.T) {
+	e := New(testGraph(), nil)
+	want := []string{
+		"Dataset/default/imagenet *",
+		"  Runtime/default/imagenet",
+		"  Pod/default/worker-0",
+		"    PersistentVolumeClaim/default/imagenet",
+		"  Pod/default/worker-1",
+		"    PersistentVolumeClaim/default/imagenet",
+	}
+	if got := rowStrings(e); !slices.Equal(got, want) {
+		t.Errorf("rows = %q, want %q", got, want)
+	}
+}
+
+func TestExpandCollapse(t *testing.T) {
+	tests := []struct {
+		name string
+		keys []string
+		want []string
+	}{
+		{
+			name: "collapse",
+			keys: []string{keyDown, keyDown, keyLeft},
+			want: []string{
+				"Dataset/default/imagenet",
+				"  Runtime/default/imagenet",
+				"  Pod/default/worker-0 + *",
+				"  Pod/default/worker-1",
+				"    PersistentVolumeClaim/default/imagenet",
+			},
+		},
+		{
+			name: "expand again",
+			keys: []string{"j", "j", "h", "l"},
+			want: []string{
+				"Dataset/default/imagenet",
+				"  Runtime/default/imagenet",
+				"  Pod/default/worker-0 *",
+				"    PersistentVolumeClaim/default/imagenet",
+				"  Pod/default/worker-1",
+				"    PersistentVolumeClaim/default/imagenet",
+			},
+		},
+		{
+			name: "right on an open row moves into it",
+			keys: []string{keyDown, keyDown, keyRight},
+			want: []string{
+				"Dataset/default/imagenet",
+				"  Runtime/default/imagenet",
+				"  Pod/default/worker-0",
+				"    PersistentVolumeClaim/default/imagenet *",
+				"  Pod/default/worker-1",
+				"    PersistentVolumeClaim/default/imagenet",
+			},
+		},
+		{
+			name: "left on a leaf jumps to the parent",
+			keys: []string{keyEnd, keyLeft},
+			want: []string{
+				"Dataset/default/imagenet",
+				"  Runtime/default/imagenet",
+				"  Pod/default/worker-0",
+				"    PersistentVolumeClaim/default/imagenet",
+				"  Pod/default/worker-1 *",
+				"    PersistentVolumeClaim/default/imagenet",
+			},
+		},
+		{
+			name: "enter toggles",
+			keys: []string{keyEnter},
+			want: []string{"Dataset/default/imagenet + *"},
+		},
+		{
+			name: "collapsed rows keep their state",
+			keys: []string{keyDown, keyDown, keyLeft, keyHome, keyEnter, keyEnter},
+			want: []string{
+				"Dataset/default/imagenet *",
+				"  Runtime/default/imagenet",
+				"  Pod/default/worker-0 +",
+				"  Pod/default/worker-1",
+				"    PersistentVolumeClaim/default/imagenet",
+			},
+		},
+		{
+			name: "search reveals",
+			keys: []string{keyEnter, "/", "w", "o", "r", "k", "e", "r", "-", "1", keyEnter},
+			want: []string{
+				"Dataset/default/imagenet",
+				"  Runtime/default/imagenet",
+				"  Pod/default/worker-0",
+				"    PersistentVolumeClaim/default/imagenet",
+				"  Pod/default/worker-1 *",
+				"    PersistentVolumeClaim/default/imagenet",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := New(testGraph(), nil)
+			press(e, tt.keys...)
+			if got := rowStrings(e); !slices.Equal(got, tt.want) {
+				t.Errorf("rows = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnhealthyOnly(t *testing.T) {
+	g := testGraph()
+	e := New(g, nil)
+	press(e, "u")
+	want := []string{
+		"Dataset/default/imagenet *",
+		"  Pod/default/worker-1",
+	}
+	if got := rowStrings(e); !slices.Equal(got, want) {
+		t.Errorf("rows = %q, want %q", got, want)
+	}
+	if !strings.Contains(e.title(), "unhealthy only") {
+		t.Errorf("title %q does not mention the filter", e.title())
+	}
+
+	// Restarts and pending deletions are unhealthy too, and keep their
+	// ancestors.
+	pvc := g.Resources[format.ResourceTypePVC][0]
+	pvc.DeletionTimestamp = &collectedAt
+	rt := g.Resources[format.ResourceTypeRuntime][0]
+	rt.Details = []format.Detail{format.IntDetail("restarts", 2, "", format.DetailPriorityPrimary)}
+	want = []string{
+		"Dataset/default/imagenet *",
+		"  Runtime/default/imagenet",
+		"  Pod/default/worker-0",
+		"    PersistentVolumeClaim/default/imagenet",
+		"  Pod/default/worker-1",
+		"    PersistentVolumeClaim/default/imagenet",
+	}
+	if got := rowStrings(e); !slices.Equal(got, want) {
+		t.Errorf("rows = %q, want %q", got, want)
+	}
+
+	press(e, "u")
+	pvc.DeletionTimestamp = nil
+	rt.Details = nil
+	if got := rowStrings(e); len(got) != 6 {
+		t.Errorf("rows after turning the filter off = %q, want all 6", got)
+	}
+}
+
+func TestRowsWithoutRoot(t *testing.T) {
+	train := &format.Resource{Type: format.ResourceTypeDeployment, Name: "train", Namespace: "default", Status: "Ready"}
+	pod := &format.Resource{Type: format.ResourceTypePod, Name: "train-0", Namespace: "default", Status: "Running"}
+	a := &format.Resource{Type: format.ResourceTypePod, Name: "a", Namespace: "default", Status: "Running"}
+	b := &format.Resource{Type: format.ResourceTypePod, Name: "b", Namespace: "default", Status: "Running"}
+	pvc := &format.Resource{Type: format.ResourceTypePVC, Name: "data", Namespace: "default", Status: "Bound"}
+	svc := &format.Resource{Type: format.ResourceTypeService, Name: "web", Namespace: "default"}
+	g := format.NewGraph(nil)
+	for _, res := range []*format.Resource{train, pod, a, b, pvc, svc} {
+		g.AddResource(res)
+	}
+	g.AddEdge(train, pod, format.EdgeTypeOwnerReference, "metadata.ownerReferences[0]")
+	g.AddEdge(pod, pvc, format.EdgeTypeVolumeMount, "spec.volumes[0].persistentVolumeClaim.claimName")
+	// A cycle without an entry point.
+	g.AddEdge(a, b, format.EdgeTypeVolumeMount, "")
+	g.AddEdge(b, a, format.EdgeTypeVolumeMount, "")
+
+	e := New(g, nil)
+	want := []string{
+		"Deployment/default/train *",
+		"  Pod/default/train-0",
+		"    PersistentVolumeClaim/default/data",
+		"Service/default/web",
+		"Pod/default/a",
+		"  Pod/default/b",
+	}
+	if got := rowStrings(e); !slices.Equal(got, want) {
+		t.Errorf("rows = %q, want %q", got, want)
+	}
+	press(e, keyEnd, keyLeft)
+	if want := "Pod/default/a"; e.cursor != want {
+		t.Errorf("cursor = %q, want %q", e.cursor, want)
+	}
+	if screen := e.render(); !strings.Contains(screen, "Deployment train") {
+		t.Errorf("screen does not show the deployment:\n%s", screen)
+	}
+}
+
+func TestDetailAges(t *testing.T) {
+	clock := time.Date(2024, 5, 4, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name        string
+		collectedAt time.Time
+		want        string
+	}{
+		{name: "collection time", collectedAt: collectedAt, want: "(2h0m ago)"},
+		{name: "clock", want: "(1d2h ago)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := testGraph()
+			g.Metadata.CollectedAt = tt.collectedAt
+			e := New(g, nil)
+			e.clock = func() time.Time { return clock }
+			g.Root.CreationTimestamp = collectedAt.Add(-2 * time.Hour)
+			deleted := collectedAt.Add(-2 * time.Hour)
+			g.Root.DeletionTimestamp = &deleted
+			var text []string
+			for _, l := range e.detailLines(g.Root, 80) {
+				text = append(text, l.text)
+			}
+			got := strings.Join(text, "\n")
+			for _, s := range []string{tt.want, "Deleting since " + strings.Trim(tt.want, "()")} {
+				if !strings.Contains(got, s) {
+					t.Errorf("detail lines do not contain %q:\n%s", s, got)
+				}
+			}
+		})
+	}
+}
+
+func TestDetailRelationships(t *testing.T) {
+	g := testGraph()
+	e := New(g, nil)
+	w0 := g.Resources[format.ResourceTypePod][0]
+	var text []string
+	for _, l := range e.detailLines(w0, 80) {
+		text = append(text, l.text)
+	}
+	got := strings.Join(text, "\n")
+	want := "Relationships\n  ← labelSelector Dataset/default/imagenet\n    fluid.io/dataset=imagenet\n" +
+		"  → volumeMount PersistentVolumeClaim/default/imagenet\n    spec.volumes[0].persistentVolumeClaim.claimName"
+	if !strings.HasSuffix(got, want) {
+		t.Errorf("detail lines end in\n%s\nwant\n%s", got, want)
+	}
+}
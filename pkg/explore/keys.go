@@ -0,0 +1,116 @@
+package explore
+
+import (
+	"io"
+	"unicode/utf8"
+)
+
+// Key names returned by decodeKeys. Printable keys are returned as the
+// character itself.
+const (
+	keyUp        = "up"
+	keyDown      = "down"
+	keyLeft      = "left"
+	keyRight     = "right"
+	keyHome      = "home"
+	keyEnd       = "end"
+	keyPageUp    = "pgup"
+	keyPageDown  = "pgdn"
+	keyEnter     = "enter"
+	keyTab       = "tab"
+	keyEscape    = "esc"
+	keyBackspace = "backspace"
+	keyCtrlC     = "ctrl-c"
+)
+
+var escapeSequences = map[string]string{
+	"[A": keyUp, "[B": keyDown, "[C": keyRight, "[D": keyLeft,
+	"OA": keyUp, "OB": keyDown, "OC": keyRight, "OD": keyLeft,
+	"[H": keyHome, "[F": keyEnd, "OH": keyHome, "OF": keyEnd,
+	"[1~": keyHome, "[4~": keyEnd, "[7~": keyHome, "[8~": keyEnd,
+	"[5~": keyPageUp, "[6~": keyPageDown,
+}
+
+// maxPending bounds the bytes kept for an unfinished escape sequence, so
+// that garbage without a final byte is eventually dropped.
+const maxPending = 16
+
+// keyDecoder splits the bytes read from a raw-mode terminal into keys. An
+// escape sequence or character cut off at the end of a read is kept for
+// the next one, while a lone escape byte is the Escape key.
+type keyDecoder struct {
+	pending []byte
+}
+
+// decode returns the keys completed by the bytes of one read. Unknown
+// escape sequences are dropped.
+func (d *keyDecoder) decode(b []byte) []string {
+	b = append(d.pending, b...)
+	d.pending = nil
+	var keys []string
+	for len(b) > 0 {
+		switch c := b[0]; {
+		case c == 0x1b:
+			if len(b) == 1 {
+				keys = append(keys, keyEscape)
+				b = b[1:]
+				continue
+			}
+			n := 2
+			if b[1] == '[' || b[1] == 'O' {
+				for n < len(b) && (b[n] < 0x40 || b[n] > 0x7e) {
+					n++
+				}
+				if n == len(b) {
+					if len(b) < maxPending {
+						d.pending = b
+					}
+					return keys
+				}
+				n++
+			}
+			if key, ok := escapeSequences[string(b[1:n])]; ok {
+				keys = append(keys, key)
+			}
+			b = b[n:]
+		case c == '\r' || c == '\n':
+			keys = append(keys, keyEnter)
+			b = b[1:]
+		case c == '\t':
+			keys = append(keys, keyTab)
+			b = b[1:]
+		case c == 0x7f || c == 0x08:
+			keys = append(keys, keyBackspace)
+			b = b[1:]
+		case c == 0x03:
+			keys = append(keys, keyCtrlC)
+			b = b[1:]
+		case c < 0x20:
+			b = b[1:]
+		default:
+			if !utf8.FullRune(b) {
+				d.pending = b
+				return keys
+			}
+			r, size := utf8.DecodeRune(b)
+			keys = append(keys, string(r))
+			b = b[size:]
+		}
+	}
+	return keys
+}
+
+// readInput sends what each read from r returns until it fails.
+func readInput(r io.Reader, input chan<- []byte) {
+	defer close(input)
+	buf := make([]byte, 256)
+	for {
+		n, err := r.Read(buf)
+		if n > 0 {
+			input <- append([]byte(nil), buf[:n]...)
+		}
+		if err != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package explore
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		name  string
+		reads []string
+		want  []string
+	}{
+		{name: "printable", reads: []string{"jk/é"}, want: []string{"j", "k", "/", "é"}},
+		{name: "control", reads: []string{"\r\n\t\x7f\x08\x03\x01"},
+			want: []string{keyEnter, keyEnter, keyTab, keyBackspace, keyBackspace, keyCtrlC}},
+		{name: "arrows", reads: []string{"\x1b[A\x1b[B\x1bOC\x1bOD"}, want: []string{keyUp, keyDown, keyRight, keyLeft}},
+		{name: "tilde sequences", reads: []string{"\x1b[5~\x1b[6~\x1b[1~\x1b[4~"},
+			want: []string{keyPageUp, keyPageDown, keyHome, keyEnd}},
+		{name: "unknown sequence", reads: []string{"\x1b[15~q"}, want: []string{"q"}},
+		{name: "lone escape", reads: []string{"\x1b", "q"}, want: []string{keyEscape, "q"}},
+		{name: "split after bracket", reads: []string{"j\x1b[", "Ak"}, want: []string{"j", keyUp, "k"}},
+		{name: "split in parameters", reads: []string{"\x1b[5", "~"}, want: []string{keyPageUp}},
+		{name: "split three times", reads: []string{"\x1bO", "", "H"}, want: []string{keyHome}},
+		{name: "split rune", reads: []string{"\xc3", "\xa9"}, want: []string{"é"}},
+		{name: "unfinished garbage", reads: []string{"\x1b[0123456789;0123456789", "q"}, want: []string{"q"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var d keyDecoder
+			var got []string
+			for _, r := range tt.reads {
+				got = append(got, d.decode([]byte(r))...)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("keys = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
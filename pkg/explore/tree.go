@@ -0,0 +1,174 @@
+package explore
+
+import (
+	"7h3-3mp7y-m4n/kubectl-graph/pkg/format"
+	"strings"
+)
+
+// pathSeparator joins the IDs from the root to a row into the row's path.
+// A resource reachable along several edges, such as a PVC owned by the
+// dataset and mounted by a pod, appears once per path.
+const pathSeparator = " > "
+
+// row is one line of the tree.
+type row struct {
+	res  *format.Resource
+	path string
+	// edge is the relationship from the parent row, empty for the root.
+	edge        format.EdgeType
+	depth       int
+	hasChildren bool
+	expanded    bool
+}
+
+type child struct {
+	res  *format.Resource
+	edge format.EdgeType
+}
+
+// tree flattens a graph into rows. expanded records the rows the user
+// opened or closed; rows not in it are open down to defaultDepth.
+type tree struct {
+	graph    *format.Graph
+	expanded map[string]bool
+	// keep, when set, hides the subtrees without a resource it accepts.
+	keep func(res *format.Resource) bool
+}
+
+const defaultDepth = 2
+
+func (t *tree) isExpanded(path string, depth int) bool {
+	if open, ok := t.expanded[path]; ok {
+		return open
+	}
+	return depth < defaultDepth
+}
+
+// rows returns the visible rows, or every row when all is set.
+func (t *tree) rows(all bool) []row {
+	if t.graph == nil {
+		return nil
+	}
+	children := make(map[*format.Resource][]child)
+	for _, e := range t.graph.Edges {
+		children[e.From] = append(children[e.From], child{res: e.To, edge: e.Type})
+	}
+	kept := make(map[*format.Resource]bool)
+	var keeps func(res *format.Resource, seen map[*format.Resource]bool) bool
+	keeps = func(res *format.Resource, seen map[*format.Resource]bool) bool {
+		if t.keep == nil || t.keep(res) {
+			return true
+		}
+		if v, ok := kept[res]; ok {
+			return v
+		}
+		seen[res] = true
+		defer delete(seen, res)
+		for _, c := range children[res] {
+			if !seen[c.res] && keeps(c.res, seen) {
+				kept[res] = true
+				return true
+			}
+		}
+		kept[res] = false
+		return false
+	}
+
+	var rows []row
+	ancestors := make(map[*format.Resource]bool)
+	var walk func(res *format.Resource, path string, edge format.EdgeType, depth int)
+	walk = func(res *format.Resource, path string, edge format.EdgeType, depth int) {
+		var visible []child
+		for _, c := range children[res] {
+			// An edge back to an ancestor would recurse forever.
+			if !ancestors[c.res] && keeps(c.res, map[*format.Resource]bool{}) {
+				visible = append(visible, c)
+			}
+		}
+		r := row{res: res, path: path, edge: edge, depth: depth, hasChildren: len(visible) > 0}
+		r.expanded = r.hasChildren && (all || t.isExpanded(path, depth))
+		rows = append(rows, r)
+		if !r.expanded {
+			return
+		}
+		ancestors[res] = true
+		for _, c := range visible {
+			walk(c.res, path+pathSeparator+c.res.ID(), c.edge, depth+1)
+		}
+		delete(ancestors, res)
+	}
+	for _, top := range t.tops(children) {
+		walk(top, top.ID(), "", 0)
+	}
+	return rows
+}
+
+// tops returns the resources at the top of the tree: the root, or for
+// graphs without one, such as query results, the resources without an
+// incoming edge followed by one resource of each cycle not reachable from
+// them.
+func (t *tree) tops(children map[*format.Resource][]child) []*format.Resource {
+	if t.graph.Root != nil {
+		return []*format.Resource{t.graph.Root}
+	}
+	targets := make(map[*format.Resource]bool)
+	for _, e := range t.graph.Edges {
+		targets[e.To] = true
+	}
+	reached := make(map[*format.Resource]bool)
+	var reach func(res *format.Resource)
+	reach = func(res *format.Resource) {
+		if reached[res] {
+			return
+		}
+		reached[res] = true
+		for _, c := range children[res] {
+			reach(c.res)
+		}
+	}
+	var tops []*format.Resource
+	nodes := t.graph.Nodes()
+	for _, res := range nodes {
+		if !targets[res] {
+			tops = append(tops, res)
+			reach(res)
+		}
+	}
+	for _, res := range nodes {
+		if !reached[res] {
+			tops = append(tops, res)
+			reach(res)
+		}
+	}
+	return tops
+}
+
+// reveal expands every row above path.
+func (t *tree) reveal(path string) {
+	parts := strings.Split(path, pathSeparator)
+	for i := 1; i < len(parts); i++ {
+		t.expanded[strings.Join(parts[:i], pathSeparator)] = true
+	}
+}
+
+// unhealthy is the filter behind the u key: resources with a warning or
+// failing status, restarts, or a pending deletion.
+func unhealthy(res *format.Resource) bool {
+	switch format.StatusHealth(res.Status) {
+	case format.HealthWarning, format.HealthFailing:
+		return true
+	}
+	if d, ok := res.Detail("restarts"); ok {
+		if n, _ := d.Int(); n > 0 {
+			return true
+		}
+	}
+	return res.DeletionTimestamp != nil
+}
+
+// matches reports whether the search query q, compared case-insensitively,
+// is part of the resource's ID or status.
+func matches(res *format.Resource, q string) bool {
+	q = strings.ToLower(q)
+	return strings.Contains(strings.ToLower(res.ID()), q) || strings.Contains(strings.ToLower(res.Status), q)
+}
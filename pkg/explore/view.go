@@ -0,0 +1,244 @@
+package explore
+
+import (
+	"7h3-3mp7y-m4n/kubectl-graph/pkg/format"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	styleBold    = "\x1b[1m"
+	styleDim     = "\x1b[2m"
+	styleReverse = "\x1b[7m"
+	styleReset   = "\x1b[0m"
+)
+
+var healthStyles = map[format.Health]string{
+	format.HealthOK:      "\x1b[32m",
+	format.HealthWarning: "\x1b[33m",
+	format.HealthFailing: "\x1b[31m",
+}
+
+// line is a line of the detail pane. Styles are applied after the text
+// is cut to the pane width, so that escape codes are never cut.
+type line struct {
+	text  string
+	style string
+}
+
+func styled(text, style string) string {
+	if style == "" {
+		return text
+	}
+	return style + text + styleReset
+}
+
+// render returns the escape codes and text that redraw the whole screen.
+func (e *Explorer) render() string {
+	w, h := e.width, e.height
+	if w <= 0 || h <= 0 {
+		w, h = 80, 24
+	}
+	body := max(h-2, 1)
+	leftW := min(max(w*2/5, 24), w/2)
+	rightW := max(w-leftW-1, 1)
+
+	rows := e.rows()
+	ci := e.cursorIndex(rows)
+	if ci < e.top {
+		e.top = max(ci, 0)
+	}
+	if ci >= e.top+body {
+		e.top = ci - body + 1
+	}
+
+	var detail []line
+	if ci >= 0 {
+		if e.pane == paneYAML {
+			detail = yamlLines(rows[ci].res)
+		} else {
+			detail = e.detailLines(rows[ci].res, rightW-1)
+		}
+	}
+	e.scroll = min(e.scroll, max(len(detail)-body, 0))
+
+	lines := make([]string, 0, h)
+	lines = append(lines, styled(format.PadRight(format.Truncate(e.title(), w), w), styleReverse))
+	for i := 0; i < body; i++ {
+		left := strings.Repeat(" ", leftW)
+		if r := e.top + i; r < len(rows) {
+			left = e.treeLine(rows[r], r == ci, leftW)
+		}
+		right := ""
+		if d := e.scroll + i; d < len(detail) {
+			right = " " + styled(format.Truncate(detail[d].text, rightW-1), detail[d].style)
+		}
+		lines = append(lines, left+styled("│", styleDim)+right)
+	}
+	lines = append(lines, e.statusLine(w))
+	return "\x1b[H" + strings.Join(lines, "\x1b[K\r\n") + "\x1b[K"
+}
+
+func (e *Explorer) title() string {
+	g := e.tree.graph
+	parts := []string{"kubectl graph explore"}
+	if g.Root != nil {
+		parts = append(parts, fmt.Sprintf("%s %s", g.Root.Type, resourcePath(g.Root)))
+	}
+	parts = append(parts, fmt.Sprintf("%d resources", len(g.Nodes())))
+	if !g.Metadata.CollectedAt.IsZero() {
+		parts = append(parts, "collected "+g.Metadata.CollectedAt.Local().Format("15:04:05"))
+	}
+	if e.unhealthyOnly {
+		parts = append(parts, "unhealthy only")
+	}
+	if e.pane == paneYAML {
+		parts = append(parts, "yaml")
+	}
+	return " " + strings.Join(parts, " │ ")
+}
+
+func (e *Explorer) treeLine(r row, selected bool, width int) string {
+	marker := "  "
+	if r.hasChildren {
+		marker = "▸ "
+		if r.expanded {
+			marker = "▾ "
+		}
+	}
+	name := fmt.Sprintf("%s%s%s %s", strings.Repeat("  ", r.depth), marker, r.res.Type, r.res.Name)
+	text := name + "  " + r.res.Status
+	if selected {
+		return styled(format.PadRight(format.Truncate(text, width), width), styleReverse)
+	}
+	if format.DisplayWidth(text) > width {
+		return format.PadRight(format.Truncate(text, width), width)
+	}
+	status := styled(r.res.Status, healthStyles[format.StatusHealth(r.res.Status)])
+	if e.search != "" && matches(r.res, e.search) {
+		name = styled(name, styleBold)
+	}
+	return name + "  " + status + strings.Repeat(" ", width-format.DisplayWidth(text))
+}
+
+func (e *Explorer) statusLine(width int) string {
+	switch {
+	case e.searching:
+		return format.Truncate("/"+e.input+"█", width)
+	case e.message != "":
+		return format.Truncate(e.message, width)
+	default:
+		return styled(format.Truncate(helpText, width), styleDim)
+	}
+}
+
+// detailLines describes res: its metadata, details, labels, conditions
+// and relationships. Condition messages wrap at width.
+func (e *Explorer) detailLines(res *format.Resource, width int) []line {
+	now := e.now()
+	lines := []line{{text: fmt.Sprintf("%s %s", res.Type, resourcePath(res)), style: styleBold}}
+	kind := res.Kind
+	if res.APIGroup != "" {
+		kind += "." + res.APIGroup
+	}
+	if kind != "" {
+		lines = append(lines, line{text: "Kind:    " + kind})
+	}
+	lines = append(lines, line{text: "Status:  " + res.Status, style: healthStyles[format.StatusHealth(res.Status)]})
+	if !res.CreationTimestamp.IsZero() {
+		lines = append(lines, line{text: fmt.Sprintf("Created: %s (%s ago)",
+			res.CreationTimestamp.Local().Format(time.RFC3339), format.FormatAge(res.Age(now)))})
+	}
+	if res.DeletionTimestamp != nil {
+		lines = append(lines, line{text: fmt.Sprintf("Deleting since %s ago", format.FormatAge(now.Sub(*res.DeletionTimestamp))),
+			style: healthStyles[format.HealthWarning]})
+	}
+
+	lines = append(lines, line{}, line{text: "Details", style: styleBold})
+	if len(res.Details) == 0 {
+		lines = append(lines, line{text: "  <none>", style: styleDim})
+	}
+	for _, d := range res.Details {
+		lines = append(lines, line{text: fmt.Sprintf("  %s: %s", d.Key, d.String())})
+	}
+
+	lines = append(lines, line{}, line{text: "Labels", style: styleBold})
+	keys := make([]string, 0, len(res.Labels))
+	for k := range res.Labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	if len(keys) == 0 {
+		lines = append(lines, line{text: "  <none>", style: styleDim})
+	}
+	for _, k := range keys {
+		lines = append(lines, line{text: fmt.Sprintf("  %s=%s", k, res.Labels[k])})
+	}
+
+	lines = append(lines, line{}, line{text: "Conditions", style: styleBold})
+	if len(res.Conditions) == 0 {
+		lines = append(lines, line{text: "  <none>", style: styleDim})
+	}
+	for _, c := range res.Conditions {
+		style := ""
+		if c.Status != "True" {
+			style = healthStyles[format.HealthWarning]
+		}
+		lines = append(lines, line{text: strings.TrimSpace(fmt.Sprintf("  %s=%s %s", c.Type, c.Status, c.Reason)), style: style})
+		if c.Message != "" {
+			for _, l := range format.WrapText(c.Message, max(width-4, 10)) {
+				lines = append(lines, line{text: "    " + l, style: styleDim})
+			}
+		}
+	}
+
+	lines = append(lines, line{}, line{text: "Relationships", style: styleBold})
+	found := false
+	for _, edge := range e.tree.graph.Edges {
+		var text string
+		switch {
+		case edge.From == res:
+			text = fmt.Sprintf("  → %s %s", edge.Type, edge.To.ID())
+		case edge.To == res:
+			text = fmt.Sprintf("  ← %s %s", edge.Type, edge.From.ID())
+		default:
+			continue
+		}
+		found = true
+		lines = append(lines, line{text: text})
+		if edge.Evidence != "" {
+			lines = append(lines, line{text: "    " + edge.Evidence, style: styleDim})
+		}
+	}
+	if !found {
+		lines = append(lines, line{text: "  <none>", style: styleDim})
+	}
+	return lines
+}
+
+// yamlLines returns the raw object as YAML.
+func yamlLines(res *format.Resource) []line {
+	lines := []line{{text: fmt.Sprintf("%s %s", res.Type, resourcePath(res)), style: styleBold}, {}}
+	if res.Object == nil {
+		return append(lines, line{text: "The raw object is only available for graphs collected from a cluster.", style: styleDim})
+	}
+	data, err := yaml.Marshal(res.Object)
+	if err != nil {
+		return append(lines, line{text: err.Error()})
+	}
+	for _, l := range strings.Split(strings.TrimRight(string(data), "\n"), "\n") {
+		lines = append(lines, line{text: l})
+	}
+	return lines
+}
+
+func resourcePath(res *format.Resource) string {
+	if res.Namespace == "" {
+		return res.Name
+	}
+	return res.Namespace + "/" + res.Name
+}
@@ -0,0 +1,117 @@
+package format
+
+import (
+	"sort"
+	"strconv"
+	"time"
+)
+
+// attributeKind is the value type of an exported attribute.
+type attributeKind string
+
+const (
+	attributeString attributeKind = "string"
+	attributeLong   attributeKind = "long"
+)
+
+// attribute describes one column of the flattened node table shared by the
+// GraphML and GEXF exports.
+type attribute struct {
+	Name string
+	Kind attributeKind
+}
+
+var baseNodeAttributes = []attribute{
+	{Name: "type", Kind: attributeString},
+	{Name: "name", Kind: attributeString},
+	{Name: "namespace", Kind: attributeString},
+	{Name: "status", Kind: attributeString},
+	{Name: "creationTimestamp", Kind: attributeString},
+	{Name: "deletionTimestamp", Kind: attributeString},
+}
+
+var edgeAttributes = []attribute{
+	{Name: "type", Kind: attributeString},
+	{Name: "evidence", Kind: attributeString},
+}
+
+// nodeAttributes returns the base attributes followed by one detail.<key>
+// attribute per detail key and one label.<key> attribute per label key, both
+// sorted. A detail is exported as a long only if it is an integer on every
+// node that has it.
+func nodeAttributes(nodes []*Resource) []attribute {
+	detailKinds := make(map[string]attributeKind)
+	labels := make(map[string]bool)
+	for _, res := range nodes {
+		for _, d := range res.Details {
+			kind := attributeString
+			if _, ok := d.Int(); ok {
+				kind = attributeLong
+			}
+			if prev, ok := detailKinds[d.Key]; ok && prev != kind {
+				kind = attributeString
+			}
+			detailKinds[d.Key] = kind
+		}
+		for k := range res.Labels {
+			labels[k] = true
+		}
+	}
+
+	attrs := append([]attribute(nil), baseNodeAttributes...)
+	detailKeys := make([]string, 0, len(detailKinds))
+	for k := range detailKinds {
+		detailKeys = append(detailKeys, k)
+	}
+	sort.Strings(detailKeys)
+	for _, k := range detailKeys {
+		attrs = append(attrs, attribute{Name: "detail." + k, Kind: detailKinds[k]})
+	}
+	labelKeys := make([]string, 0, len(labels))
+	for k := range labels {
+		labelKeys = append(labelKeys, k)
+	}
+	sort.Strings(labelKeys)
+	for _, k := range labelKeys {
+		attrs = append(attrs, attribute{Name: "label." + k, Kind: attributeString})
+	}
+	return attrs
+}
+
+// nodeAttributeValues returns the attribute values set on res, rendered as
+// strings. Attributes the resource does not have are left out.
+func nodeAttributeValues(res *Resource) map[string]string {
+	values := map[string]string{
+		"type":   string(res.Type),
+		"name":   res.Name,
+		"status": res.Status,
+	}
+	if res.Namespace != "" {
+		values["namespace"] = res.Namespace
+	}
+	if !res.CreationTimestamp.IsZero() {
+		values["creationTimestamp"] = res.CreationTimestamp.UTC().Format(time.RFC3339)
+	}
+	if res.DeletionTimestamp != nil {
+		values["deletionTimestamp"] = res.DeletionTimestamp.UTC().Format(time.RFC3339)
+	}
+	for _, d := range res.Details {
+		if v, ok := d.Int(); ok {
+			values["detail."+d.Key] = strconv.FormatInt(v, 10)
+		} else {
+			values["detail."+d.Key] = d.String()
+		}
+	}
+	for k, v := range res.Labels {
+		values["label."+k] = v
+	}
+	return values
+}
+
+func edgeAttributeValues(e Edge) map[string]string {
+	values := map[string]string{"type": string(e.Type)}
+	if e.Evidence != "" {
+		values["evidence"] = e.Evidence
+	}
+	return values
+}
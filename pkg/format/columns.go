@@ -0,0 +1,531 @@
+package format
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"k8s.io/client-go/util/jsonpath"
+)
+
+// column is one column of a table.
+type column struct {
+	header string
+	// width is the preferred minimum width of the column; wider values
+	// widen it.
+	width int
+	// priority decides which columns give up space when a table is wider
+	// than the terminal: higher numbers are narrowed first and 0 is never
+	// narrowed.
+	priority int
+	value    func(res *Resource) string
+	// sortValue returns what --sort-by compares, an int64 or a string.
+	// When nil, the column sorts by value.
+	sortValue func(res *Resource) interface{}
+	colorize  func(s string) string
+}
+
+func (c column) sortKey(res *Resource) interface{} {
+	if c.sortValue != nil {
+		return c.sortValue(res)
+	}
+	return c.value(res)
+}
+
+// minColumnWidth is the narrowest a column is truncated to, unless its
+// header is wider.
+const minColumnWidth = 8
+
+// resourceRows evaluates columns for every resource.
+func resourceRows(columns []column, resources []*Resource) [][]string {
+	rows := make([][]string, len(resources))
+	for i, res := range resources {
+		rows[i] = make([]string, len(columns))
+		for j, c := range columns {
+			rows[i][j] = c.value(res)
+		}
+	}
+	return rows
+}
+
+// printColumns writes a header, a rule when rule is set, and rows. When
+// maxWidth is positive the table is fitted to it: cells are truncated,
+// except in the last column, which wraps onto continuation lines.
+func printColumns(w io.Writer, indent string, columns []column, rows [][]string, rule bool, maxWidth int) {
+	widths := fitColumns(columns, rows, DisplayWidth(indent), maxWidth)
+	last := len(columns) - 1
+
+	// line renders one row, which is several lines when the last cell wraps.
+	line := func(cells []string, colorize bool) string {
+		var b strings.Builder
+		var wrapped []string
+		b.WriteString(indent)
+		for j, cell := range cells {
+			if j > 0 {
+				b.WriteByte(' ')
+			}
+			if j < last {
+				cell = Truncate(cell, widths[j])
+			} else if maxWidth > 0 {
+				lines := WrapText(cell, widths[j])
+				cell, wrapped = lines[0], lines[1:]
+			}
+			// Colors are matched against the bare value, so cells are
+			// padded after they are colored; PadRight skips the codes.
+			if colorize && columns[j].colorize != nil {
+				cell = columns[j].colorize(cell)
+			}
+			if j < last {
+				cell = PadRight(cell, widths[j])
+			}
+			b.WriteString(cell)
+		}
+		out := strings.TrimRight(b.String(), " ")
+		if len(wrapped) > 0 {
+			margin := strings.Repeat(" ", DisplayWidth(indent)+lineWidth(widths[:last])+1)
+			for _, l := range wrapped {
+				if colorize && columns[last].colorize != nil {
+					l = columns[last].colorize(l)
+				}
+				out += "\n" + margin + l
+			}
+		}
+		return out
+	}
+
+	headers := make([]string, len(columns))
+	for j, c := range columns {
+		headers[j] = c.header
+	}
+	fmt.Fprintln(w, line(headers, false))
+	if rule {
+		ruleWidth := max(lineWidth(widths), 100)
+		if maxWidth > 0 {
+			ruleWidth = min(ruleWidth, maxWidth-DisplayWidth(indent))
+		}
+		fmt.Fprintf(w, "%s%s\n", indent, strings.Repeat("-", ruleWidth))
+	}
+	for _, row := range rows {
+		fmt.Fprintln(w, line(row, true))
+	}
+}
+
+// lineWidth returns the width of columns of the given widths, including
+// the space after each one but the last.
+func lineWidth(widths []int) int {
+	n := len(widths)
+	for _, width := range widths {
+		n += width
+	}
+	if len(widths) > 0 {
+		n--
+	}
+	return n
+}
+
+// fitColumns returns the width of each column. Columns are as wide as
+// their widest cell and at least their preferred width. When that does
+// not fit in maxWidth, preferred widths are dropped first. Then, starting
+// with the highest priority number, the widest columns of each priority
+// are narrowed down to minColumnWidth or their header. The last column
+// wraps rather than truncates, so it is first only narrowed to a quarter
+// of the terminal, and further only when nothing else is left.
+func fitColumns(columns []column, rows [][]string, indent, maxWidth int) []int {
+	natural := make([]int, len(columns))
+	preferred := make([]int, len(columns))
+	for j, c := range columns {
+		natural[j] = DisplayWidth(c.header)
+		for _, row := range rows {
+			natural[j] = max(natural[j], DisplayWidth(row[j]))
+		}
+		preferred[j] = max(natural[j], c.width)
+	}
+	if maxWidth <= 0 || indent+lineWidth(preferred) <= maxWidth {
+		return preferred
+	}
+
+	widths := natural
+	overflow := indent + lineWidth(widths) - maxWidth
+	floors := make([]int, len(columns))
+	for j, c := range columns {
+		floors[j] = min(widths[j], max(minColumnWidth, DisplayWidth(c.header)))
+	}
+	last := len(columns) - 1
+	comfortable := make([]int, len(columns))
+	copy(comfortable, floors)
+	comfortable[last] = min(widths[last], max(floors[last], maxWidth/4))
+
+	priorities := make([]int, 0, len(columns))
+	for _, c := range columns {
+		if c.priority > 0 && !slices.Contains(priorities, c.priority) {
+			priorities = append(priorities, c.priority)
+		}
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(priorities)))
+
+	for _, limits := range [][]int{comfortable, floors} {
+		for _, p := range priorities {
+			// Narrow the widest column of this priority one cell at a
+			// time, so columns of equal priority share the loss.
+			for overflow > 0 {
+				widest := -1
+				for j, c := range columns {
+					if c.priority == p && widths[j] > limits[j] && (widest < 0 || widths[j] > widths[widest]) {
+						widest = j
+					}
+				}
+				if widest < 0 {
+					break
+				}
+				widths[widest]--
+				overflow--
+			}
+		}
+	}
+	return widths
+}
+
+// detailColumns returns one column per detail key found in resources,
+// ordered by priority and then by first appearance.
+func detailColumns(resources []*Resource) []column {
+	var keys []string
+	priorities := make(map[string]DetailPriority)
+	for _, res := range resources {
+		for _, d := range res.Details {
+			if p, ok := priorities[d.Key]; !ok || d.Priority < p {
+				if !ok {
+					keys = append(keys, d.Key)
+				}
+				priorities[d.Key] = d.Priority
+			}
+		}
+	}
+	sort.SliceStable(keys, func(i, j int) bool {
+		return priorities[keys[i]] < priorities[keys[j]]
+	})
+
+	columns := make([]column, 0, len(keys))
+	for _, key := range keys {
+		columns = append(columns, column{
+			header:   strings.ToUpper(key),
+			priority: 3,
+			value: func(res *Resource) string {
+				d, ok := res.Detail(key)
+				if !ok {
+					return "<none>"
+				}
+				return d.String()
+			},
+			sortValue: func(res *Resource) interface{} {
+				d, ok := res.Detail(key)
+				if !ok {
+					return ""
+				}
+				if n, ok := d.Int(); ok {
+					return n
+				}
+				return d.String()
+			},
+		})
+	}
+	return columns
+}
+
+// labelColumns returns the LABELS column when showLabels is set and one
+// column per key in keys, headed by the last segment of the key as
+// kubectl's -L does.
+func labelColumns(showLabels bool, keys []string) []column {
+	var columns []column
+	if showLabels {
+		columns = append(columns, column{header: "LABELS", priority: 4, value: formatLabels})
+	}
+	for _, key := range keys {
+		header := key
+		if i := strings.LastIndex(header, "/"); i >= 0 {
+			header = header[i+1:]
+		}
+		columns = append(columns, column{
+			header:   strings.ToUpper(header),
+			priority: 4,
+			value:    func(res *Resource) string { return res.Labels[key] },
+		})
+	}
+	return columns
+}
+
+func formatLabels(res *Resource) string {
+	if len(res.Labels) == 0 {
+		return "<none>"
+	}
+	pairs := make([]string, 0, len(res.Labels))
+	for k, v := range res.Labels {
+		pairs = append(pairs, k+"="+v)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+// sortKeyFunc returns the key rows are sorted by for sortBy, which is
+// either the header of one of columns or a JSONPath expression over the
+// row object. It returns nil when sortBy is empty or names a column that
+// is not in columns.
+func sortKeyFunc(columns []column, sortBy string, now time.Time) (func(res *Resource) interface{}, error) {
+	if sortBy == "" {
+		return nil, nil
+	}
+	for _, c := range columns {
+		if strings.EqualFold(c.header, sortBy) {
+			return c.sortKey, nil
+		}
+	}
+	if !strings.ContainsAny(sortBy[:1], ".{$") {
+		return nil, nil
+	}
+	jp, err := parseRowPath(sortBy)
+	if err != nil {
+		return nil, err
+	}
+	return func(res *Resource) interface{} {
+		values := rowValues(jp, rowObject(res, now))
+		if len(values) == 0 {
+			return ""
+		}
+		return values[0]
+	}, nil
+}
+
+// sortResources returns resources ordered by key. Numbers compare
+// numerically and everything else as strings; a nil key keeps the
+// collection order.
+func sortResources(resources []*Resource, key func(res *Resource) interface{}) []*Resource {
+	if key == nil {
+		return resources
+	}
+	keys := make(map[*Resource]interface{}, len(resources))
+	for _, res := range resources {
+		keys[res] = key(res)
+	}
+	sorted := make([]*Resource, len(resources))
+	copy(sorted, resources)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return lessValue(keys[sorted[i]], keys[sorted[j]])
+	})
+	return sorted
+}
+
+func unknownSortColumn(sortBy string, headers []string) error {
+	return fmt.Errorf("cannot sort by %q: not a column (%s) or a JSONPath expression", sortBy, strings.Join(headers, ", "))
+}
+
+func lessValue(a, b interface{}) bool {
+	fa, aNum := toFloat(a)
+	fb, bNum := toFloat(b)
+	if aNum && bNum {
+		return fa < fb
+	}
+	return fmt.Sprint(a) < fmt.Sprint(b)
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case int64:
+		return float64(n), true
+	case float64:
+		return n, true
+	case string:
+		f, err := strconv.ParseFloat(n, 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// rowObject returns the object custom columns and --sort-by expressions
+// are evaluated against: the resource's Node as generic maps, plus age,
+// the formatted age. Details are keyed by name rather than listed, so that
+// .details.restarts selects a single detail.
+func rowObject(res *Resource, now time.Time) map[string]interface{} {
+	data, err := json.Marshal(newNode(res))
+	if err != nil {
+		return nil
+	}
+	var obj map[string]interface{}
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return nil
+	}
+	obj["age"] = ResourceAge(res, now)
+	details := make(map[string]interface{}, len(res.Details))
+	for _, d := range res.Details {
+		details[d.Key] = d.Value
+	}
+	obj["details"] = details
+	return obj
+}
+
+// relaxedJSONPathRegexp strips the braces and a leading "$", ".", or "$."
+// as one prefix, so that "$.x" is not read as the recursive "..x".
+var relaxedJSONPathRegexp = regexp.MustCompile(`^\{?\$?\.?(.*?)\}?$`)
+
+// relaxedJSONPath accepts ".field", "$.field" and "field" in addition to
+// "{.field}", the same shorthand kubectl allows in custom columns and
+// --sort-by. -o jsonpath templates are parsed as written.
+func relaxedJSONPath(expr string) string {
+	if strings.Contains(strings.Trim(expr, "{}"), "{") {
+		return expr
+	}
+	m := relaxedJSONPathRegexp.FindStringSubmatch(expr)
+	if m == nil || m[1] == "" {
+		return expr
+	}
+	return "{." + m[1] + "}"
+}
+
+func parseRowPath(expr string) (*jsonpath.JSONPath, error) {
+	jp := jsonpath.New("column").AllowMissingKeys(true)
+	if err := jp.Parse(relaxedJSONPath(expr)); err != nil {
+		return nil, fmt.Errorf("error parsing jsonpath %s: %w", expr, err)
+	}
+	return jp, nil
+}
+
+// rowValues returns every value jp selects from obj.
+func rowValues(jp *jsonpath.JSONPath, obj map[string]interface{}) []interface{} {
+	results, err := jp.FindResults(obj)
+	if err != nil {
+		return nil
+	}
+	var values []interface{}
+	for _, result := range results {
+		for _, v := range result {
+			for v.Kind() == reflect.Interface || v.Kind() == reflect.Ptr {
+				if v.IsNil() {
+					break
+				}
+				v = v.Elem()
+			}
+			if v.IsValid() && v.CanInterface() {
+				values = append(values, v.Interface())
+			}
+		}
+	}
+	return values
+}
+
+// CustomColumnsFormatter prints one table over every node, with the columns
+// given as HEADER:JSONPATH pairs as kubectl's -o custom-columns does. Paths
+// are evaluated against the node as it appears in the json output, with
+// the additions described on rowObject.
+type CustomColumnsFormatter struct {
+	opts    Options
+	headers []string
+	paths   []*jsonpath.JSONPath
+}
+
+// NewCustomColumnsFormatter parses a spec such as
+// "NAME:.name,RESTARTS:.details.restarts".
+func NewCustomColumnsFormatter(spec string, opts Options) (*CustomColumnsFormatter, error) {
+	var headers, paths []string
+	for _, part := range strings.Split(spec, ",") {
+		header, path, ok := strings.Cut(part, ":")
+		if !ok || header == "" || path == "" {
+			return nil, fmt.Errorf("custom-columns format %q is invalid, expected HEADER:JSONPATH", part)
+		}
+		headers = append(headers, header)
+		paths = append(paths, path)
+	}
+	return newCustomColumnsFormatter(headers, paths, opts)
+}
+
+// NewCustomColumnsFormatterFromFile parses the file form of the spec: a
+// line of headers followed by a line of paths, both separated by
+// whitespace.
+func NewCustomColumnsFormatterFromFile(text string, opts Options) (*CustomColumnsFormatter, error) {
+	var lines []string
+	scanner := bufio.NewScanner(strings.NewReader(text))
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	if len(lines) != 2 {
+		return nil, fmt.Errorf("custom-columns file must have exactly two lines, headers and paths, found %d", len(lines))
+	}
+	headers := strings.Fields(lines[0])
+	paths := strings.Fields(lines[1])
+	if len(headers) != len(paths) {
+		return nil, fmt.Errorf("custom-columns file has %d headers but %d paths", len(headers), len(paths))
+	}
+	return newCustomColumnsFormatter(headers, paths, opts)
+}
+
+func newCustomColumnsFormatter(headers, paths []string, opts Options) (*CustomColumnsFormatter, error) {
+	cf := &CustomColumnsFormatter{opts: opts, headers: headers}
+	for _, path := range paths {
+		jp, err := parseRowPath(path)
+		if err != nil {
+			return nil, err
+		}
+		cf.paths = append(cf.paths, jp)
+	}
+	return cf, nil
+}
+
+func (cf *CustomColumnsFormatter) Format(w io.Writer, g *Graph) error {
+	now := cf.opts.now(g)
+	columns := make([]column, len(cf.headers))
+	for i, header := range cf.headers {
+		jp := cf.paths[i]
+		values := func(res *Resource) []interface{} {
+			return rowValues(jp, rowObject(res, now))
+		}
+		columns[i] = column{
+			header: header,
+			value: func(res *Resource) string {
+				return formatColumnValues(values(res))
+			},
+			sortValue: func(res *Resource) interface{} {
+				if v := values(res); len(v) > 0 {
+					return v[0]
+				}
+				return ""
+			},
+		}
+	}
+	key, err := sortKeyFunc(columns, cf.opts.SortBy, now)
+	if err != nil {
+		return err
+	}
+	if key == nil && cf.opts.SortBy != "" {
+		return unknownSortColumn(cf.opts.SortBy, cf.headers)
+	}
+	printColumns(w, "", columns, resourceRows(columns, sortResources(g.Nodes(), key)), false, 0)
+	return nil
+}
+
+func formatColumnValues(values []interface{}) string {
+	parts := make([]string, 0, len(values))
+	for _, v := range values {
+		switch v := v.(type) {
+		case string:
+			parts = append(parts, v)
+		case map[string]interface{}, []interface{}:
+			data, _ := json.Marshal(v)
+			parts = append(parts, string(data))
+		case float64:
+			parts = append(parts, strconv.FormatFloat(v, 'f', -1, 64))
+		default:
+			parts = append(parts, fmt.Sprint(v))
+		}
+	}
+	if len(parts) == 0 || strings.Join(parts, "") == "" {
+		return "<none>"
+	}
+	return strings.Join(parts, ",")
+}
@@ -0,0 +1,243 @@
+package format
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+var columnsNow = time.Date(2024, 5, 3, 12, 0, 0, 0, time.UTC)
+
+// columnsGraph is a dataset selecting three pods of different ages,
+// restarts and labels.
+func columnsGraph() *Graph {
+	root := &Resource{Type: ResourceTypeDataset, Name: "imagenet", Namespace: "default", Status: "Bound",
+		CreationTimestamp: columnsNow.Add(-72 * time.Hour)}
+	g := NewGraph(root)
+	g.Metadata.CollectedAt = columnsNow
+	pods := []*Resource{
+		{Type: ResourceTypePod, Name: "web-0", Namespace: "default", Status: "Running",
+			CreationTimestamp: columnsNow.Add(-5 * time.Hour),
+			Labels:            map[string]string{"app": "web", "fluid.io/dataset": "imagenet"},
+			Details:           []Detail{IntDetail("restarts", 3, "", DetailPriorityPrimary), StringDetail("node", "node-b", DetailPrioritySecondary)}},
+		{Type: ResourceTypePod, Name: "web-1", Namespace: "default", Status: "CrashLoopBackOff",
+			CreationTimestamp: columnsNow.Add(-time.Hour),
+			Labels:            map[string]string{"app": "web"},
+			Details:           []Detail{IntDetail("restarts", 10, "", DetailPriorityPrimary), StringDetail("node", "node-a", DetailPrioritySecondary)}},
+		{Type: ResourceTypePod, Name: "web-2", Namespace: "default", Status: "Pending",
+			CreationTimestamp: columnsNow.Add(-48 * time.Hour)},
+	}
+	for _, pod := range pods {
+		g.AddResource(pod)
+		g.AddEdge(root, pod, EdgeTypeLabelSelector, "fluid.io/dataset=imagenet")
+	}
+	return g
+}
+
+func render(t *testing.T, f Formatter, g *Graph) string {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := f.Format(&buf, g); err != nil {
+		t.Fatal(err)
+	}
+	return buf.String()
+}
+
+func TestCustomColumns(t *testing.T) {
+	tests := []struct {
+		name   string
+		spec   string
+		sortBy string
+		want   string
+	}{
+		{
+			name: "details and labels",
+			spec: "NAME:.name,STATUS:.status,RESTARTS:.details.restarts,APP:.labels.app,AGE:.age",
+			want: `NAME     STATUS           RESTARTS APP    AGE
+imagenet Bound            <none>   <none> 3d0h
+web-0    Running          3        web    5h0m
+web-1    CrashLoopBackOff 10       web    1h0m
+web-2    Pending          <none>   <none> 2d0h
+`,
+		},
+		{
+			name: "braces and lists",
+			spec: "ID:{.id},DETAILS:.details,LABELS:.labels",
+			want: `ID                       DETAILS                         LABELS
+Dataset/default/imagenet {}                              <none>
+Pod/default/web-0        {"node":"node-b","restarts":3}  {"app":"web","fluid.io/dataset":"imagenet"}
+Pod/default/web-1        {"node":"node-a","restarts":10} {"app":"web"}
+Pod/default/web-2        {}                              <none>
+`,
+		},
+		{
+			name:   "sort by column, numerically",
+			spec:   "NAME:.name,RESTARTS:.details.restarts",
+			sortBy: "restarts",
+			want: `NAME     RESTARTS
+imagenet <none>
+web-2    <none>
+web-0    3
+web-1    10
+`,
+		},
+		{
+			name:   "sort by detail path",
+			spec:   "NAME:.name",
+			sortBy: ".details.node",
+			want: `NAME
+imagenet
+web-2
+web-1
+web-0
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := NewCustomColumnsFormatter(tt.spec, Options{SortBy: tt.sortBy})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := render(t, f, columnsGraph()); got != tt.want {
+				t.Errorf("output =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCustomColumnsErrors(t *testing.T) {
+	for _, spec := range []string{"NAME", "NAME:", ":.name", "NAME:.name,", "NAME:.name["} {
+		if _, err := NewCustomColumnsFormatter(spec, Options{}); err == nil {
+			t.Errorf("NewCustomColumnsFormatter(%q) succeeded, want an error", spec)
+		}
+	}
+	f, err := NewCustomColumnsFormatter("NAME:.name", Options{SortBy: "AGE"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := f.Format(&buf, columnsGraph()); err == nil || !strings.Contains(err.Error(), `cannot sort by "AGE"`) {
+		t.Errorf("Format sorted by a missing column = %v, want an error", err)
+	}
+}
+
+func TestCustomColumnsFromFile(t *testing.T) {
+	f, err := NewCustomColumnsFormatterFromFile("\nNAME   RESTARTS\n.name  .details.restarts\n\n", Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := render(t, f, columnsGraph()), "NAME     RESTARTS\nimagenet <none>\nweb-0    3\n"; !strings.HasPrefix(got, want) {
+		t.Errorf("output =\n%s\nwant it to start with\n%s", got, want)
+	}
+	for _, text := range []string{"NAME", "NAME\n.name\n.status", "NAME STATUS\n.name"} {
+		if _, err := NewCustomColumnsFormatterFromFile(text, Options{}); err == nil {
+			t.Errorf("NewCustomColumnsFormatterFromFile(%q) succeeded, want an error", text)
+		}
+	}
+}
+
+// podNames returns the pod names in the order the Pods table lists them.
+func podNames(out string) []string {
+	var names []string
+	for _, line := range strings.Split(out, "\n") {
+		if fields := strings.Fields(line); len(fields) > 0 && strings.HasPrefix(fields[0], "web-") {
+			names = append(names, fields[0])
+		}
+	}
+	return names
+}
+
+func TestTableSortBy(t *testing.T) {
+	tests := []struct {
+		sortBy string
+		wide   bool
+		want   string
+	}{
+		{sortBy: "", want: "web-0 web-1 web-2"},
+		{sortBy: "AGE", want: "web-1 web-0 web-2"},
+		{sortBy: "age", want: "web-1 web-0 web-2"},
+		{sortBy: "NAME", want: "web-0 web-1 web-2"},
+		{sortBy: "STATUS", want: "web-1 web-2 web-0"},
+		{sortBy: ".details.restarts", want: "web-2 web-0 web-1"},
+		{sortBy: "{.details.node}", want: "web-2 web-1 web-0"},
+		{sortBy: "RESTARTS", wide: true, want: "web-2 web-0 web-1"},
+		{sortBy: "NODE", wide: true, want: "web-2 web-1 web-0"},
+	}
+	for _, tt := range tests {
+		opts := Options{SortBy: tt.sortBy}
+		f := NewTableFormatter(opts)
+		if tt.wide {
+			f = NewWideTableFormatter(opts)
+		}
+		if got := strings.Join(podNames(render(t, f, columnsGraph())), " "); got != tt.want {
+			t.Errorf("--sort-by=%s (wide %v): pods %s, want %s", tt.sortBy, tt.wide, got, tt.want)
+		}
+	}
+
+	var buf bytes.Buffer
+	err := NewTableFormatter(Options{SortBy: "RESTARTS"}).Format(&buf, columnsGraph())
+	if err == nil || !strings.Contains(err.Error(), "NAME, STATUS, AGE, DETAILS") {
+		t.Errorf("--sort-by=RESTARTS without -o wide = %v, want an error listing the columns", err)
+	}
+}
+
+func TestLabelColumns(t *testing.T) {
+	f := NewTableFormatter(Options{ShowLabels: true, LabelColumns: []string{"app", "fluid.io/dataset"}})
+	out := render(t, f, columnsGraph())
+	for _, want := range []string{
+		"  NAME                                     STATUS           AGE        DETAILS      LABELS                            APP DATASET\n",
+		"  web-0                                    Running          5h0m       restarts: 3  app=web,fluid.io/dataset=imagenet web imagenet\n",
+		"  web-1                                    CrashLoopBackOff 1h0m       restarts: 10 app=web                           web\n",
+		"  web-2                                    Pending          2d0h                    <none>\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output does not contain %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestRelaxedJSONPath(t *testing.T) {
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{"{.nodes[*].name}", "{.nodes[*].name}"},
+		{".nodes[*].name", "{.nodes[*].name}"},
+		{"$.nodes[*].name", "{.nodes[*].name}"},
+		{"{$.nodes[*].name}", "{.nodes[*].name}"},
+		{"nodes[*].name", "{.nodes[*].name}"},
+		{"..name", "{..name}"},
+		{"$..name", "{..name}"},
+		{"$", "$"},
+		{"{range .nodes[*]}{.name}{end}", "{range .nodes[*]}{.name}{end}"},
+	}
+	for _, tt := range tests {
+		if got := relaxedJSONPath(tt.expr); got != tt.want {
+			t.Errorf("relaxedJSONPath(%q) = %q, want %q", tt.expr, got, tt.want)
+		}
+	}
+}
+
+var ansiCodes = regexp.MustCompile("\x1b\\[[0-9;]*m")
+
+func TestStatusColor(t *testing.T) {
+	// Without a width the table is as wide as its content; at 70 columns
+	// the cells are truncated.
+	for _, width := range []int{0, 70} {
+		plain := render(t, NewTableFormatter(Options{Width: width}), columnsGraph())
+		colored := render(t, NewTableFormatter(Options{Color: true, Width: width}), columnsGraph())
+		for _, want := range []string{"\x1b[32mRunning\x1b[0m", "\x1b[31mCrashLoopBackOff\x1b[0m", "\x1b[33mPending\x1b[0m"} {
+			if !strings.Contains(colored, want) {
+				t.Errorf("width %d: colored output does not contain %q:\n%s", width, want, colored)
+			}
+		}
+		// Cells are padded after coloring, so the columns line up as in
+		// the plain table.
+		if got := ansiCodes.ReplaceAllString(colored, ""); got != plain {
+			t.Errorf("width %d: colored output without escape codes =\n%s\nwant\n%s", width, got, plain)
+		}
+	}
+}
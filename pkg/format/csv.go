@@ -0,0 +1,104 @@
+package format
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// CSVFormatter writes the nodes and the edges of the graph as two CSV
+// tables for spreadsheets. Nodes have one detail.<key> and label.<key>
+// column per key found in the graph. On a single stream the edges table
+// follows the nodes table after a blank line; FormatDir writes them to
+// nodes.csv and edges.csv instead.
+type CSVFormatter struct {
+	opts Options
+}
+
+func NewCSVFormatter(opts Options) *CSVFormatter {
+	return &CSVFormatter{opts: opts}
+}
+
+func (cf *CSVFormatter) Format(w io.Writer, g *Graph) error {
+	if err := cf.writeNodes(w, g); err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintln(w); err != nil {
+		return err
+	}
+	return cf.writeEdges(w, g)
+}
+
+// FormatDir writes nodes.csv and edges.csv to dir, creating it if needed.
+func (cf *CSVFormatter) FormatDir(dir string, g *Graph) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	files := []struct {
+		name  string
+		write func(io.Writer, *Graph) error
+	}{
+		{"nodes.csv", cf.writeNodes},
+		{"edges.csv", cf.writeEdges},
+	}
+	for _, file := range files {
+		f, err := os.Create(filepath.Join(dir, file.name))
+		if err != nil {
+			return err
+		}
+		if err := file.write(f, g); err != nil {
+			f.Close()
+			return err
+		}
+		if err := f.Close(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var csvNodeColumns = []string{"id", "kind", "namespace", "name", "status", "age", "creationTimestamp"}
+
+func (cf *CSVFormatter) writeNodes(w io.Writer, g *Graph) error {
+	now := cf.opts.now(g)
+	nodes := g.Nodes()
+	var extra []string
+	for _, attr := range nodeAttributes(nodes)[len(baseNodeAttributes):] {
+		extra = append(extra, attr.Name)
+	}
+
+	cw := csv.NewWriter(w)
+	cw.Write(append(append([]string(nil), csvNodeColumns...), extra...))
+	for _, res := range nodes {
+		kind := res.Kind
+		if kind == "" {
+			kind = string(res.Type)
+		}
+		// Both cells are left empty when the creation time is not known.
+		record := []string{res.ID(), kind, res.Namespace, res.Name, res.Status, "", ""}
+		if !res.CreationTimestamp.IsZero() {
+			record[5] = FormatAge(res.Age(now))
+			record[6] = res.CreationTimestamp.UTC().Format(time.RFC3339)
+		}
+		values := nodeAttributeValues(res)
+		for _, name := range extra {
+			record = append(record, values[name])
+		}
+		cw.Write(record)
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func (cf *CSVFormatter) writeEdges(w io.Writer, g *Graph) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"from", "to", "type", "evidence"})
+	for _, e := range g.Edges {
+		cw.Write([]string{e.From.ID(), e.To.ID(), string(e.Type), e.Evidence})
+	}
+	cw.Flush()
+	return cw.Error()
+}
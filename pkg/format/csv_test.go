@@ -0,0 +1,91 @@
+package format
+
+import (
+	"bytes"
+	"encoding/csv"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var (
+	wantCSVNodes = [][]string{
+		{"id", "kind", "namespace", "name", "status", "age", "creationTimestamp",
+			"detail.capacity", "detail.files", "detail.node", "detail.restarts", "label.app", "label.fluid.io/dataset"},
+		{"Dataset/default/imagenet", "Dataset", "default", "imagenet", "Bound", "2d4h", "2024-05-01T08:00:00Z",
+			"", "1200", "", "", "", ""},
+		{"Pod/default/web-0", "Pod", "default", "web-0", "Running", "2d3h", "2024-05-01T09:00:00Z",
+			"", "", "node-a", "3", "web", "imagenet"},
+		{"PersistentVolumeClaim/default/data", "PersistentVolumeClaim", "default", "data", "Bound", "", "",
+			"10Gi", "", "", "", "", ""},
+		{"PersistentVolume/pv-data", "PersistentVolume", "", "pv-data", "Bound", "", "",
+			"10", "", "", "", "", ""},
+	}
+	wantCSVEdges = [][]string{
+		{"from", "to", "type", "evidence"},
+		{"Dataset/default/imagenet", "Pod/default/web-0", "labelSelector", "fluid.io/dataset=imagenet"},
+		{"Pod/default/web-0", "PersistentVolumeClaim/default/data", "volumeMount", "spec.volumes[0].persistentVolumeClaim.claimName"},
+		{"PersistentVolumeClaim/default/data", "PersistentVolume/pv-data", "volumeBinding", ""},
+	}
+)
+
+func readCSV(t *testing.T, text string) [][]string {
+	t.Helper()
+	records, err := csv.NewReader(strings.NewReader(text)).ReadAll()
+	if err != nil {
+		t.Fatalf("invalid CSV: %v\n%s", err, text)
+	}
+	return records
+}
+
+func TestCSV(t *testing.T) {
+	var buf bytes.Buffer
+	if err := NewCSVFormatter(Options{}).Format(&buf, exportGraph()); err != nil {
+		t.Fatal(err)
+	}
+	nodes, edges, ok := strings.Cut(buf.String(), "\n\n")
+	if !ok {
+		t.Fatalf("no blank line between the tables:\n%s", buf.String())
+	}
+	if got := readCSV(t, nodes); !reflect.DeepEqual(got, wantCSVNodes) {
+		t.Errorf("nodes = %q, want %q", got, wantCSVNodes)
+	}
+	if got := readCSV(t, edges); !reflect.DeepEqual(got, wantCSVEdges) {
+		t.Errorf("edges = %q, want %q", got, wantCSVEdges)
+	}
+}
+
+func TestCSVDir(t *testing.T) {
+	// FormatDir creates missing directories.
+	dir := filepath.Join(t.TempDir(), "report", "csv")
+	if err := NewCSVFormatter(Options{}).FormatDir(dir, exportGraph()); err != nil {
+		t.Fatal(err)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	if want := []string{"edges.csv", "nodes.csv"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("files = %v, want %v", names, want)
+	}
+	for name, want := range map[string][][]string{"nodes.csv": wantCSVNodes, "edges.csv": wantCSVEdges} {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := readCSV(t, string(data)); !reflect.DeepEqual(got, want) {
+			t.Errorf("%s = %q, want %q", name, got, want)
+		}
+	}
+
+	var f Formatter = NewCSVFormatter(Options{})
+	if _, ok := f.(DirFormatter); !ok {
+		t.Error("the csv formatter does not implement DirFormatter")
+	}
+}
@@ -0,0 +1,145 @@
+package format
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// CypherFormatter emits idempotent Cypher statements that merge the graph
+// into Neo4j. Nodes carry the fixed label Resource, keyed on graphId, the
+// cluster name joined with Resource.ID, plus a label for their ResourceType,
+// so snapshots of many datasets, namespaces and clusters can be loaded into
+// the same database repeatedly. Properties are replaced as a whole on every
+// load, so details and labels that disappeared from the cluster disappear
+// from the database too. Relationships are merged on their type and
+// evidence, so that a pod referring to a ConfigMap from two fields gets
+// two of them.
+type CypherFormatter struct{}
+
+// cypherLabel is the label every node is merged on. The type label cannot
+// change for a graphId, as Resource.ID includes the type.
+const cypherLabel = "Resource"
+
+func NewCypherFormatter() *CypherFormatter {
+	return &CypherFormatter{}
+}
+
+func (cf *CypherFormatter) Format(out io.Writer, g *Graph) error {
+	w := bufio.NewWriter(out)
+	nodes := g.Nodes()
+	cluster := g.Metadata.Cluster
+
+	if len(nodes) > 0 {
+		fmt.Fprintf(w, "CREATE CONSTRAINT IF NOT EXISTS FOR (n:%s) REQUIRE n.graphId IS UNIQUE;\n\n", cypherLabel)
+	}
+
+	for _, res := range nodes {
+		id := cypherString(graphID(cluster, res))
+		fmt.Fprintf(w, "MERGE (n:%s {graphId: %s})\n", cypherLabel, id)
+		kind := res.Kind
+		if kind == "" {
+			kind = string(res.Type)
+		}
+		props := [][2]string{
+			{"graphId", id},
+			{"id", cypherString(res.ID())},
+			{"cluster", cypherString(cluster)},
+			{"kind", cypherString(kind)},
+			{"name", cypherString(res.Name)},
+			{"namespace", cypherString(res.Namespace)},
+			{"status", cypherString(res.Status)},
+		}
+		if !res.CreationTimestamp.IsZero() {
+			props = append(props, [2]string{"creationTimestamp", cypherDateTime(res.CreationTimestamp)})
+		}
+		if res.DeletionTimestamp != nil {
+			props = append(props, [2]string{"deletionTimestamp", cypherDateTime(*res.DeletionTimestamp)})
+		}
+		if !g.Metadata.CollectedAt.IsZero() {
+			props = append(props, [2]string{"collectedAt", cypherDateTime(g.Metadata.CollectedAt)})
+		}
+		for _, d := range res.Details {
+			value := cypherString(d.String())
+			if v, ok := d.Int(); ok {
+				value = fmt.Sprintf("%d", v)
+			}
+			props = append(props, [2]string{"detail." + d.Key, value})
+		}
+		labelKeys := make([]string, 0, len(res.Labels))
+		for k := range res.Labels {
+			labelKeys = append(labelKeys, k)
+		}
+		sort.Strings(labelKeys)
+		for _, k := range labelKeys {
+			props = append(props, [2]string{"label." + k, cypherString(res.Labels[k])})
+		}
+		fmt.Fprintf(w, "SET n:%s, n = ", cypherName(string(res.Type)))
+		writeCypherMap(w, props)
+	}
+	if len(nodes) > 0 && len(g.Edges) > 0 {
+		fmt.Fprintln(w)
+	}
+
+	for _, e := range g.Edges {
+		fmt.Fprintf(w, "MATCH (a:%s {graphId: %s}), (b:%s {graphId: %s})\n",
+			cypherLabel, cypherString(graphID(cluster, e.From)),
+			cypherLabel, cypherString(graphID(cluster, e.To)))
+		fmt.Fprintf(w, "MERGE (a)-[r:%s {evidence: %s}]->(b);\n", relationshipType(e.Type), cypherString(e.Evidence))
+	}
+	return w.Flush()
+}
+
+// writeCypherMap writes a map literal of already encoded values, one
+// property per line, and ends the statement.
+func writeCypherMap(w io.Writer, props [][2]string) {
+	fmt.Fprintln(w, "{")
+	for i, p := range props {
+		sep := ","
+		if i == len(props)-1 {
+			sep = ""
+		}
+		fmt.Fprintf(w, "    %s: %s%s\n", cypherName(p[0]), p[1], sep)
+	}
+	fmt.Fprintln(w, "};")
+}
+
+func graphID(cluster string, res *Resource) string {
+	return cluster + "|" + res.ID()
+}
+
+// relationshipType converts an EdgeType such as labelSelector to the Cypher
+// convention LABEL_SELECTOR.
+func relationshipType(t EdgeType) string {
+	var b strings.Builder
+	for i, r := range string(t) {
+		if unicode.IsUpper(r) && i > 0 {
+			b.WriteByte('_')
+		}
+		b.WriteRune(unicode.ToUpper(r))
+	}
+	return cypherName(b.String())
+}
+
+// cypherName quotes an identifier with backticks unless it is a plain word.
+func cypherName(name string) string {
+	for i, r := range name {
+		if !(unicode.IsLetter(r) || r == '_' || i > 0 && unicode.IsDigit(r)) {
+			return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+		}
+	}
+	return name
+}
+
+func cypherString(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `'`, `\'`, "\n", `\n`, "\r", `\r`)
+	return "'" + r.Replace(s) + "'"
+}
+
+func cypherDateTime(t time.Time) string {
+	return fmt.Sprintf("datetime('%s')", t.UTC().Format(time.RFC3339))
+}
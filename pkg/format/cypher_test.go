@@ -0,0 +1,73 @@
+package format
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestCypher(t *testing.T) {
+	root := &Resource{Type: ResourceTypeDataset, Name: "imagenet", Namespace: "default", Status: "Bound",
+		Labels: map[string]string{"team": "vision"}}
+	runtime := &Resource{Type: ResourceTypeRuntime, Kind: "AlluxioRuntime", APIGroup: "data.fluid.io",
+		Name: "imagenet", Namespace: "default", Status: "Ready"}
+	pod := &Resource{Type: ResourceTypePod, Name: "web-0", Namespace: "default", Status: "Running",
+		Details: []Detail{IntDetail("restarts", 3, "", DetailPriorityPrimary)}}
+	cm := &Resource{Type: ResourceTypeConfigMap, Name: "settings", Namespace: "default", Status: "Active"}
+	g := NewGraph(root)
+	g.Metadata.Cluster = "prod"
+	g.AddResource(runtime)
+	g.AddResource(pod)
+	g.AddResource(cm)
+	g.AddEdge(root, pod, EdgeTypeLabelSelector, "fluid.io/dataset=imagenet")
+	g.AddEdge(pod, cm, EdgeTypeConfigRef, "spec.volumes[1].configMap.name")
+	g.AddEdge(pod, cm, EdgeTypeConfigRef, "spec.containers[0].envFrom[0].configMapRef.name")
+
+	var buf bytes.Buffer
+	if err := NewCypherFormatter().Format(&buf, g); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, s := range []string{
+		"CREATE CONSTRAINT IF NOT EXISTS FOR (n:Resource) REQUIRE n.graphId IS UNIQUE;\n",
+		// Properties are replaced as a whole, graphId included, so reloads
+		// drop stale ones.
+		"MERGE (n:Resource {graphId: 'prod|Pod/default/web-0'})\nSET n:Pod, n = {\n    graphId: 'prod|Pod/default/web-0',\n",
+		// The Kubernetes kind, or the type when it is not known.
+		"    cluster: 'prod',\n    kind: 'AlluxioRuntime',\n    name: 'imagenet',\n",
+		"    cluster: 'prod',\n    kind: 'Pod',\n    name: 'web-0',\n",
+		"    `detail.restarts`: 3\n};\n",
+		"    `label.team`: 'vision'\n};\n",
+		"MATCH (a:Resource {graphId: 'prod|Dataset/default/imagenet'}), (b:Resource {graphId: 'prod|Pod/default/web-0'})\n" +
+			"MERGE (a)-[r:LABEL_SELECTOR {evidence: 'fluid.io/dataset=imagenet'}]->(b);\n",
+		// Parallel edges of one type are told apart by their evidence.
+		"MERGE (a)-[r:CONFIG_REF {evidence: 'spec.volumes[1].configMap.name'}]->(b);\n",
+		"MERGE (a)-[r:CONFIG_REF {evidence: 'spec.containers[0].envFrom[0].configMapRef.name'}]->(b);\n",
+	} {
+		if !strings.Contains(out, s) {
+			t.Errorf("output does not contain %q:\n%s", s, out)
+		}
+	}
+	if strings.Contains(out, "SET n.") || strings.Contains(out, "SET r.") {
+		t.Errorf("output sets single properties, leaving stale ones behind:\n%s", out)
+	}
+}
+
+func TestCypherNames(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"name", "name"},
+		{"detail.restarts", "`detail.restarts`"},
+		{"label.app`x", "`label.app``x`"},
+		{"9lives", "`9lives`"},
+	}
+	for _, tt := range tests {
+		if got := cypherName(tt.in); got != tt.want {
+			t.Errorf("cypherName(%q) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+	if got := relationshipType(EdgeTypeLabelSelector); got != "LABEL_SELECTOR" {
+		t.Errorf("relationshipType = %s, want LABEL_SELECTOR", got)
+	}
+}
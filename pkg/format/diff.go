@@ -0,0 +1,98 @@
+package format
+
+import (
+	"reflect"
+	"time"
+)
+
+// ChangeOp is what happened to a node or an edge between two graphs.
+type ChangeOp string
+
+const (
+	ChangeAdd    ChangeOp = "add"
+	ChangeUpdate ChangeOp = "update"
+	ChangeDelete ChangeOp = "delete"
+)
+
+// Change is one difference between two graphs, and one line of the JSON
+// Lines stream of inspect --watch. Exactly one of Node and Edge is set;
+// deletions carry the node or edge as it was last seen.
+type Change struct {
+	Op   ChangeOp      `json:"op"`
+	Time time.Time     `json:"time"`
+	Node *Node         `json:"node,omitempty"`
+	Edge *Relationship `json:"edge,omitempty"`
+}
+
+// Diff returns the changes that turn prev into next: node changes in the
+// order of next's nodes followed by deletions, then edge changes likewise.
+// A nil prev diffs against an empty graph, so every node and edge is added.
+// Nodes are matched by ID and compared in their serialized form, edges by
+// their endpoints, type and evidence, since a pod can refer to one
+// ConfigMap from several fields.
+func Diff(prev, next *Graph) []Change {
+	at := next.Metadata.CollectedAt
+	var changes []Change
+
+	prevNodes := make(map[string]Node)
+	var prevOrder []string
+	if prev != nil {
+		for _, res := range prev.Nodes() {
+			prevNodes[res.ID()] = newNode(res)
+			prevOrder = append(prevOrder, res.ID())
+		}
+	}
+	seen := make(map[string]bool)
+	for _, res := range next.Nodes() {
+		node := newNode(res)
+		seen[node.ID] = true
+		old, ok := prevNodes[node.ID]
+		switch {
+		case !ok:
+			changes = append(changes, Change{Op: ChangeAdd, Time: at, Node: &node})
+		case !reflect.DeepEqual(old, node):
+			changes = append(changes, Change{Op: ChangeUpdate, Time: at, Node: &node})
+		}
+	}
+	for _, id := range prevOrder {
+		if !seen[id] {
+			node := prevNodes[id]
+			changes = append(changes, Change{Op: ChangeDelete, Time: at, Node: &node})
+		}
+	}
+
+	type edgeKey struct {
+		from, to string
+		edgeType EdgeType
+		evidence string
+	}
+	prevEdges := make(map[edgeKey]Relationship)
+	var prevEdgeOrder []edgeKey
+	if prev != nil {
+		for _, rel := range NewDocument(prev).Edges {
+			key := edgeKey{rel.From, rel.To, rel.Type, rel.Evidence}
+			prevEdges[key] = rel
+			prevEdgeOrder = append(prevEdgeOrder, key)
+		}
+	}
+	seenEdges := make(map[edgeKey]bool)
+	for _, rel := range NewDocument(next).Edges {
+		rel := rel
+		key := edgeKey{rel.From, rel.To, rel.Type, rel.Evidence}
+		seenEdges[key] = true
+		old, ok := prevEdges[key]
+		switch {
+		case !ok:
+			changes = append(changes, Change{Op: ChangeAdd, Time: at, Edge: &rel})
+		case old != rel:
+			changes = append(changes, Change{Op: ChangeUpdate, Time: at, Edge: &rel})
+		}
+	}
+	for _, key := range prevEdgeOrder {
+		if !seenEdges[key] {
+			rel := prevEdges[key]
+			changes = append(changes, Change{Op: ChangeDelete, Time: at, Edge: &rel})
+		}
+	}
+	return changes
+}
@@ -0,0 +1,167 @@
+package format
+
+import (
+	"encoding/json"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// diffGraph is a dataset with a pod mounting a claim. Each call returns
+// new resources, which the tests then change.
+func diffGraph() (*Graph, *Resource, *Resource) {
+	root := &Resource{Type: ResourceTypeDataset, Name: "imagenet", Namespace: "default", Status: "Bound"}
+	pod := &Resource{Type: ResourceTypePod, Name: "web-0", Namespace: "default", Status: "Running",
+		Details: []Detail{IntDetail("restarts", 0, "", DetailPriorityPrimary)}}
+	pvc := &Resource{Type: ResourceTypePVC, Name: "data", Namespace: "default", Status: "Bound"}
+	g := NewGraph(root)
+	g.Metadata.CollectedAt = time.Date(2024, 5, 3, 12, 0, 0, 0, time.UTC)
+	g.AddResource(pod)
+	g.AddResource(pvc)
+	g.AddEdge(root, pod, EdgeTypeLabelSelector, "fluid.io/dataset=imagenet")
+	g.AddEdge(pod, pvc, EdgeTypeVolumeMount, "spec.volumes[0].persistentVolumeClaim.claimName")
+	return g, pod, pvc
+}
+
+// changeStrings returns the changes as "op node ID" or "op from -type-> to".
+func changeStrings(changes []Change) []string {
+	out := make([]string, 0, len(changes))
+	for _, c := range changes {
+		if c.Node != nil {
+			out = append(out, string(c.Op)+" "+c.Node.ID)
+		} else {
+			out = append(out, string(c.Op)+" "+c.Edge.From+" -"+string(c.Edge.Type)+"-> "+c.Edge.To)
+		}
+	}
+	return out
+}
+
+func TestDiff(t *testing.T) {
+	const (
+		dataset = "Dataset/default/imagenet"
+		pod     = "Pod/default/web-0"
+		pvc     = "PersistentVolumeClaim/default/data"
+	)
+	tests := []struct {
+		name   string
+		change func(g *Graph, pod, pvc *Resource)
+		want   []string
+	}{
+		{
+			name:   "unchanged",
+			change: func(*Graph, *Resource, *Resource) {},
+			want:   []string{},
+		},
+		{
+			name:   "status",
+			change: func(_ *Graph, pod, _ *Resource) { pod.Status = "Failed" },
+			want:   []string{"update " + pod},
+		},
+		{
+			name: "detail",
+			change: func(_ *Graph, pod, _ *Resource) {
+				pod.Details = []Detail{IntDetail("restarts", 3, "", DetailPriorityPrimary)}
+			},
+			want: []string{"update " + pod},
+		},
+		{
+			name: "added node and edge",
+			change: func(g *Graph, pod, _ *Resource) {
+				cm := &Resource{Type: ResourceTypeConfigMap, Name: "settings", Namespace: "default", Status: "Active"}
+				g.AddResource(cm)
+				g.AddEdge(pod, cm, EdgeTypeConfigRef, "spec.volumes[1].configMap.name")
+			},
+			want: []string{"add ConfigMap/default/settings", "add " + pod + " -configRef-> ConfigMap/default/settings"},
+		},
+		{
+			name: "removed node and edge",
+			change: func(g *Graph, _, _ *Resource) {
+				delete(g.Resources, ResourceTypePVC)
+				g.Edges = g.Edges[:1]
+			},
+			want: []string{"delete " + pvc, "delete " + pod + " -volumeMount-> " + pvc},
+		},
+		{
+			name: "planned edge",
+			change: func(g *Graph, _, _ *Resource) {
+				g.Edges[1].Planned = true
+			},
+			want: []string{"update " + pod + " -volumeMount-> " + pvc},
+		},
+		{
+			name: "second reference",
+			change: func(g *Graph, pod, pvc *Resource) {
+				g.AddEdge(pod, pvc, EdgeTypeVolumeMount, "spec.volumes[1].persistentVolumeClaim.claimName")
+			},
+			want: []string{"add " + pod + " -volumeMount-> " + pvc},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prev, _, _ := diffGraph()
+			next, pod, pvc := diffGraph()
+			next.Metadata.CollectedAt = prev.Metadata.CollectedAt.Add(time.Minute)
+			tt.change(next, pod, pvc)
+			changes := Diff(prev, next)
+			if got := changeStrings(changes); !slices.Equal(got, tt.want) {
+				t.Errorf("Diff = %v, want %v", got, tt.want)
+			}
+			for _, c := range changes {
+				if !c.Time.Equal(next.Metadata.CollectedAt) {
+					t.Errorf("change time = %v, want the collection time %v", c.Time, next.Metadata.CollectedAt)
+				}
+			}
+		})
+	}
+}
+
+func TestDiffFromNothing(t *testing.T) {
+	g, _, _ := diffGraph()
+	want := []string{
+		"add Dataset/default/imagenet",
+		"add Pod/default/web-0",
+		"add PersistentVolumeClaim/default/data",
+		"add Dataset/default/imagenet -labelSelector-> Pod/default/web-0",
+		"add Pod/default/web-0 -volumeMount-> PersistentVolumeClaim/default/data",
+	}
+	if got := changeStrings(Diff(nil, g)); !slices.Equal(got, want) {
+		t.Errorf("Diff = %v, want %v", got, want)
+	}
+	want = []string{
+		"delete Dataset/default/imagenet",
+		"delete Pod/default/web-0",
+		"delete PersistentVolumeClaim/default/data",
+		"delete Dataset/default/imagenet -labelSelector-> Pod/default/web-0",
+		"delete Pod/default/web-0 -volumeMount-> PersistentVolumeClaim/default/data",
+	}
+	if got := changeStrings(Diff(g, NewGraph(nil))); !slices.Equal(got, want) {
+		t.Errorf("Diff to an empty graph = %v, want %v", got, want)
+	}
+}
+
+func TestChangeJSON(t *testing.T) {
+	prev, _, _ := diffGraph()
+	next, pod, _ := diffGraph()
+	pod.Status = "Failed"
+	next.Metadata.CollectedAt = prev.Metadata.CollectedAt
+	changes := Diff(prev, next)
+	if len(changes) != 1 {
+		t.Fatalf("%d changes, want 1", len(changes))
+	}
+	data, err := json.Marshal(changes[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{
+		`{"op":"update","time":"2024-05-03T12:00:00Z","node":{"id":"Pod/default/web-0",`,
+		`"status":"Failed"`,
+	} {
+		if !strings.Contains(string(data), s) {
+			t.Errorf("%s does not contain %s", data, s)
+		}
+	}
+	if strings.Contains(string(data), `"edge"`) {
+		t.Errorf("%s has an edge", data)
+	}
+}
@@ -0,0 +1,273 @@
+package format
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// DocumentAPIVersion is the version of the serialized graph schema. It
+	// changes whenever a field is removed or changes meaning.
+	DocumentAPIVersion = "kubectl-graph.io/v1alpha1"
+	// DocumentKind is the kind of a serialized graph.
+	DocumentKind = "ResourceGraph"
+)
+
+// Document is the stable, serialized form of a Graph. It is what the json
+// output writes and what commands reading a saved graph accept.
+type Document struct {
+	APIVersion string           `json:"apiVersion" description:"Schema version, always kubectl-graph.io/v1alpha1."`
+	Kind       string           `json:"kind" description:"Document kind, always ResourceGraph."`
+	Metadata   DocumentMetadata `json:"metadata" description:"Where and when the graph was collected."`
+	Root       string           `json:"root,omitempty" description:"ID of the node the graph was collected from, unless a query left it out."`
+	Nodes      []Node           `json:"nodes" description:"Every resource in the graph, the root first."`
+	Edges      []Relationship   `json:"edges" description:"Directed relationships between nodes."`
+}
+
+// DocumentMetadata describes where and when a graph was collected.
+type DocumentMetadata struct {
+	Cluster     string    `json:"cluster,omitempty" description:"Name of the kubeconfig cluster the graph was read from."`
+	Context     string    `json:"context,omitempty" description:"Name of the kubeconfig context the graph was read with."`
+	CollectedAt time.Time `json:"collectedAt" description:"RFC 3339 time the graph was collected."`
+	ToolVersion string    `json:"toolVersion,omitempty" description:"Version of kubectl-graph that collected the graph."`
+}
+
+// Node is a serialized Resource.
+type Node struct {
+	ID                string            `json:"id" description:"Identifier of the form Type/namespace/name, or Type/name for cluster-scoped resources."`
+	Type              ResourceType      `json:"type" description:"Resource type, such as Dataset, Runtime or Pod."`
+	Kind              string            `json:"kind,omitempty" description:"Kubernetes kind, such as AlluxioRuntime."`
+	APIGroup          string            `json:"apiGroup,omitempty" description:"Kubernetes API group, empty for the core group."`
+	Name              string            `json:"name" description:"Object name."`
+	Namespace         string            `json:"namespace,omitempty" description:"Object namespace, empty for cluster-scoped resources."`
+	Status            string            `json:"status" description:"Phase or summarized status."`
+	CreationTimestamp *time.Time        `json:"creationTimestamp,omitempty" description:"RFC 3339 creation time of the object, unset when unknown, as for objects read from manifests."`
+	DeletionTimestamp *time.Time        `json:"deletionTimestamp,omitempty" description:"RFC 3339 time deletion was requested, if any."`
+	Details           []NodeDetail      `json:"details" description:"Typed attributes in display order."`
+	Labels            map[string]string `json:"labels,omitempty" description:"Object labels."`
+	Conditions        []NodeCondition   `json:"conditions" description:"Status conditions reported by the object."`
+	Planned           bool              `json:"planned,omitempty" description:"Set when the resource does not exist yet and controllers will create it, in graphs built from manifests."`
+}
+
+// NodeDetail is a serialized Detail.
+type NodeDetail struct {
+	Key      string      `json:"key" description:"Detail name."`
+	Value    interface{} `json:"value" description:"Detail value, a string or an integer."`
+	Unit     string      `json:"unit,omitempty" description:"Unit of an integer value."`
+	Priority int         `json:"priority" description:"Display priority, 0 for details shown in the default table."`
+}
+
+// NodeCondition is a serialized Condition.
+type NodeCondition struct {
+	Type               string     `json:"type" description:"Condition type."`
+	Status             string     `json:"status" description:"True, False or Unknown."`
+	Reason             string     `json:"reason,omitempty" description:"Machine-readable reason for the last transition."`
+	Message            string     `json:"message,omitempty" description:"Human-readable details about the last transition."`
+	LastTransitionTime *time.Time `json:"lastTransitionTime,omitempty" description:"RFC 3339 time of the last status change."`
+}
+
+// Relationship is a serialized Edge.
+type Relationship struct {
+	From     string   `json:"from" description:"ID of the source node."`
+	To       string   `json:"to" description:"ID of the target node."`
+	Type     EdgeType `json:"type" description:"Relationship type, such as ownerReference or labelSelector."`
+	Evidence string   `json:"evidence,omitempty" description:"What the relationship was inferred from, such as a selector or field path."`
+	Planned  bool     `json:"planned,omitempty" description:"Set when the relationship only exists at runtime because one of its nodes is planned."`
+}
+
+// NewDocument converts g to its serialized form.
+func NewDocument(g *Graph) *Document {
+	doc := &Document{
+		APIVersion: DocumentAPIVersion,
+		Kind:       DocumentKind,
+		Metadata: DocumentMetadata{
+			Cluster:     g.Metadata.Cluster,
+			Context:     g.Metadata.Context,
+			CollectedAt: g.Metadata.CollectedAt,
+			ToolVersion: g.Metadata.ToolVersion,
+		},
+		Nodes: make([]Node, 0),
+		Edges: make([]Relationship, 0, len(g.Edges)),
+	}
+	if g.Root != nil {
+		doc.Root = g.Root.ID()
+	}
+	for _, res := range g.Nodes() {
+		doc.Nodes = append(doc.Nodes, newNode(res))
+	}
+	for _, edge := range g.Edges {
+		doc.Edges = append(doc.Edges, Relationship{
+			From:     edge.From.ID(),
+			To:       edge.To.ID(),
+			Type:     edge.Type,
+			Evidence: edge.Evidence,
+			Planned:  edge.Planned,
+		})
+	}
+	return doc
+}
+
+func newNode(res *Resource) Node {
+	node := Node{
+		ID:                res.ID(),
+		Type:              res.Type,
+		Kind:              res.Kind,
+		APIGroup:          res.APIGroup,
+		Name:              res.Name,
+		Namespace:         res.Namespace,
+		Status:            res.Status,
+		DeletionTimestamp: res.DeletionTimestamp,
+		Details:           make([]NodeDetail, 0, len(res.Details)),
+		Labels:            res.Labels,
+		Conditions:        make([]NodeCondition, 0, len(res.Conditions)),
+		Planned:           res.Planned,
+	}
+	if !res.CreationTimestamp.IsZero() {
+		t := res.CreationTimestamp
+		node.CreationTimestamp = &t
+	}
+	for _, d := range res.Details {
+		node.Details = append(node.Details, NodeDetail{
+			Key:      d.Key,
+			Value:    d.Value,
+			Unit:     d.Unit,
+			Priority: int(d.Priority),
+		})
+	}
+	for _, c := range res.Conditions {
+		cond := NodeCondition{
+			Type:    c.Type,
+			Status:  c.Status,
+			Reason:  c.Reason,
+			Message: c.Message,
+		}
+		if !c.LastTransitionTime.IsZero() {
+			t := c.LastTransitionTime
+			cond.LastTransitionTime = &t
+		}
+		node.Conditions = append(node.Conditions, cond)
+	}
+	return node
+}
+
+// Graph rebuilds the in-memory graph described by the document.
+func (d *Document) Graph() (*Graph, error) {
+	byID := make(map[string]*Resource, len(d.Nodes))
+	for _, node := range d.Nodes {
+		if _, ok := byID[node.ID]; ok {
+			return nil, fmt.Errorf("duplicate node %q", node.ID)
+		}
+		byID[node.ID] = node.resource()
+	}
+	root, ok := byID[d.Root]
+	if !ok && d.Root != "" {
+		return nil, fmt.Errorf("root %q is not a node", d.Root)
+	}
+
+	g := NewGraph(root)
+	g.Metadata = Metadata{
+		Cluster:     d.Metadata.Cluster,
+		Context:     d.Metadata.Context,
+		CollectedAt: d.Metadata.CollectedAt,
+		ToolVersion: d.Metadata.ToolVersion,
+	}
+	for _, node := range d.Nodes {
+		if node.ID != d.Root {
+			g.AddResource(byID[node.ID])
+		}
+	}
+	for _, rel := range d.Edges {
+		from, ok := byID[rel.From]
+		if !ok {
+			return nil, fmt.Errorf("edge source %q is not a node", rel.From)
+		}
+		to, ok := byID[rel.To]
+		if !ok {
+			return nil, fmt.Errorf("edge target %q is not a node", rel.To)
+		}
+		g.AddEdge(from, to, rel.Type, rel.Evidence)
+	}
+	return g, nil
+}
+
+func (n Node) resource() *Resource {
+	res := &Resource{
+		Type:              n.Type,
+		Kind:              n.Kind,
+		APIGroup:          n.APIGroup,
+		Name:              n.Name,
+		Namespace:         n.Namespace,
+		Status:            n.Status,
+		DeletionTimestamp: n.DeletionTimestamp,
+		Details:           make([]Detail, 0, len(n.Details)),
+		Labels:            n.Labels,
+		Conditions:        make([]Condition, 0, len(n.Conditions)),
+		Planned:           n.Planned,
+	}
+	if n.CreationTimestamp != nil {
+		res.CreationTimestamp = *n.CreationTimestamp
+	}
+	for _, d := range n.Details {
+		value := d.Value
+		// Integers are the only numbers the schema allows. ReadDocument
+		// decodes them as json.Number, keeping the digits beyond 2^53 that
+		// the float64 of a plain json.Unmarshal loses.
+		switch v := value.(type) {
+		case json.Number:
+			if i, err := v.Int64(); err == nil {
+				value = i
+			} else {
+				value = v.String()
+			}
+		case float64:
+			value = int64(v)
+		}
+		res.Details = append(res.Details, Detail{
+			Key:      d.Key,
+			Value:    value,
+			Unit:     d.Unit,
+			Priority: DetailPriority(d.Priority),
+		})
+	}
+	for _, c := range n.Conditions {
+		cond := Condition{
+			Type:    c.Type,
+			Status:  c.Status,
+			Reason:  c.Reason,
+			Message: c.Message,
+		}
+		if c.LastTransitionTime != nil {
+			cond.LastTransitionTime = *c.LastTransitionTime
+		}
+		res.Conditions = append(res.Conditions, cond)
+	}
+	return res
+}
+
+// ReadDocument reads a serialized graph in JSON or YAML, validating it
+// against the schema before decoding it.
+func ReadDocument(r io.Reader) (*Document, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	data, err = yaml.YAMLToJSON(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid graph document: %w", err)
+	}
+	if err := ValidateDocument(data); err != nil {
+		return nil, err
+	}
+	doc := &Document{}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
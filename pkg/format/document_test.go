@@ -0,0 +1,125 @@
+package format
+
+import (
+	"bytes"
+	"math"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDocumentRoundTrip(t *testing.T) {
+	created := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		created time.Time
+		want    string
+		missing string
+	}{
+		{"with timestamp", created, `"creationTimestamp": "2024-05-01T10:00:00Z"`, ""},
+		{"without timestamp", time.Time{}, "", "creationTimestamp"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := &Resource{Type: ResourceTypeDataset, Name: "imagenet", Namespace: "default", Status: "Bound", CreationTimestamp: tt.created}
+			g := NewGraph(root)
+			g.Metadata.CollectedAt = created.Add(time.Hour)
+
+			var buf bytes.Buffer
+			if err := NewJSONFormatter().Format(&buf, g); err != nil {
+				t.Fatal(err)
+			}
+			out := buf.String()
+			if tt.want != "" && !strings.Contains(out, tt.want) {
+				t.Errorf("document does not contain %s:\n%s", tt.want, out)
+			}
+			if tt.missing != "" && strings.Contains(out, tt.missing) {
+				t.Errorf("document contains %s:\n%s", tt.missing, out)
+			}
+			if err := ValidateDocument(buf.Bytes()); err != nil {
+				t.Fatal(err)
+			}
+
+			doc, err := ReadDocument(&buf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			read, err := doc.Graph()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !read.Root.CreationTimestamp.Equal(tt.created) {
+				t.Errorf("creationTimestamp = %v, want %v", read.Root.CreationTimestamp, tt.created)
+			}
+		})
+	}
+}
+
+func TestSchemaRequired(t *testing.T) {
+	node := Schema().Properties["nodes"].Items
+	for _, field := range []string{"creationTimestamp", "deletionTimestamp", "planned"} {
+		if slices.Contains(node.Required, field) {
+			t.Errorf("node field %s is required", field)
+		}
+	}
+	for _, field := range []string{"id", "type", "name", "status", "details", "conditions"} {
+		if !slices.Contains(node.Required, field) {
+			t.Errorf("node field %s is not required", field)
+		}
+	}
+	if slices.Contains(Schema().Required, "root") {
+		t.Error("root is required, but query results may have none")
+	}
+}
+
+func TestDocumentWithoutRoot(t *testing.T) {
+	pod := &Resource{Type: ResourceTypePod, Name: "web-0", Namespace: "default", Status: "Running"}
+	g := NewGraph(nil)
+	g.AddResource(pod)
+
+	var buf bytes.Buffer
+	if err := NewJSONFormatter().Format(&buf, g); err != nil {
+		t.Fatal(err)
+	}
+	doc, err := ReadDocument(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	read, err := doc.Graph()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if read.Root != nil || len(read.Nodes()) != 1 {
+		t.Errorf("read root %v and %d nodes, want no root and 1 node", read.Root, len(read.Nodes()))
+	}
+}
+
+func TestDocumentLargeIntegers(t *testing.T) {
+	details := []Detail{
+		IntDetail("cached", 1<<53+1, "B", DetailPriorityPrimary),
+		IntDetail("limit", math.MaxInt64, "B", DetailPrioritySecondary),
+		IntDetail("files", -3, "", DetailPrioritySecondary),
+		StringDetail("capacity", "9007199254740993", DetailPriorityPrimary),
+	}
+	for _, f := range []Formatter{NewJSONFormatter(), NewYAMLFormatter()} {
+		root := &Resource{Type: ResourceTypeDataset, Name: "imagenet", Namespace: "default", Status: "Bound", Details: details}
+		var buf bytes.Buffer
+		if err := f.Format(&buf, NewGraph(root)); err != nil {
+			t.Fatal(err)
+		}
+		doc, err := ReadDocument(&buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		read, err := doc.Graph()
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i, want := range details {
+			if got := read.Root.Details[i]; got.Value != want.Value {
+				t.Errorf("%T: detail %s = %v (%T), want %v (%T)", f, want.Key, got.Value, got.Value, want.Value, want.Value)
+			}
+		}
+	}
+}
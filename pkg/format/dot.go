@@ -0,0 +1,59 @@
+package format
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// DotFormatter prints the graph in Graphviz DOT, for 'dot -Tpng' and the
+// many tools that read it.
+type DotFormatter struct{}
+
+func NewDotFormatter() *DotFormatter {
+	return &DotFormatter{}
+}
+
+var dotShapes = map[ResourceType]string{
+	ResourceTypeDataset: "cylinder",
+	ResourceTypeRuntime: "hexagon",
+	ResourceTypePVC:     "note",
+	ResourceTypePV:      "folder",
+	ResourceTypeService: "ellipse",
+	ResourceTypeNode:    "box3d",
+}
+
+func (df *DotFormatter) Format(out io.Writer, g *Graph) error {
+	w := bufio.NewWriter(out)
+	name := "resources"
+	if g.Root != nil {
+		name = g.Root.ID()
+	}
+	fmt.Fprintf(w, "digraph %s {\n", strconv.Quote(name))
+	fmt.Fprintln(w, "  rankdir=LR;")
+	fmt.Fprintln(w, `  node [shape=box, style="rounded,filled", fontname="Helvetica", fontsize=10];`)
+	fmt.Fprintln(w, `  edge [fontname="Helvetica", fontsize=9];`)
+	for _, res := range g.Nodes() {
+		p := statusPaletteFor(res.Status)
+		shape := dotShapes[res.Type]
+		if shape == "" {
+			shape = "box"
+		}
+		fmt.Fprintf(w, "  %s [label=%s, shape=%s, color=%s, fillcolor=%s, tooltip=%s];\n",
+			strconv.Quote(res.ID()), strconv.Quote(fmt.Sprintf("%s\n%s\n%s", res.Type, res.Name, res.Status)),
+			shape, strconv.Quote(p.stroke), strconv.Quote(p.fill), strconv.Quote(res.ID()))
+	}
+	for _, e := range g.Edges {
+		fmt.Fprintf(w, "  %s -> %s [label=%s", strconv.Quote(e.From.ID()), strconv.Quote(e.To.ID()), strconv.Quote(string(e.Type)))
+		if e.Evidence != "" {
+			fmt.Fprintf(w, ", tooltip=%s", strconv.Quote(e.Evidence))
+		}
+		if e.Planned {
+			fmt.Fprint(w, ", style=dashed")
+		}
+		fmt.Fprintln(w, "];")
+	}
+	fmt.Fprintln(w, "}")
+	return w.Flush()
+}
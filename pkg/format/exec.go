@@ -0,0 +1,97 @@
+package format
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ExternalFormatterPrefix is the prefix of the executables on PATH that
+// provide additional output formats: -o foo runs kubectl-graph-format-foo.
+const ExternalFormatterPrefix = "kubectl-graph-format-"
+
+// externalFormatterName restricts external format names so that -o can
+// never select a path.
+var externalFormatterName = regexp.MustCompile(`^[a-z0-9][a-z0-9-]*$`)
+
+// ExecFormatter runs an external formatter. The program reads the graph
+// document as JSON on stdin and writes its rendering to stdout; it gets
+// the -o argument after '=', if any, as its only argument.
+// KUBECTL_GRAPH_COLOR is "true" when it may use colors, and COLUMNS is set
+// when the output is a terminal of known width.
+type ExecFormatter struct {
+	name string
+	path string
+	arg  string
+	opts Options
+}
+
+func NewExecFormatter(name, path, arg string, opts Options) *ExecFormatter {
+	return &ExecFormatter{name: name, path: path, arg: arg, opts: opts}
+}
+
+func (ef *ExecFormatter) Format(w io.Writer, g *Graph) error {
+	data, err := json.Marshal(NewDocument(g))
+	if err != nil {
+		return err
+	}
+	var args []string
+	if ef.arg != "" {
+		args = append(args, ef.arg)
+	}
+	cmd := exec.Command(ef.path, args...)
+	cmd.Stdin = bytes.NewReader(data)
+	cmd.Stdout = w
+	cmd.Stderr = os.Stderr
+	cmd.Env = append(os.Environ(), "KUBECTL_GRAPH_COLOR="+strconv.FormatBool(ef.opts.Color))
+	if ef.opts.Width > 0 {
+		cmd.Env = append(cmd.Env, "COLUMNS="+strconv.Itoa(ef.opts.Width))
+	}
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("external formatter %s failed: %w", ef.path, err)
+	}
+	return nil
+}
+
+// lookExternalFormatter returns the path of the executable providing the
+// named format.
+func lookExternalFormatter(name string) (string, error) {
+	if !externalFormatterName.MatchString(name) {
+		return "", fmt.Errorf("invalid formatter name %q", name)
+	}
+	return exec.LookPath(ExternalFormatterPrefix + name)
+}
+
+// ExternalFormatters returns the names of the external formatters on
+// PATH, sorted.
+func ExternalFormatters() []string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			name, ok := strings.CutPrefix(entry.Name(), ExternalFormatterPrefix)
+			if !ok || seen[name] || !externalFormatterName.MatchString(name) {
+				continue
+			}
+			if _, err := lookExternalFormatter(name); err != nil {
+				continue
+			}
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,108 @@
+package format
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// externalFormatters puts scripts on PATH: kubectl-graph-format-echo prints
+// its argument and environment followed by the document it reads, and
+// kubectl-graph-format-fail exits with an error.
+func externalFormatters(t *testing.T) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("external formatters are shell scripts")
+	}
+	dir := t.TempDir()
+	scripts := map[string]string{
+		"kubectl-graph-format-echo":          "#!/bin/sh\necho \"arg=$1 color=$KUBECTL_GRAPH_COLOR columns=$COLUMNS\"\nexec cat\n",
+		"kubectl-graph-format-fail":          "#!/bin/sh\nexit 3\n",
+		"kubectl-graph-format-Invalid":       "#!/bin/sh\n",
+		"kubectl-graph-format-not-a-program": "",
+	}
+	for name, script := range scripts {
+		mode := os.FileMode(0o755)
+		if script == "" {
+			mode = 0o644
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(script), mode); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+}
+
+func TestExternalFormatters(t *testing.T) {
+	externalFormatters(t)
+	names := ExternalFormatters()
+	for _, name := range []string{"echo", "fail"} {
+		if !slices.Contains(names, name) {
+			t.Errorf("ExternalFormatters = %v, want %s", names, name)
+		}
+	}
+	for _, name := range []string{"Invalid", "not-a-program"} {
+		if slices.Contains(names, name) {
+			t.Errorf("ExternalFormatters = %v, want no %s", names, name)
+		}
+	}
+	if usages := FormatterUsages(); !slices.Contains(usages, "echo[=ARG]") {
+		t.Errorf("FormatterUsages = %v, want echo[=ARG]", usages)
+	}
+}
+
+func TestExecFormatter(t *testing.T) {
+	externalFormatters(t)
+	tests := []struct {
+		spec   string
+		opts   Options
+		header string
+	}{
+		{spec: "echo", header: "arg= color=false columns="},
+		{spec: "echo=a b", opts: Options{Color: true, Width: 90}, header: "arg=a b color=true columns=90"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			t.Setenv("COLUMNS", "")
+			f, err := NewFormatter(tt.spec, tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var buf bytes.Buffer
+			if err := f.Format(&buf, exportGraph()); err != nil {
+				t.Fatal(err)
+			}
+			header, body, _ := strings.Cut(buf.String(), "\n")
+			if header != tt.header {
+				t.Errorf("header = %q, want %q", header, tt.header)
+			}
+			var doc Document
+			if err := json.Unmarshal([]byte(body), &doc); err != nil {
+				t.Fatalf("stdin is not a document: %v\n%s", err, body)
+			}
+			if doc.Kind != DocumentKind || doc.Root != "Dataset/default/imagenet" || len(doc.Nodes) != 4 || len(doc.Edges) != 3 {
+				t.Errorf("document = %+v, want the graph", doc)
+			}
+		})
+	}
+
+	f, err := NewFormatter("fail", Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = f.Format(&bytes.Buffer{}, exportGraph())
+	if err == nil || !strings.Contains(err.Error(), "kubectl-graph-format-fail failed: exit status 3") {
+		t.Errorf("Format = %v, want the exit status", err)
+	}
+
+	for _, spec := range []string{"Invalid", "not-a-program"} {
+		if _, err := NewFormatter(spec, Options{}); err == nil {
+			t.Errorf("NewFormatter(%q) succeeded, want an error", spec)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package format
+
+import (
+	"bytes"
+	"encoding/xml"
+	"testing"
+)
+
+type xmlGEXF struct {
+	Version string `xml:"version,attr"`
+	Meta    struct {
+		LastModified string `xml:"lastmodifieddate,attr"`
+		Creator      string `xml:"creator"`
+		Description  string `xml:"description"`
+	} `xml:"meta"`
+	Graph struct {
+		DefaultEdgeType string `xml:"defaultedgetype,attr"`
+		Attributes      []struct {
+			Class      string `xml:"class,attr"`
+			Attributes []struct {
+				ID    string `xml:"id,attr"`
+				Title string `xml:"title,attr"`
+				Type  string `xml:"type,attr"`
+			} `xml:"attribute"`
+		} `xml:"attributes"`
+		Nodes []struct {
+			ID        string            `xml:"id,attr"`
+			Label     string            `xml:"label,attr"`
+			AttValues []xmlGEXFAttValue `xml:"attvalues>attvalue"`
+		} `xml:"nodes>node"`
+		Edges []struct {
+			Source    string            `xml:"source,attr"`
+			Target    string            `xml:"target,attr"`
+			Label     string            `xml:"label,attr"`
+			AttValues []xmlGEXFAttValue `xml:"attvalues>attvalue"`
+		} `xml:"edges>edge"`
+	} `xml:"graph"`
+}
+
+type xmlGEXFAttValue struct {
+	For   string `xml:"for,attr"`
+	Value string `xml:"value,attr"`
+}
+
+func TestGEXF(t *testing.T) {
+	var buf bytes.Buffer
+	if err := NewGEXFFormatter().Format(&buf, exportGraph()); err != nil {
+		t.Fatal(err)
+	}
+	var doc xmlGEXF
+	if err := xml.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("invalid XML: %v\n%s", err, buf.String())
+	}
+	if doc.Version != "1.3" || doc.Graph.DefaultEdgeType != "directed" {
+		t.Errorf("version %q, defaultedgetype %q, want 1.3 and directed", doc.Version, doc.Graph.DefaultEdgeType)
+	}
+	if doc.Meta.LastModified != "2024-05-03" || doc.Meta.Creator != "kubectl-graph" ||
+		doc.Meta.Description != "Dataset/default/imagenet and its dependencies" {
+		t.Errorf("meta = %+v", doc.Meta)
+	}
+
+	e := exported{types: make(map[string]string), nodes: make(map[string]map[string]string)}
+	// Attribute IDs are per class.
+	names := make(map[string]map[string]string)
+	for _, class := range doc.Graph.Attributes {
+		names[class.Class] = make(map[string]string)
+		for _, attr := range class.Attributes {
+			names[class.Class][attr.ID] = attr.Title
+			e.types[class.Class+":"+attr.Title] = attr.Type
+		}
+	}
+	values := func(class string, attValues []xmlGEXFAttValue) map[string]string {
+		values := make(map[string]string)
+		for _, v := range attValues {
+			name, ok := names[class][v.For]
+			if !ok {
+				t.Errorf("attvalue refers to undeclared %s attribute %s", class, v.For)
+			}
+			values[name] = v.Value
+		}
+		return values
+	}
+	for _, n := range doc.Graph.Nodes {
+		e.nodes[n.ID] = values("node", n.AttValues)
+		if n.Label != e.nodes[n.ID]["name"] {
+			t.Errorf("node %s has label %q, want its name", n.ID, n.Label)
+		}
+	}
+	for _, edge := range doc.Graph.Edges {
+		ee := exportedEdge{edge.Source, edge.Target, values("edge", edge.AttValues)}
+		if edge.Label != ee.values["type"] {
+			t.Errorf("edge %s -> %s has label %q, want its type", edge.Source, edge.Target, edge.Label)
+		}
+		e.edges = append(e.edges, ee)
+	}
+	checkExport(t, e)
+}
@@ -1,6 +1,11 @@
 package format
 
-import "time"
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
 
 type ResourceType string
 
@@ -11,42 +16,307 @@ const (
 	ResourceTypePVC         ResourceType = "PersistentVolumeClaim"
 	ResourceTypePV          ResourceType = "PersistentVolume"
 	ResourceTypeService     ResourceType = "Service"
+	ResourceTypeConfigMap   ResourceType = "ConfigMap"
+	ResourceTypeSecret      ResourceType = "Secret"
+	ResourceTypeNode        ResourceType = "Node"
+	ResourceTypeDeployment  ResourceType = "Deployment"
 	ResourceTypeStatefulSet ResourceType = "StatefulSet"
 	ResourceTypeDaemonSet   ResourceType = "DaemonSet"
+	ResourceTypeJob         ResourceType = "Job"
+)
+
+// StatusNotYetPresent is the status of planned resources, which do not
+// exist yet.
+const StatusNotYetPresent = "NotYetPresent"
+
+// resourceTypeOrder is the order in which resource types are listed when a
+// graph is walked as a flat list of nodes.
+var resourceTypeOrder = []ResourceType{
+	ResourceTypeDataset,
+	ResourceTypeRuntime,
+	ResourceTypeDeployment,
+	ResourceTypeStatefulSet,
+	ResourceTypeDaemonSet,
+	ResourceTypeJob,
+	ResourceTypePod,
+	ResourceTypePVC,
+	ResourceTypePV,
+	ResourceTypeService,
+	ResourceTypeConfigMap,
+	ResourceTypeSecret,
+	ResourceTypeNode,
+}
+
+var resourceTypeAliases = map[string]ResourceType{
+	"dataset":                ResourceTypeDataset,
+	"datasets":               ResourceTypeDataset,
+	"runtime":                ResourceTypeRuntime,
+	"runtimes":               ResourceTypeRuntime,
+	"pod":                    ResourceTypePod,
+	"pods":                   ResourceTypePod,
+	"po":                     ResourceTypePod,
+	"persistentvolumeclaim":  ResourceTypePVC,
+	"persistentvolumeclaims": ResourceTypePVC,
+	"pvc":                    ResourceTypePVC,
+	"pvcs":                   ResourceTypePVC,
+	"persistentvolume":       ResourceTypePV,
+	"persistentvolumes":      ResourceTypePV,
+	"pv":                     ResourceTypePV,
+	"pvs":                    ResourceTypePV,
+	"service":                ResourceTypeService,
+	"services":               ResourceTypeService,
+	"svc":                    ResourceTypeService,
+	"configmap":              ResourceTypeConfigMap,
+	"configmaps":             ResourceTypeConfigMap,
+	"cm":                     ResourceTypeConfigMap,
+	"secret":                 ResourceTypeSecret,
+	"secrets":                ResourceTypeSecret,
+	"node":                   ResourceTypeNode,
+	"nodes":                  ResourceTypeNode,
+	"no":                     ResourceTypeNode,
+	"deployment":             ResourceTypeDeployment,
+	"deployments":            ResourceTypeDeployment,
+	"deploy":                 ResourceTypeDeployment,
+	"statefulset":            ResourceTypeStatefulSet,
+	"statefulsets":           ResourceTypeStatefulSet,
+	"sts":                    ResourceTypeStatefulSet,
+	"daemonset":              ResourceTypeDaemonSet,
+	"daemonsets":             ResourceTypeDaemonSet,
+	"ds":                     ResourceTypeDaemonSet,
+	"job":                    ResourceTypeJob,
+	"jobs":                   ResourceTypeJob,
+}
+
+// ParseResourceType resolves a kind name, plural or kubectl short name such
+// as "pvc" or "svc" to a ResourceType. Matching is case-insensitive.
+func ParseResourceType(s string) (ResourceType, bool) {
+	t, ok := resourceTypeAliases[strings.ToLower(s)]
+	return t, ok
+}
+
+// EdgeType identifies the kind of evidence that links two resources.
+type EdgeType string
+
+const (
+	// EdgeTypeOwnerReference links an owner to an object listing it in metadata.ownerReferences.
+	EdgeTypeOwnerReference EdgeType = "ownerReference"
+	// EdgeTypeLabelSelector links a resource to objects matched by a label selector.
+	EdgeTypeLabelSelector EdgeType = "labelSelector"
+	// EdgeTypeServiceSelector links a Service to the pods matched by spec.selector.
+	EdgeTypeServiceSelector EdgeType = "serviceSelector"
+	// EdgeTypeVolumeMount links a pod to a claim referenced from spec.volumes.
+	EdgeTypeVolumeMount EdgeType = "volumeMount"
+	// EdgeTypeConfigRef links a pod to a ConfigMap or Secret referenced from env, envFrom or volumes.
+	EdgeTypeConfigRef EdgeType = "configRef"
+	// EdgeTypeScheduledOn links a pod to the node named in spec.nodeName.
+	EdgeTypeScheduledOn EdgeType = "scheduledOn"
+	// EdgeTypeVolumeBinding links a claim to the volume named in spec.volumeName.
+	EdgeTypeVolumeBinding EdgeType = "volumeBinding"
+	// EdgeTypeRuntimeBinding links a Fluid dataset to the runtime bound to it by name.
+	EdgeTypeRuntimeBinding EdgeType = "runtimeBinding"
+	// EdgeTypeIngressBackend links an Ingress to a Service named as a backend.
+	EdgeTypeIngressBackend EdgeType = "ingressBackend"
+	// EdgeTypeEndpointTarget links an EndpointSlice to the pod named in an endpoint's targetRef.
+	EdgeTypeEndpointTarget EdgeType = "endpointTarget"
 )
 
 type Resource struct {
-	Type       ResourceType
-	Name       string
-	Namespace  string
-	Status     string
-	Age        time.Duration
-	Details    map[string]interface{}
-	Labels     map[string]string
-	Conditions []Condition
+	Type ResourceType
+	// Kind and APIGroup identify the Kubernetes API type, which is more
+	// specific than Type for runtimes (e.g. AlluxioRuntime). APIGroup is
+	// empty for the core group.
+	Kind      string
+	APIGroup  string
+	Name      string
+	Namespace string
+	Status    string
+	// CreationTimestamp and DeletionTimestamp are copied from the object
+	// metadata. Ages are computed by the formatters so that a saved graph
+	// stays correct.
+	CreationTimestamp time.Time
+	DeletionTimestamp *time.Time
+	Details           []Detail
+	Labels            map[string]string
+	Conditions        []Condition
+	// Planned marks a resource that does not exist yet: controllers will
+	// create it once the manifests the graph was built from are applied.
+	Planned bool
+	// Object is the Kubernetes object the resource was converted from, in
+	// unstructured form. It is only set for resources read from a cluster
+	// and is not part of the serialized Document.
+	Object map[string]interface{}
+}
+
+// DetailPriority orders details for display. Lower values are more
+// important; the table shows only primary details while every priority is
+// kept in the graph.
+type DetailPriority int
+
+const (
+	DetailPriorityPrimary DetailPriority = iota
+	DetailPrioritySecondary
+)
+
+// Detail is a single typed attribute of a resource. Value holds either a
+// string or an int64 so that it keeps its type when serialized.
+type Detail struct {
+	Key      string
+	Value    interface{}
+	Unit     string
+	Priority DetailPriority
+}
+
+// StringDetail returns a detail holding a string value.
+func StringDetail(key, value string, priority DetailPriority) Detail {
+	return Detail{Key: key, Value: value, Priority: priority}
+}
+
+// IntDetail returns a detail holding an integer value with an optional unit.
+func IntDetail(key string, value int64, unit string, priority DetailPriority) Detail {
+	return Detail{Key: key, Value: value, Unit: unit, Priority: priority}
+}
+
+// Int returns the value of an integer detail.
+func (d Detail) Int() (int64, bool) {
+	v, ok := d.Value.(int64)
+	return v, ok
+}
+
+// IsZero reports whether the detail holds an empty string or zero.
+func (d Detail) IsZero() bool {
+	switch v := d.Value.(type) {
+	case string:
+		return v == ""
+	case int64:
+		return v == 0
+	default:
+		return v == nil
+	}
+}
+
+// String renders the value followed by its unit, if any.
+func (d Detail) String() string {
+	if d.Value == nil {
+		return ""
+	}
+	if d.Unit == "" {
+		return fmt.Sprint(d.Value)
+	}
+	return fmt.Sprintf("%v%s", d.Value, d.Unit)
+}
+
+// Detail looks up a detail by key.
+func (r *Resource) Detail(key string) (Detail, bool) {
+	for _, d := range r.Details {
+		if d.Key == key {
+			return d, true
+		}
+	}
+	return Detail{}, false
+}
+
+// DetailString returns the rendered value of a detail, or "" if unset.
+func (r *Resource) DetailString(key string) string {
+	d, _ := r.Detail(key)
+	return d.String()
+}
+
+// ID returns an identifier that is unique within a cluster, of the form
+// Type/namespace/name, or Type/name for cluster-scoped resources.
+func (r *Resource) ID() string {
+	if r.Namespace == "" {
+		return fmt.Sprintf("%s/%s", r.Type, r.Name)
+	}
+	return fmt.Sprintf("%s/%s/%s", r.Type, r.Namespace, r.Name)
+}
+
+// QualifiedName returns the resource as kubectl names it with -o name:
+// kind/name for the core group and kind.group/name otherwise.
+func (r *Resource) QualifiedName() string {
+	kind := r.Kind
+	if kind == "" {
+		kind = string(r.Type)
+	}
+	kind = strings.ToLower(kind)
+	if r.APIGroup != "" {
+		kind += "." + r.APIGroup
+	}
+	return kind + "/" + r.Name
+}
+
+// Age returns how long the resource had existed at now.
+func (r *Resource) Age(now time.Time) time.Duration {
+	if r.CreationTimestamp.IsZero() || now.Before(r.CreationTimestamp) {
+		return 0
+	}
+	return now.Sub(r.CreationTimestamp)
 }
 
 type Condition struct {
-	Type    string
-	Status  string
-	Reason  string
-	Message string
+	Type               string
+	Status             string
+	Reason             string
+	Message            string
+	LastTransitionTime time.Time
+}
+
+// Health groups statuses for coloring and for summaries.
+type Health int
+
+const (
+	HealthUnknown Health = iota
+	HealthOK
+	HealthWarning
+	HealthFailing
+)
+
+// StatusHealth classifies a resource status.
+func StatusHealth(status string) Health {
+	switch status {
+	case "Running", "Bound", "Active", "Ready":
+		return HealthOK
+	case "Pending", "Creating":
+		return HealthWarning
+	case "Failed", "Error", "CrashLoopBackOff":
+		return HealthFailing
+	default:
+		return HealthUnknown
+	}
 }
 
 type Graph struct {
+	Metadata  Metadata
 	Root      *Resource
 	Resources map[ResourceType][]*Resource
 	Edges     []Edge
 }
 
+// Metadata describes where and when a graph was collected.
+type Metadata struct {
+	Cluster string
+	Context string
+	// CollectedAt is when the graph was collected. Formatters measure ages
+	// against it unless told otherwise.
+	CollectedAt time.Time
+	ToolVersion string
+}
+
+// Edge is a directed relationship between two resources. Evidence records
+// what the relationship was inferred from, such as a selector string or the
+// field path holding the reference.
 type Edge struct {
-	From *Resource
-	To   *Resource
-	Type string
+	From     *Resource
+	To       *Resource
+	Type     EdgeType
+	Evidence string
+	// Planned is set when either end is planned, so that the relationship
+	// only exists at runtime.
+	Planned bool
 }
 
 func NewGraph(root *Resource) *Graph {
 	return &Graph{
+		Metadata:  Metadata{CollectedAt: time.Now()},
 		Root:      root,
 		Resources: make(map[ResourceType][]*Resource),
 		Edges:     make([]Edge, 0),
@@ -60,14 +330,84 @@ func (g *Graph) AddResource(resource *Resource) {
 	g.Resources[resource.Type] = append(g.Resources[resource.Type], resource)
 }
 
-func (g *Graph) AddEdge(from, to *Resource, edgeType string) {
+func (g *Graph) AddEdge(from, to *Resource, edgeType EdgeType, evidence string) {
 	g.Edges = append(g.Edges, Edge{
-		From: from,
-		To:   to,
-		Type: edgeType,
+		From:     from,
+		To:       to,
+		Type:     edgeType,
+		Evidence: evidence,
+		Planned:  from.Planned || to.Planned,
 	})
 }
 
+// Workloads returns the Deployments, StatefulSets, DaemonSets and Jobs in
+// the graph.
+func (g *Graph) Workloads() []*Resource {
+	var workloads []*Resource
+	for _, t := range []ResourceType{ResourceTypeDeployment, ResourceTypeStatefulSet, ResourceTypeDaemonSet, ResourceTypeJob} {
+		workloads = append(workloads, g.Resources[t]...)
+	}
+	return workloads
+}
+
+// Nodes returns the root followed by every other resource in the graph,
+// grouped by type in a stable order.
+func (g *Graph) Nodes() []*Resource {
+	nodes := make([]*Resource, 0)
+	if g.Root != nil {
+		nodes = append(nodes, g.Root)
+	}
+	for _, t := range g.resourceTypes() {
+		nodes = append(nodes, g.Resources[t]...)
+	}
+	return nodes
+}
+
+func (g *Graph) resourceTypes() []ResourceType {
+	types := make([]ResourceType, 0, len(g.Resources))
+	known := make(map[ResourceType]bool, len(resourceTypeOrder))
+	for _, t := range resourceTypeOrder {
+		known[t] = true
+		if len(g.Resources[t]) > 0 {
+			types = append(types, t)
+		}
+	}
+	extra := make([]ResourceType, 0)
+	for t := range g.Resources {
+		if !known[t] {
+			extra = append(extra, t)
+		}
+	}
+	sort.Slice(extra, func(i, j int) bool { return extra[i] < extra[j] })
+	return append(types, extra...)
+}
+
+// Subgraph returns a graph holding only the given resources and the edges
+// between them. It keeps the root only if the root is among resources, and
+// has no root otherwise.
+func (g *Graph) Subgraph(resources []*Resource) *Graph {
+	sub := NewGraph(nil)
+	sub.Metadata = g.Metadata
+	keep := make(map[*Resource]bool, len(resources))
+	for _, res := range resources {
+		if keep[res] {
+			continue
+		}
+		keep[res] = true
+		if res == g.Root {
+			sub.Root = res
+			continue
+		}
+		sub.AddResource(res)
+	}
+	for _, edge := range g.Edges {
+		if keep[edge.From] && keep[edge.To] {
+			sub.Edges = append(sub.Edges, edge)
+		}
+	}
+	return sub
+}
+
 func (g *Graph) GetChildren(parent *Resource) []*Resource {
 	children := make([]*Resource, 0)
 	for _, edge := range g.Edges {
@@ -77,3 +417,13 @@ func (g *Graph) GetChildren(parent *Resource) []*Resource {
 	}
 	return children
 }
+
+func (g *Graph) GetParents(child *Resource) []*Resource {
+	parents := make([]*Resource, 0)
+	for _, edge := range g.Edges {
+		if edge.To == child {
+			parents = append(parents, edge.From)
+		}
+	}
+	return parents
+}
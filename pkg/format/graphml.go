@@ -0,0 +1,104 @@
+package format
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+)
+
+// GraphMLFormatter exports the graph as GraphML, with every resource field,
+// detail and label as a typed attribute, for tools such as yEd.
+type GraphMLFormatter struct{}
+
+func NewGraphMLFormatter() *GraphMLFormatter {
+	return &GraphMLFormatter{}
+}
+
+type graphMLDocument struct {
+	XMLName xml.Name     `xml:"graphml"`
+	XMLNS   string       `xml:"xmlns,attr"`
+	Keys    []graphMLKey `xml:"key"`
+	Graph   graphMLGraph `xml:"graph"`
+}
+
+type graphMLKey struct {
+	ID       string `xml:"id,attr"`
+	For      string `xml:"for,attr"`
+	AttrName string `xml:"attr.name,attr"`
+	AttrType string `xml:"attr.type,attr"`
+}
+
+type graphMLGraph struct {
+	ID          string        `xml:"id,attr"`
+	EdgeDefault string        `xml:"edgedefault,attr"`
+	Nodes       []graphMLNode `xml:"node"`
+	Edges       []graphMLEdge `xml:"edge"`
+}
+
+type graphMLNode struct {
+	ID   string        `xml:"id,attr"`
+	Data []graphMLData `xml:"data"`
+}
+
+type graphMLEdge struct {
+	ID     string        `xml:"id,attr"`
+	Source string        `xml:"source,attr"`
+	Target string        `xml:"target,attr"`
+	Data   []graphMLData `xml:"data"`
+}
+
+type graphMLData struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:",chardata"`
+}
+
+func (gf *GraphMLFormatter) Format(w io.Writer, g *Graph) error {
+	nodes := g.Nodes()
+	doc := graphMLDocument{
+		XMLNS: "http://graphml.graphdrawing.org/xmlns",
+		Graph: graphMLGraph{EdgeDefault: "directed"},
+	}
+	if g.Root != nil {
+		doc.Graph.ID = g.Root.ID()
+	}
+
+	nodeAttrs := nodeAttributes(nodes)
+	for i, attr := range nodeAttrs {
+		doc.Keys = append(doc.Keys, graphMLKey{ID: fmt.Sprintf("n%d", i), For: "node", AttrName: attr.Name, AttrType: string(attr.Kind)})
+	}
+	for i, attr := range edgeAttributes {
+		doc.Keys = append(doc.Keys, graphMLKey{ID: fmt.Sprintf("e%d", i), For: "edge", AttrName: attr.Name, AttrType: string(attr.Kind)})
+	}
+
+	for _, res := range nodes {
+		values := nodeAttributeValues(res)
+		node := graphMLNode{ID: res.ID()}
+		for i, attr := range nodeAttrs {
+			if v, ok := values[attr.Name]; ok {
+				node.Data = append(node.Data, graphMLData{Key: fmt.Sprintf("n%d", i), Value: v})
+			}
+		}
+		doc.Graph.Nodes = append(doc.Graph.Nodes, node)
+	}
+	for i, e := range g.Edges {
+		values := edgeAttributeValues(e)
+		edge := graphMLEdge{ID: fmt.Sprintf("edge%d", i), Source: e.From.ID(), Target: e.To.ID()}
+		for j, attr := range edgeAttributes {
+			if v, ok := values[attr.Name]; ok {
+				edge.Data = append(edge.Data, graphMLData{Key: fmt.Sprintf("e%d", j), Value: v})
+			}
+		}
+		doc.Graph.Edges = append(doc.Graph.Edges, edge)
+	}
+
+	return writeXML(w, doc)
+}
+
+func writeXML(w io.Writer, v interface{}) error {
+	data, err := xml.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "%s%s\n", xml.Header, data)
+	return err
+}
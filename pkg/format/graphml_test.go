@@ -0,0 +1,207 @@
+package format
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// exportGraph holds integer, string and mixed details, labels, a
+// cluster-scoped resource and an edge without evidence.
+func exportGraph() *Graph {
+	created := time.Date(2024, 5, 1, 10, 0, 0, 0, time.FixedZone("CEST", 2*60*60))
+	root := &Resource{Type: ResourceTypeDataset, Name: "imagenet", Namespace: "default", Status: "Bound",
+		CreationTimestamp: created,
+		Details:           []Detail{IntDetail("files", 1200, "", DetailPriorityPrimary)}}
+	pod := &Resource{Type: ResourceTypePod, Name: "web-0", Namespace: "default", Status: "Running",
+		CreationTimestamp: created.Add(time.Hour),
+		Labels:            map[string]string{"app": "web", "fluid.io/dataset": "imagenet"},
+		Details: []Detail{
+			IntDetail("restarts", 3, "", DetailPriorityPrimary),
+			StringDetail("node", "node-a", DetailPrioritySecondary),
+		}}
+	pvc := &Resource{Type: ResourceTypePVC, Name: "data", Namespace: "default", Status: "Bound",
+		Details: []Detail{StringDetail("capacity", "10Gi", DetailPriorityPrimary)}}
+	pv := &Resource{Type: ResourceTypePV, Name: "pv-data", Status: "Bound",
+		Details: []Detail{IntDetail("capacity", 10, "Gi", DetailPriorityPrimary)}}
+	g := NewGraph(root)
+	g.Metadata.CollectedAt = time.Date(2024, 5, 3, 12, 0, 0, 0, time.UTC)
+	for _, res := range []*Resource{pod, pvc, pv} {
+		g.AddResource(res)
+	}
+	g.AddEdge(root, pod, EdgeTypeLabelSelector, "fluid.io/dataset=imagenet")
+	g.AddEdge(pod, pvc, EdgeTypeVolumeMount, "spec.volumes[0].persistentVolumeClaim.claimName")
+	g.AddEdge(pvc, pv, EdgeTypeVolumeBinding, "")
+	return g
+}
+
+// exported is a GraphML or GEXF export with attribute IDs resolved to
+// names.
+type exported struct {
+	// types maps "node:<name>" and "edge:<name>" to the declared type.
+	types map[string]string
+	nodes map[string]map[string]string
+	edges []exportedEdge
+}
+
+type exportedEdge struct {
+	source, target string
+	values         map[string]string
+}
+
+// checkExport checks the attributes of exportGraph in e.
+func checkExport(t *testing.T, e exported) {
+	t.Helper()
+	wantTypes := map[string]string{
+		"node:type":                   "string",
+		"node:name":                   "string",
+		"node:namespace":              "string",
+		"node:status":                 "string",
+		"node:creationTimestamp":      "string",
+		"node:deletionTimestamp":      "string",
+		"node:detail.files":           "long",
+		"node:detail.restarts":        "long",
+		"node:detail.node":            "string",
+		"node:detail.capacity":        "string",
+		"node:label.app":              "string",
+		"node:label.fluid.io/dataset": "string",
+		"edge:type":                   "string",
+		"edge:evidence":               "string",
+	}
+	for key, want := range wantTypes {
+		if got := e.types[key]; got != want {
+			t.Errorf("attribute %s has type %q, want %q", key, got, want)
+		}
+	}
+	if len(e.types) != len(wantTypes) {
+		t.Errorf("attributes = %v, want %v", e.types, wantTypes)
+	}
+
+	wantNodes := map[string]map[string]string{
+		"Dataset/default/imagenet": {"type": "Dataset", "name": "imagenet", "namespace": "default", "status": "Bound",
+			"creationTimestamp": "2024-05-01T08:00:00Z", "detail.files": "1200"},
+		"Pod/default/web-0": {"type": "Pod", "name": "web-0", "namespace": "default", "status": "Running",
+			"creationTimestamp": "2024-05-01T09:00:00Z", "detail.restarts": "3", "detail.node": "node-a",
+			"label.app": "web", "label.fluid.io/dataset": "imagenet"},
+		"PersistentVolumeClaim/default/data": {"type": "PersistentVolumeClaim", "name": "data", "namespace": "default",
+			"status": "Bound", "detail.capacity": "10Gi"},
+		// Integers of a string attribute keep their digits only.
+		"PersistentVolume/pv-data": {"type": "PersistentVolume", "name": "pv-data", "status": "Bound", "detail.capacity": "10"},
+	}
+	if len(e.nodes) != len(wantNodes) {
+		t.Errorf("%d nodes, want %d", len(e.nodes), len(wantNodes))
+	}
+	for id, want := range wantNodes {
+		got := e.nodes[id]
+		for name, value := range want {
+			if got[name] != value {
+				t.Errorf("%s %s = %q, want %q", id, name, got[name], value)
+			}
+		}
+		if len(got) != len(want) {
+			t.Errorf("%s has attributes %v, want %v", id, got, want)
+		}
+		for name, value := range got {
+			if e.types["node:"+name] == "long" {
+				if _, err := strconv.ParseInt(value, 10, 64); err != nil {
+					t.Errorf("%s %s = %q is not a long", id, name, value)
+				}
+			}
+		}
+	}
+
+	wantEdges := []exportedEdge{
+		{"Dataset/default/imagenet", "Pod/default/web-0",
+			map[string]string{"type": "labelSelector", "evidence": "fluid.io/dataset=imagenet"}},
+		{"Pod/default/web-0", "PersistentVolumeClaim/default/data",
+			map[string]string{"type": "volumeMount", "evidence": "spec.volumes[0].persistentVolumeClaim.claimName"}},
+		{"PersistentVolumeClaim/default/data", "PersistentVolume/pv-data",
+			map[string]string{"type": "volumeBinding"}},
+	}
+	if len(e.edges) != len(wantEdges) {
+		t.Fatalf("edges = %v, want %v", e.edges, wantEdges)
+	}
+	for i, want := range wantEdges {
+		got := e.edges[i]
+		if got.source != want.source || got.target != want.target || len(got.values) != len(want.values) {
+			t.Errorf("edge %d = %v, want %v", i, got, want)
+			continue
+		}
+		for name, value := range want.values {
+			if got.values[name] != value {
+				t.Errorf("edge %d %s = %q, want %q", i, name, got.values[name], value)
+			}
+		}
+	}
+}
+
+type xmlGraphML struct {
+	Keys []struct {
+		ID       string `xml:"id,attr"`
+		For      string `xml:"for,attr"`
+		AttrName string `xml:"attr.name,attr"`
+		AttrType string `xml:"attr.type,attr"`
+	} `xml:"key"`
+	Graph struct {
+		ID          string `xml:"id,attr"`
+		EdgeDefault string `xml:"edgedefault,attr"`
+		Nodes       []struct {
+			ID   string           `xml:"id,attr"`
+			Data []xmlGraphMLData `xml:"data"`
+		} `xml:"node"`
+		Edges []struct {
+			Source string           `xml:"source,attr"`
+			Target string           `xml:"target,attr"`
+			Data   []xmlGraphMLData `xml:"data"`
+		} `xml:"edge"`
+	} `xml:"graph"`
+}
+
+type xmlGraphMLData struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:",chardata"`
+}
+
+func TestGraphML(t *testing.T) {
+	var buf bytes.Buffer
+	if err := NewGraphMLFormatter().Format(&buf, exportGraph()); err != nil {
+		t.Fatal(err)
+	}
+	var doc xmlGraphML
+	if err := xml.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("invalid XML: %v\n%s", err, buf.String())
+	}
+	if doc.Graph.ID != "Dataset/default/imagenet" || doc.Graph.EdgeDefault != "directed" {
+		t.Errorf("graph id %q, edgedefault %q, want the root and directed", doc.Graph.ID, doc.Graph.EdgeDefault)
+	}
+
+	e := exported{types: make(map[string]string), nodes: make(map[string]map[string]string)}
+	names := make(map[string]string)
+	for _, key := range doc.Keys {
+		if _, ok := names[key.ID]; ok {
+			t.Errorf("key %s declared twice", key.ID)
+		}
+		names[key.ID] = key.AttrName
+		e.types[key.For+":"+key.AttrName] = key.AttrType
+	}
+	values := func(data []xmlGraphMLData) map[string]string {
+		values := make(map[string]string)
+		for _, d := range data {
+			name, ok := names[d.Key]
+			if !ok {
+				t.Errorf("data refers to undeclared key %s", d.Key)
+			}
+			values[name] = d.Value
+		}
+		return values
+	}
+	for _, n := range doc.Graph.Nodes {
+		e.nodes[n.ID] = values(n.Data)
+	}
+	for _, edge := range doc.Graph.Edges {
+		e.edges = append(e.edges, exportedEdge{edge.Source, edge.Target, values(edge.Data)})
+	}
+	checkExport(t, e)
+}
@@ -0,0 +1,47 @@
+package format
+
+import (
+	_ "embed"
+	"encoding/json"
+	"html/template"
+	"io"
+	"time"
+)
+
+//go:embed assets/graph.html
+var htmlTemplateSource string
+
+var htmlTemplate = template.Must(template.New("graph.html").Parse(htmlTemplateSource))
+
+// HTMLFormatter writes a single self-contained HTML page with the graph
+// document embedded and a small renderer inlined, so the file can be opened
+// offline.
+type HTMLFormatter struct {
+	opts Options
+}
+
+func NewHTMLFormatter(opts Options) *HTMLFormatter {
+	return &HTMLFormatter{opts: opts}
+}
+
+func (hf *HTMLFormatter) Format(w io.Writer, g *Graph) error {
+	// json.Marshal escapes <, > and &, so the document is safe to inline in
+	// a script element.
+	data, err := json.Marshal(NewDocument(g))
+	if err != nil {
+		return err
+	}
+	title := "kubectl-graph"
+	if g.Root != nil {
+		title = string(g.Root.Type) + " " + g.Root.Name
+	}
+	return htmlTemplate.Execute(w, struct {
+		Title string
+		Now   string
+		Graph template.JS
+	}{
+		Title: title,
+		Now:   hf.opts.now(g).UTC().Format(time.RFC3339),
+		Graph: template.JS(data),
+	})
+}
@@ -0,0 +1,56 @@
+package format
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestHTML(t *testing.T) {
+	pod := &Resource{Type: ResourceTypePod, Name: "web-0", Namespace: "default", Status: "Running"}
+	pvc := &Resource{Type: ResourceTypePVC, Name: "data", Namespace: "default", Status: "Bound"}
+	rooted := NewGraph(&Resource{Type: ResourceTypeDataset, Name: "imagenet", Namespace: "default", Status: "Bound"})
+	rootless := NewGraph(nil)
+	for _, g := range []*Graph{rooted, rootless} {
+		g.AddResource(pod)
+		g.AddResource(pvc)
+		g.AddEdge(pod, pvc, EdgeTypeVolumeMount, "spec.volumes[0].persistentVolumeClaim.claimName")
+	}
+	tests := []struct {
+		name  string
+		g     *Graph
+		title string
+		root  bool
+	}{
+		{"root", rooted, "Dataset imagenet", true},
+		{"no root", rootless, "kubectl-graph", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := NewHTMLFormatter(Options{}).Format(&buf, tt.g); err != nil {
+				t.Fatal(err)
+			}
+			out := buf.String()
+			if !strings.Contains(out, "<title>"+tt.title+"</title>") {
+				t.Errorf("page does not have the title %q", tt.title)
+			}
+			// The document is inlined as a script literal, which must
+			// still be a valid graph document.
+			_, rest, ok := strings.Cut(out, "const graph = ")
+			if !ok {
+				t.Fatal("page does not embed the graph document")
+			}
+			data, _, _ := strings.Cut(rest, ";\n")
+			if err := ValidateDocument([]byte(data)); err != nil {
+				t.Fatalf("embedded document: %v", err)
+			}
+			if got := strings.Contains(data, `"root":`); got != tt.root {
+				t.Errorf("embedded document has a root = %v, want %v", got, tt.root)
+			}
+			if !strings.Contains(data, `"id":"Pod/default/web-0"`) {
+				t.Errorf("embedded document misses the pod: %s", data)
+			}
+		})
+	}
+}
@@ -3,6 +3,7 @@ package format
 import (
 	"encoding/json"
 	"fmt"
+	"io"
 )
 
 type JSONFormatter struct{}
@@ -10,22 +11,14 @@ type JSONFormatter struct{}
 func NewJSONFormatter() *JSONFormatter {
 	return &JSONFormatter{}
 }
-func (jf *JSONFormatter) Format(g *Graph) error {
-	output := struct {
-		Root      *Resource                    `json:"root"`
-		Resources map[ResourceType][]*Resource `json:"resources"`
-		Edges     []Edge                       `json:"edges"`
-	}{
-		Root:      g.Root,
-		Resources: g.Resources,
-		Edges:     g.Edges,
-	}
 
-	data, err := json.MarshalIndent(output, "", "  ")
+// Format writes the graph as a versioned Document.
+func (jf *JSONFormatter) Format(w io.Writer, g *Graph) error {
+	data, err := json.MarshalIndent(NewDocument(g), "", "  ")
 	if err != nil {
 		return err
 	}
 
-	fmt.Println(string(data))
-	return nil
+	_, err = fmt.Fprintln(w, string(data))
+	return err
 }
@@ -0,0 +1,456 @@
+package format
+
+import (
+	"cmp"
+	"slices"
+	"sort"
+)
+
+// LayoutConfig sets the geometry used by LayeredLayout.
+type LayoutConfig struct {
+	NodeWidth  float64
+	NodeHeight float64
+	// LayerGap is the vertical space between layers and NodeGap the
+	// horizontal space between neighbouring nodes of a layer.
+	LayerGap float64
+	NodeGap  float64
+	// Margin is added around the drawing.
+	Margin float64
+}
+
+// DefaultLayoutConfig fits node boxes that hold a kind line and a name of
+// about 24 characters.
+var DefaultLayoutConfig = LayoutConfig{
+	NodeWidth:  190,
+	NodeHeight: 48,
+	LayerGap:   70,
+	NodeGap:    30,
+	Margin:     20,
+}
+
+// Point is a position in layout coordinates, with y growing downwards.
+type Point struct {
+	X, Y float64
+}
+
+// LayoutNode is a placed resource. X and Y are the top-left corner of its box.
+type LayoutNode struct {
+	Resource *Resource
+	Layer    int
+	X, Y     float64
+}
+
+// LayoutEdge is a routed edge. Points run from the source box to the target
+// box, passing through one bend point per layer crossed.
+type LayoutEdge struct {
+	Edge   Edge
+	Points []Point
+}
+
+// Layout is the result of LayeredLayout.
+type Layout struct {
+	Nodes  []*LayoutNode
+	Edges  []LayoutEdge
+	Width  float64
+	Height float64
+}
+
+// edgeKey is an ordered pair of node indexes.
+type edgeKey struct{ from, to int }
+
+// vertex is a real node or a dummy node inserted where an edge crosses a
+// layer.
+type vertex struct {
+	res   *Resource
+	layer int
+	pos   float64
+	x     float64
+	in    []*vertex
+	out   []*vertex
+}
+
+// LayeredLayout places the graph top to bottom using the Sugiyama method:
+// cycles are broken by reversing back edges, nodes are assigned to layers by
+// longest path, long edges are split with dummy nodes, crossings are
+// reduced with barycenter sweeps and x coordinates are balanced towards
+// neighbours. Every step iterates in the order of canonicalOrder, so the
+// same graph always yields the same layout, whatever order its resources
+// and edges were added in.
+func LayeredLayout(g *Graph, cfg LayoutConfig) *Layout {
+	nodes, edges := canonicalOrder(g)
+	index := make(map[*Resource]int, len(nodes))
+	for i, res := range nodes {
+		index[res] = i
+	}
+
+	// Keep one edge per ordered pair for layering; parallel edges share a
+	// route.
+	adjacent := make([][]int, len(nodes))
+	seen := make(map[edgeKey]bool)
+	for _, edge := range edges {
+		from, okFrom := index[edge.From]
+		to, okTo := index[edge.To]
+		if !okFrom || !okTo || from == to || seen[edgeKey{from, to}] {
+			continue
+		}
+		seen[edgeKey{from, to}] = true
+		adjacent[from] = append(adjacent[from], to)
+	}
+	reversed := breakCycles(adjacent)
+	layers := assignLayers(adjacent, reversed)
+
+	vertices := make([]*vertex, len(nodes))
+	for i, res := range nodes {
+		vertices[i] = &vertex{res: res, layer: layers[i]}
+	}
+	// chains maps each layered pair to the vertices its route passes
+	// through, from the upper layer to the lower one.
+	chains := make(map[edgeKey][]*vertex)
+	for from, targets := range adjacent {
+		for _, to := range targets {
+			upper, lower := from, to
+			if reversed[edgeKey{from, to}] {
+				upper, lower = to, from
+			}
+			if _, ok := chains[edgeKey{upper, lower}]; ok {
+				continue
+			}
+			chain := []*vertex{vertices[upper]}
+			prev := vertices[upper]
+			for l := layers[upper] + 1; l < layers[lower]; l++ {
+				dummy := &vertex{layer: l}
+				prev.out = append(prev.out, dummy)
+				dummy.in = append(dummy.in, prev)
+				chain = append(chain, dummy)
+				vertices = append(vertices, dummy)
+				prev = dummy
+			}
+			prev.out = append(prev.out, vertices[lower])
+			vertices[lower].in = append(vertices[lower].in, prev)
+			chain = append(chain, vertices[lower])
+			chains[edgeKey{upper, lower}] = chain
+		}
+	}
+
+	rows := orderLayers(vertices)
+	assignX(rows, cfg)
+
+	layout := &Layout{}
+	minX := 0.0
+	for _, v := range vertices {
+		if v.x-width(v, cfg)/2 < minX {
+			minX = v.x - width(v, cfg)/2
+		}
+	}
+	shift := cfg.Margin - minX
+	for _, v := range vertices {
+		v.x += shift
+		if right := v.x + width(v, cfg)/2 + cfg.Margin; right > layout.Width {
+			layout.Width = right
+		}
+	}
+	top := func(layer int) float64 {
+		return cfg.Margin + float64(layer)*(cfg.NodeHeight+cfg.LayerGap)
+	}
+	placed := make(map[*Resource]*LayoutNode, len(nodes))
+	for i, res := range nodes {
+		v := vertices[i]
+		n := &LayoutNode{Resource: res, Layer: v.layer, X: v.x - cfg.NodeWidth/2, Y: top(v.layer)}
+		placed[res] = n
+		layout.Nodes = append(layout.Nodes, n)
+	}
+	layout.Height = top(len(rows)) - cfg.LayerGap + cfg.Margin
+	if len(rows) == 0 {
+		layout.Height = 2 * cfg.Margin
+	}
+
+	for _, edge := range edges {
+		from, okFrom := index[edge.From]
+		to, okTo := index[edge.To]
+		if !okFrom || !okTo {
+			continue
+		}
+		if from == to {
+			n := placed[edge.From]
+			right := n.X + cfg.NodeWidth
+			layout.Edges = append(layout.Edges, LayoutEdge{Edge: edge, Points: []Point{
+				{right, n.Y + cfg.NodeHeight/3},
+				{right + cfg.NodeGap, n.Y},
+				{right + cfg.NodeGap, n.Y + cfg.NodeHeight},
+				{right, n.Y + 2*cfg.NodeHeight/3},
+			}})
+			continue
+		}
+		chain, ok := chains[edgeKey{from, to}]
+		flip := false
+		if !ok {
+			chain = chains[edgeKey{to, from}]
+			flip = true
+		}
+		points := make([]Point, 0, len(chain))
+		for i, v := range chain {
+			y := top(v.layer) + cfg.NodeHeight/2
+			switch {
+			case i == 0:
+				y = top(v.layer) + cfg.NodeHeight
+			case i == len(chain)-1:
+				y = top(v.layer)
+			}
+			points = append(points, Point{v.x, y})
+		}
+		if flip {
+			for i, j := 0, len(points)-1; i < j; i, j = i+1, j-1 {
+				points[i], points[j] = points[j], points[i]
+			}
+		}
+		layout.Edges = append(layout.Edges, LayoutEdge{Edge: edge, Points: points})
+	}
+	return layout
+}
+
+// canonicalOrder returns the nodes in g.Nodes order with the resources of
+// each type sorted by ID, and the edges sorted by their endpoints, type and
+// evidence.
+func canonicalOrder(g *Graph) ([]*Resource, []Edge) {
+	nodes := g.Nodes()
+	rank := make(map[ResourceType]int)
+	for _, res := range nodes {
+		if _, ok := rank[res.Type]; !ok {
+			rank[res.Type] = len(rank)
+		}
+	}
+	// The root stays first.
+	sorted := nodes
+	if g.Root != nil {
+		sorted = nodes[1:]
+	}
+	slices.SortStableFunc(sorted, func(a, b *Resource) int {
+		return cmp.Or(cmp.Compare(rank[a.Type], rank[b.Type]), cmp.Compare(a.ID(), b.ID()))
+	})
+	edges := slices.Clone(g.Edges)
+	slices.SortStableFunc(edges, func(a, b Edge) int {
+		return cmp.Or(cmp.Compare(a.From.ID(), b.From.ID()), cmp.Compare(a.To.ID(), b.To.ID()),
+			cmp.Compare(a.Type, b.Type), cmp.Compare(a.Evidence, b.Evidence))
+	})
+	return nodes, edges
+}
+
+// breakCycles runs a depth-first search in node order and returns the back
+// edges, which are laid out as if they pointed the other way.
+func breakCycles(adjacent [][]int) map[edgeKey]bool {
+	const (
+		unvisited = iota
+		active
+		done
+	)
+	state := make([]int, len(adjacent))
+	reversed := make(map[edgeKey]bool)
+	var visit func(int)
+	visit = func(n int) {
+		state[n] = active
+		for _, m := range adjacent[n] {
+			switch state[m] {
+			case unvisited:
+				visit(m)
+			case active:
+				reversed[edgeKey{n, m}] = true
+			}
+		}
+		state[n] = done
+	}
+	for n := range adjacent {
+		if state[n] == unvisited {
+			visit(n)
+		}
+	}
+	return reversed
+}
+
+// assignLayers puts every node one layer below its lowest predecessor.
+func assignLayers(adjacent [][]int, reversed map[edgeKey]bool) []int {
+	preds := make([][]int, len(adjacent))
+	for from, targets := range adjacent {
+		for _, to := range targets {
+			if reversed[edgeKey{from, to}] {
+				preds[from] = append(preds[from], to)
+			} else {
+				preds[to] = append(preds[to], from)
+			}
+		}
+	}
+	layers := make([]int, len(adjacent))
+	for i := range layers {
+		layers[i] = -1
+	}
+	var layerOf func(int) int
+	layerOf = func(n int) int {
+		if layers[n] >= 0 {
+			return layers[n]
+		}
+		layer := 0
+		for _, p := range preds[n] {
+			if l := layerOf(p) + 1; l > layer {
+				layer = l
+			}
+		}
+		layers[n] = layer
+		return layer
+	}
+	for n := range adjacent {
+		layerOf(n)
+	}
+	return layers
+}
+
+// orderLayers groups vertices by layer and reduces crossings with
+// alternating downward and upward barycenter sweeps, keeping the best
+// ordering seen.
+func orderLayers(vertices []*vertex) [][]*vertex {
+	rows := make([][]*vertex, 0)
+	for _, v := range vertices {
+		for len(rows) <= v.layer {
+			rows = append(rows, make([]*vertex, 0))
+		}
+		rows[v.layer] = append(rows[v.layer], v)
+	}
+	renumber := func() {
+		for _, row := range rows {
+			for i, v := range row {
+				v.pos = float64(i)
+			}
+		}
+	}
+	renumber()
+
+	best := snapshot(rows)
+	bestCrossings := countCrossings(rows)
+	for sweep := 0; sweep < 8 && bestCrossings > 0; sweep++ {
+		if sweep%2 == 0 {
+			for l := 1; l < len(rows); l++ {
+				sortByBarycenter(rows[l], func(v *vertex) []*vertex { return v.in })
+				renumber()
+			}
+		} else {
+			for l := len(rows) - 2; l >= 0; l-- {
+				sortByBarycenter(rows[l], func(v *vertex) []*vertex { return v.out })
+				renumber()
+			}
+		}
+		if c := countCrossings(rows); c < bestCrossings {
+			best, bestCrossings = snapshot(rows), c
+		}
+	}
+	rows = best
+	renumber()
+	return rows
+}
+
+func snapshot(rows [][]*vertex) [][]*vertex {
+	copied := make([][]*vertex, len(rows))
+	for i, row := range rows {
+		copied[i] = append([]*vertex(nil), row...)
+	}
+	return copied
+}
+
+func sortByBarycenter(row []*vertex, neighbours func(*vertex) []*vertex) {
+	center := make(map[*vertex]float64, len(row))
+	for _, v := range row {
+		ns := neighbours(v)
+		if len(ns) == 0 {
+			center[v] = v.pos
+			continue
+		}
+		sum := 0.0
+		for _, n := range ns {
+			sum += n.pos
+		}
+		center[v] = sum / float64(len(ns))
+	}
+	sort.SliceStable(row, func(i, j int) bool {
+		return center[row[i]] < center[row[j]]
+	})
+}
+
+func countCrossings(rows [][]*vertex) int {
+	crossings := 0
+	for _, row := range rows {
+		type segment struct{ a, b float64 }
+		segments := make([]segment, 0)
+		for _, v := range row {
+			for _, w := range v.out {
+				segments = append(segments, segment{v.pos, w.pos})
+			}
+		}
+		for i := range segments {
+			for j := i + 1; j < len(segments); j++ {
+				s, t := segments[i], segments[j]
+				if (s.a < t.a && s.b > t.b) || (s.a > t.a && s.b < t.b) {
+					crossings++
+				}
+			}
+		}
+	}
+	return crossings
+}
+
+func width(v *vertex, cfg LayoutConfig) float64 {
+	if v.res == nil {
+		return cfg.NodeGap / 2
+	}
+	return cfg.NodeWidth
+}
+
+// separation is the minimum distance between the centers of neighbours a
+// and b.
+func separation(a, b *vertex, cfg LayoutConfig) float64 {
+	return width(a, cfg)/2 + cfg.NodeGap + width(b, cfg)/2
+}
+
+// assignX packs every layer left to right, then repeatedly pulls vertices
+// towards the mean x of their neighbours without letting them overlap.
+func assignX(rows [][]*vertex, cfg LayoutConfig) {
+	for _, row := range rows {
+		x := 0.0
+		for i, v := range row {
+			if i > 0 {
+				x += separation(row[i-1], v, cfg)
+			}
+			v.x = x
+		}
+	}
+	for iter := 0; iter < 12; iter++ {
+		for _, row := range rows {
+			desired := make([]float64, len(row))
+			for i, v := range row {
+				desired[i] = v.x
+				neighbours := append(append([]*vertex(nil), v.in...), v.out...)
+				if len(neighbours) == 0 {
+					continue
+				}
+				sum := 0.0
+				for _, n := range neighbours {
+					sum += n.x
+				}
+				desired[i] = sum / float64(len(neighbours))
+			}
+			// Resolve overlaps once pushing right and once pushing left,
+			// and take the mean; both are valid, so their mean is too.
+			right := append([]float64(nil), desired...)
+			for i := 1; i < len(row); i++ {
+				if minX := right[i-1] + separation(row[i-1], row[i], cfg); right[i] < minX {
+					right[i] = minX
+				}
+			}
+			left := append([]float64(nil), desired...)
+			for i := len(row) - 2; i >= 0; i-- {
+				if maxX := left[i+1] - separation(row[i], row[i+1], cfg); left[i] > maxX {
+					left[i] = maxX
+				}
+			}
+			for i, v := range row {
+				v.x = (left[i] + right[i]) / 2
+			}
+		}
+	}
+}
@@ -0,0 +1,181 @@
+package format
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+type layoutEdge struct {
+	from, to string
+	edgeType EdgeType
+}
+
+// layoutGraph builds a graph from resources named "Type/name" and edges
+// between them, adding both in the given orders.
+func layoutGraph(root string, names []string, edges []layoutEdge, nodeOrder, edgeOrder []int) *Graph {
+	resources := make(map[string]*Resource)
+	resource := func(id string) *Resource {
+		if res, ok := resources[id]; ok {
+			return res
+		}
+		t, name, _ := strings.Cut(id, "/")
+		res := &Resource{Type: ResourceType(t), Name: name, Namespace: "default", Status: "Running"}
+		resources[id] = res
+		return res
+	}
+	var g *Graph
+	if root != "" {
+		g = NewGraph(resource(root))
+	} else {
+		g = NewGraph(nil)
+	}
+	for _, i := range nodeOrder {
+		g.AddResource(resource(names[i]))
+	}
+	for _, i := range edgeOrder {
+		e := edges[i]
+		g.AddEdge(resource(e.from), resource(e.to), e.edgeType, "")
+	}
+	return g
+}
+
+func TestLayoutDeterminism(t *testing.T) {
+	tests := []struct {
+		name  string
+		root  string
+		nodes []string
+		edges []layoutEdge
+	}{
+		{
+			name:  "rooted",
+			root:  "Dataset/imagenet",
+			nodes: []string{"Runtime/imagenet", "Pod/worker-0", "Pod/worker-1", "Pod/fuse-0", "PersistentVolumeClaim/imagenet", "Service/master"},
+			edges: []layoutEdge{
+				{"Dataset/imagenet", "Runtime/imagenet", EdgeTypeRuntimeBinding},
+				{"Dataset/imagenet", "Pod/worker-0", EdgeTypeLabelSelector},
+				{"Dataset/imagenet", "Pod/worker-1", EdgeTypeLabelSelector},
+				{"Runtime/imagenet", "Pod/fuse-0", EdgeTypeOwnerReference},
+				{"Pod/worker-0", "PersistentVolumeClaim/imagenet", EdgeTypeVolumeMount},
+				{"Pod/worker-1", "PersistentVolumeClaim/imagenet", EdgeTypeVolumeMount},
+				{"Service/master", "Pod/worker-0", EdgeTypeServiceSelector},
+				{"Dataset/imagenet", "PersistentVolumeClaim/imagenet", EdgeTypeVolumeBinding},
+			},
+		},
+		{
+			name:  "root-less",
+			nodes: []string{"Deployment/train", "Pod/train-0", "Pod/train-1", "PersistentVolumeClaim/data", "Service/web", "ConfigMap/settings"},
+			edges: []layoutEdge{
+				{"Deployment/train", "Pod/train-0", EdgeTypeOwnerReference},
+				{"Deployment/train", "Pod/train-1", EdgeTypeOwnerReference},
+				{"Pod/train-0", "PersistentVolumeClaim/data", EdgeTypeVolumeMount},
+				{"Pod/train-1", "ConfigMap/settings", EdgeTypeConfigRef},
+				{"Service/web", "Pod/train-1", EdgeTypeServiceSelector},
+			},
+		},
+		{
+			name:  "cycles",
+			root:  "Dataset/imagenet",
+			nodes: []string{"Pod/a", "Pod/b", "Pod/c", "PersistentVolumeClaim/d"},
+			edges: []layoutEdge{
+				{"Dataset/imagenet", "Pod/a", EdgeTypeLabelSelector},
+				{"Pod/a", "Pod/b", EdgeTypeOwnerReference},
+				{"Pod/b", "Pod/c", EdgeTypeOwnerReference},
+				{"Pod/c", "Pod/a", EdgeTypeOwnerReference},
+				{"Pod/c", "PersistentVolumeClaim/d", EdgeTypeVolumeMount},
+				{"PersistentVolumeClaim/d", "Pod/c", EdgeTypeVolumeBinding},
+				{"Pod/b", "Pod/b", EdgeTypeOwnerReference},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			forward := make([]int, len(tt.nodes))
+			backward := make([]int, len(tt.nodes))
+			for i := range tt.nodes {
+				forward[i], backward[i] = i, len(tt.nodes)-1-i
+			}
+			edgesForward := make([]int, len(tt.edges))
+			edgesBackward := make([]int, len(tt.edges))
+			for i := range tt.edges {
+				edgesForward[i], edgesBackward[i] = i, len(tt.edges)-1-i
+			}
+			// Interleaved, so that neither order is a reversal of the other.
+			var interleaved []int
+			for i := 0; i < len(tt.nodes); i += 2 {
+				interleaved = append(interleaved, i)
+			}
+			for i := 1; i < len(tt.nodes); i += 2 {
+				interleaved = append(interleaved, i)
+			}
+			graphs := []*Graph{
+				layoutGraph(tt.root, tt.nodes, tt.edges, forward, edgesForward),
+				layoutGraph(tt.root, tt.nodes, tt.edges, backward, edgesBackward),
+				layoutGraph(tt.root, tt.nodes, tt.edges, interleaved, edgesBackward),
+			}
+
+			want := LayeredLayout(graphs[0], DefaultLayoutConfig)
+			checkLayout(t, graphs[0], want)
+			var wantSVG bytes.Buffer
+			if err := NewSVGFormatter(Options{}).Format(&wantSVG, graphs[0]); err != nil {
+				t.Fatal(err)
+			}
+			for i, g := range graphs[1:] {
+				got := LayeredLayout(g, DefaultLayoutConfig)
+				if got.Width != want.Width || got.Height != want.Height {
+					t.Errorf("order %d: size %vx%v, want %vx%v", i+1, got.Width, got.Height, want.Width, want.Height)
+				}
+				for j, n := range got.Nodes {
+					w := want.Nodes[j]
+					if n.Resource.ID() != w.Resource.ID() || n.X != w.X || n.Y != w.Y || n.Layer != w.Layer {
+						t.Errorf("order %d: node %d = %s at (%v, %v), want %s at (%v, %v)",
+							i+1, j, n.Resource.ID(), n.X, n.Y, w.Resource.ID(), w.X, w.Y)
+					}
+				}
+				for j, e := range got.Edges {
+					w := want.Edges[j]
+					if edgeTitle(e.Edge) != edgeTitle(w.Edge) || fmt.Sprint(e.Points) != fmt.Sprint(w.Points) {
+						t.Errorf("order %d: edge %d = %s %v, want %s %v",
+							i+1, j, edgeTitle(e.Edge), e.Points, edgeTitle(w.Edge), w.Points)
+					}
+				}
+				var svg bytes.Buffer
+				if err := NewSVGFormatter(Options{}).Format(&svg, g); err != nil {
+					t.Fatal(err)
+				}
+				if !bytes.Equal(svg.Bytes(), wantSVG.Bytes()) {
+					t.Errorf("order %d: SVG differs:\n%s\nwant\n%s", i+1, svg.Bytes(), wantSVG.Bytes())
+				}
+			}
+		})
+	}
+}
+
+// checkLayout checks that every node and edge of g is placed, that no two
+// boxes of a layer overlap and that everything fits the drawing.
+func checkLayout(t *testing.T, g *Graph, layout *Layout) {
+	t.Helper()
+	cfg := DefaultLayoutConfig
+	if len(layout.Nodes) != len(g.Nodes()) {
+		t.Errorf("%d nodes placed, want %d", len(layout.Nodes), len(g.Nodes()))
+	}
+	if len(layout.Edges) != len(g.Edges) {
+		t.Errorf("%d edges routed, want %d", len(layout.Edges), len(g.Edges))
+	}
+	for i, a := range layout.Nodes {
+		if a.X < 0 || a.Y < 0 || a.X+cfg.NodeWidth > layout.Width || a.Y+cfg.NodeHeight > layout.Height {
+			t.Errorf("%s at (%v, %v) is outside the %vx%v drawing", a.Resource.ID(), a.X, a.Y, layout.Width, layout.Height)
+		}
+		for _, b := range layout.Nodes[i+1:] {
+			if a.Layer == b.Layer && a.X < b.X+cfg.NodeWidth && b.X < a.X+cfg.NodeWidth {
+				t.Errorf("%s at %v overlaps %s at %v", a.Resource.ID(), a.X, b.Resource.ID(), b.X)
+			}
+		}
+	}
+	for _, e := range layout.Edges {
+		if len(e.Points) < 2 {
+			t.Errorf("edge %s has %d points", edgeTitle(e.Edge), len(e.Points))
+		}
+	}
+}
@@ -0,0 +1,239 @@
+package format
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// MarkdownFormatter prints an incident report in GitHub-flavored Markdown:
+// the root resource, a health summary, a table per resource type, a
+// Mermaid diagram, the relationships with their evidence, the pod
+// conditions that are not True and a list of findings. Unlike the table output it uses no colors or emoji, so it can
+// be pasted into a ticket as is.
+type MarkdownFormatter struct {
+	opts Options
+}
+
+func NewMarkdownFormatter(opts Options) *MarkdownFormatter {
+	return &MarkdownFormatter{opts: opts}
+}
+
+func (mf *MarkdownFormatter) Format(out io.Writer, g *Graph) error {
+	now := mf.opts.now(g)
+	w := bufio.NewWriter(out)
+	writeMarkdownHeader(w, g, now)
+	writeHealthSummary(w, g)
+	for _, t := range g.resourceTypes() {
+		writeMarkdownTable(w, t, g.Resources[t], now)
+	}
+	fmt.Fprint(w, "## Dependency graph\n\n```mermaid\n")
+	writeMermaid(w, g)
+	fmt.Fprint(w, "```\n\n")
+	writeRelationships(w, g.Edges)
+	writePodConditions(w, g.Resources[ResourceTypePod])
+	writeFindings(w, g, now)
+	return w.Flush()
+}
+
+func writeMarkdownHeader(w io.Writer, g *Graph, now time.Time) {
+	root := g.Root
+	if root == nil {
+		// Query results have no root when it did not match.
+		fmt.Fprint(w, "# Resources\n\n")
+		fmt.Fprint(w, "| Field | Value |\n| --- | --- |\n")
+	} else {
+		fmt.Fprintf(w, "# %s %s\n\n", root.Type, mdText(resourceName(root)))
+		fmt.Fprint(w, "| Field | Value |\n| --- | --- |\n")
+		fmt.Fprintf(w, "| Status | %s |\n", mdCell(root.Status))
+		fmt.Fprintf(w, "| Age | %s |\n", mdAge(root, now))
+		if root.DeletionTimestamp != nil {
+			fmt.Fprintf(w, "| Deletion requested | %s ago |\n", FormatAge(now.Sub(*root.DeletionTimestamp)))
+		}
+		for _, d := range sortedDetails(root.Details) {
+			fmt.Fprintf(w, "| %s | %s |\n", mdCell(d.Key), mdCell(d.String()))
+		}
+	}
+	if g.Metadata.Cluster != "" {
+		fmt.Fprintf(w, "| Cluster | %s |\n", mdCell(g.Metadata.Cluster))
+	}
+	if g.Metadata.Context != "" {
+		fmt.Fprintf(w, "| Context | %s |\n", mdCell(g.Metadata.Context))
+	}
+	if !g.Metadata.CollectedAt.IsZero() {
+		fmt.Fprintf(w, "| Collected at | %s |\n", g.Metadata.CollectedAt.UTC().Format(time.RFC3339))
+	}
+	fmt.Fprintln(w)
+}
+
+var healthNames = []struct {
+	health Health
+	name   string
+}{
+	{HealthFailing, "Failing"},
+	{HealthWarning, "Warning"},
+	{HealthUnknown, "Unknown"},
+	{HealthOK, "Healthy"},
+}
+
+// writeHealthSummary counts the resources of each health and names the
+// ones that are not healthy.
+func writeHealthSummary(w io.Writer, g *Graph) {
+	byHealth := make(map[Health][]*Resource)
+	nodes := g.Nodes()
+	for _, res := range nodes {
+		h := StatusHealth(res.Status)
+		byHealth[h] = append(byHealth[h], res)
+	}
+
+	fmt.Fprintf(w, "## Health summary\n\n%d resources, %d relationships.\n\n", len(nodes), len(g.Edges))
+	fmt.Fprint(w, "| Health | Count | Resources |\n| --- | ---: | --- |\n")
+	for _, hn := range healthNames {
+		resources := byHealth[hn.health]
+		if len(resources) == 0 {
+			continue
+		}
+		names := "-"
+		if hn.health != HealthOK {
+			refs := make([]string, len(resources))
+			for i, res := range resources {
+				ref := resourceRef(res)
+				if res.Status != "" {
+					ref = fmt.Sprintf("%s (%s)", ref, res.Status)
+				}
+				refs[i] = mdCell(ref)
+			}
+			names = strings.Join(refs, ", ")
+		}
+		fmt.Fprintf(w, "| %s | %d | %s |\n", hn.name, len(resources), names)
+	}
+	fmt.Fprintln(w)
+}
+
+func writeMarkdownTable(w io.Writer, t ResourceType, resources []*Resource, now time.Time) {
+	fmt.Fprintf(w, "## %s (%d)\n\n", t, len(resources))
+	fmt.Fprint(w, "| Name | Namespace | Status | Age | Details |\n| --- | --- | --- | --- | --- |\n")
+	for _, res := range resources {
+		fmt.Fprintf(w, "| %s | %s | %s | %s | %s |\n",
+			mdCell(res.Name), mdCell(res.Namespace), mdCell(res.Status),
+			mdAge(res, now), mdCell(formatDetails(res)))
+	}
+	fmt.Fprintln(w)
+}
+
+func writeRelationships(w io.Writer, edges []Edge) {
+	if len(edges) == 0 {
+		return
+	}
+	fmt.Fprint(w, "## Relationships\n\n")
+	fmt.Fprint(w, "| From | Type | To | Evidence |\n| --- | --- | --- | --- |\n")
+	for _, e := range edges {
+		evidence := e.Evidence
+		if e.Planned {
+			evidence += " (not yet present)"
+		}
+		fmt.Fprintf(w, "| %s | %s | %s | %s |\n",
+			mdCell(resourceRef(e.From)), mdCell(string(e.Type)), mdCell(resourceRef(e.To)), mdCell(evidence))
+	}
+	fmt.Fprintln(w)
+}
+
+func writePodConditions(w io.Writer, pods []*Resource) {
+	if len(pods) == 0 {
+		return
+	}
+	fmt.Fprint(w, "## Pod conditions\n\n")
+	var rows []string
+	reported := false
+	for _, pod := range pods {
+		for _, c := range pod.Conditions {
+			reported = true
+			if c.Status == "True" {
+				continue
+			}
+			rows = append(rows, fmt.Sprintf("| %s | %s | %s | %s | %s |",
+				mdCell(pod.Name), mdCell(c.Type), mdCell(c.Status), mdCell(c.Reason), mdCell(c.Message)))
+		}
+	}
+	if !reported {
+		fmt.Fprint(w, "No pod conditions reported.\n\n")
+		return
+	}
+	if len(rows) == 0 {
+		fmt.Fprint(w, "Every pod condition is True.\n\n")
+		return
+	}
+	fmt.Fprint(w, "| Pod | Condition | Status | Reason | Message |\n| --- | --- | --- | --- | --- |\n")
+	fmt.Fprintf(w, "%s\n\n", strings.Join(rows, "\n"))
+}
+
+// writeFindings lists, per resource, what an on-call engineer should look
+// at first: unhealthy statuses, restarts, pending deletion and conditions
+// that are not True on anything but pods, which have their own section.
+func writeFindings(w io.Writer, g *Graph, now time.Time) {
+	fmt.Fprint(w, "## Findings\n\n")
+	found := false
+	for _, res := range g.Nodes() {
+		var problems []string
+		switch StatusHealth(res.Status) {
+		case HealthFailing, HealthWarning:
+			problems = append(problems, fmt.Sprintf("is %s", res.Status))
+		}
+		if d, ok := res.Detail("restarts"); ok {
+			if n, _ := d.Int(); n > 0 {
+				problems = append(problems, fmt.Sprintf("has restarted %d times", n))
+			}
+		}
+		if res.DeletionTimestamp != nil {
+			problems = append(problems, fmt.Sprintf("has been terminating for %s", FormatAge(now.Sub(*res.DeletionTimestamp))))
+		}
+		if res.Type != ResourceTypePod {
+			for _, c := range res.Conditions {
+				if c.Status != "True" {
+					problems = append(problems, fmt.Sprintf("reports %s=%s", c.Type, c.Status))
+				}
+			}
+		}
+		if len(problems) == 0 {
+			continue
+		}
+		found = true
+		fmt.Fprintf(w, "- **%s** %s\n", mdText(resourceRef(res)), mdText(strings.Join(problems, ", ")))
+	}
+	if !found {
+		fmt.Fprint(w, "No problems found.\n")
+	}
+}
+
+func resourceName(res *Resource) string {
+	if res.Namespace == "" {
+		return res.Name
+	}
+	return res.Namespace + "/" + res.Name
+}
+
+// mdCell escapes s for a table cell, where pipes end the cell and
+// newlines end the row.
+func mdCell(s string) string {
+	if s == "" {
+		return "-"
+	}
+	s = strings.NewReplacer("|", `\|`, "\r\n", "<br>", "\n", "<br>").Replace(s)
+	return mdText(s)
+}
+
+// mdAge formats the age of res, or "-" when its creation time is not
+// known, since renderers would take "<unknown>" for an HTML tag.
+func mdAge(res *Resource, now time.Time) string {
+	if res.CreationTimestamp.IsZero() {
+		return "-"
+	}
+	return FormatAge(res.Age(now))
+}
+
+// mdText escapes the characters that would start emphasis or code.
+func mdText(s string) string {
+	return strings.NewReplacer("*", `\*`, "_", `\_`, "`", "\\`").Replace(s)
+}
@@ -0,0 +1,104 @@
+package format
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMarkdown(t *testing.T) {
+	running := &Resource{Type: ResourceTypePod, Name: "web-0", Namespace: "default", Status: "Running",
+		Conditions: []Condition{{Type: "Ready", Status: "True"}}}
+	pending := &Resource{Type: ResourceTypePod, Name: "web-1", Namespace: "default", Status: "Pending",
+		Conditions: []Condition{{Type: "PodScheduled", Status: "False", Reason: "Unschedulable"}}}
+	silent := &Resource{Type: ResourceTypePod, Name: "web-2", Namespace: "default", Status: "Pending"}
+	tests := []struct {
+		name    string
+		pods    []*Resource
+		want    []string
+		notWant []string
+	}{
+		{
+			name: "no conditions",
+			pods: []*Resource{silent},
+			want: []string{"No pod conditions reported.", "| Unknown | 1 | Runtime/imagenet |"},
+		},
+		{
+			name:    "all true",
+			pods:    []*Resource{running},
+			want:    []string{"Every pod condition is True."},
+			notWant: []string{"No pod conditions reported."},
+		},
+		{
+			name:    "not true",
+			pods:    []*Resource{running, pending, silent},
+			want:    []string{"| web-1 | PodScheduled | False | Unschedulable | - |", "Pod/web-1 (Pending)"},
+			notWant: []string{"Every pod condition is True.", "| web-0 | Ready"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := &Resource{Type: ResourceTypeDataset, Name: "imagenet", Namespace: "default", Status: "Bound"}
+			g := NewGraph(root)
+			g.Metadata.CollectedAt = time.Date(2024, 5, 3, 12, 0, 0, 0, time.UTC)
+			runtime := &Resource{Type: ResourceTypeRuntime, Name: "imagenet", Namespace: "default"}
+			g.AddResource(runtime)
+			for _, pod := range tt.pods {
+				g.AddResource(pod)
+			}
+			var buf bytes.Buffer
+			if err := NewMarkdownFormatter(Options{}).Format(&buf, g); err != nil {
+				t.Fatal(err)
+			}
+			out := buf.String()
+			if strings.Contains(out, "()") {
+				t.Errorf("report contains empty parentheses:\n%s", out)
+			}
+			for _, s := range tt.want {
+				if !strings.Contains(out, s) {
+					t.Errorf("report does not contain %q:\n%s", s, out)
+				}
+			}
+			for _, s := range tt.notWant {
+				if strings.Contains(out, s) {
+					t.Errorf("report contains %q:\n%s", s, out)
+				}
+			}
+		})
+	}
+}
+
+func TestMarkdownRelationships(t *testing.T) {
+	root := &Resource{Type: ResourceTypeDataset, Name: "imagenet", Namespace: "default", Status: "Bound"}
+	pod := &Resource{Type: ResourceTypePod, Name: "web-0", Namespace: "default", Status: "Running"}
+	planned := &Resource{Type: ResourceTypePod, Name: "train-*", Namespace: "default", Status: StatusNotYetPresent, Planned: true}
+	cm := &Resource{Type: ResourceTypeConfigMap, Name: "settings", Namespace: "default", Status: "Active"}
+	g := NewGraph(root)
+	for _, res := range []*Resource{pod, planned, cm} {
+		g.AddResource(res)
+	}
+	g.AddEdge(root, pod, EdgeTypeLabelSelector, "fluid.io/dataset=imagenet")
+	g.AddEdge(root, planned, EdgeTypeLabelSelector, "fluid.io/dataset=imagenet")
+	g.AddEdge(pod, cm, EdgeTypeConfigRef, `spec.volumes[1].configMap.name "a|b"`)
+
+	var buf bytes.Buffer
+	if err := NewMarkdownFormatter(Options{}).Format(&buf, g); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, s := range []string{
+		"## Relationships\n\n| From | Type | To | Evidence |\n| --- | --- | --- | --- |\n",
+		"| Dataset/imagenet | labelSelector | Pod/web-0 | fluid.io/dataset=imagenet |\n",
+		"| Dataset/imagenet | labelSelector | Pod/train-\\* | fluid.io/dataset=imagenet (not yet present) |\n",
+		`| Pod/web-0 | configRef | ConfigMap/settings | spec.volumes[1].configMap.name "a\|b" |` + "\n",
+		// The Mermaid diagram carries the evidence in its edge labels.
+		"  n0 -->|\"labelSelector: fluid.io/dataset=imagenet\"| n1\n",
+		"  n0 -.->|\"labelSelector: fluid.io/dataset=imagenet\"| n2\n",
+		"  n1 -->|\"configRef: spec.volumes[1].configMap.name #quot;a#124;b#quot;\"| n3\n",
+	} {
+		if !strings.Contains(out, s) {
+			t.Errorf("report does not contain %q:\n%s", s, out)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package format
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// MermaidFormatter prints the graph as a Mermaid flowchart, which GitHub,
+// GitLab and most wikis render inside a ```mermaid block.
+type MermaidFormatter struct{}
+
+func NewMermaidFormatter() *MermaidFormatter {
+	return &MermaidFormatter{}
+}
+
+func (mf *MermaidFormatter) Format(out io.Writer, g *Graph) error {
+	w := bufio.NewWriter(out)
+	writeMermaid(w, g)
+	return w.Flush()
+}
+
+var mermaidClasses = map[Health]string{
+	HealthOK:      "ok",
+	HealthWarning: "warn",
+	HealthFailing: "bad",
+	HealthUnknown: "unknown",
+}
+
+// writeMermaid writes the flowchart without the surrounding code fence.
+// Nodes are numbered in Nodes order, so the output is stable for a graph.
+func writeMermaid(w io.Writer, g *Graph) {
+	fmt.Fprintln(w, "flowchart LR")
+	ids := make(map[*Resource]string)
+	for i, res := range g.Nodes() {
+		id := fmt.Sprintf("n%d", i)
+		ids[res] = id
+		fmt.Fprintf(w, "  %s[\"%s<br/>%s<br/><i>%s</i>\"]:::%s\n", id,
+			escapeMermaid(string(res.Type)), escapeMermaid(res.Name), escapeMermaid(res.Status),
+			mermaidClasses[StatusHealth(res.Status)])
+	}
+	for _, e := range g.Edges {
+		from, to := ids[e.From], ids[e.To]
+		if from == "" || to == "" {
+			continue
+		}
+		arrow := "-->"
+		if e.Planned {
+			arrow = "-.->"
+		}
+		// The label is quoted, as evidence such as field paths holds
+		// brackets and parentheses.
+		label := string(e.Type)
+		if e.Evidence != "" {
+			label += ": " + e.Evidence
+		}
+		fmt.Fprintf(w, "  %s %s|\"%s\"| %s\n", from, arrow, escapeMermaid(label), to)
+	}
+	for _, h := range []Health{HealthOK, HealthWarning, HealthFailing, HealthUnknown} {
+		p := healthPalettes[h]
+		fmt.Fprintf(w, "  classDef %s fill:%s,stroke:%s\n", mermaidClasses[h], p.fill, p.stroke)
+	}
+}
+
+// escapeMermaid replaces the characters that end a quoted Mermaid label or
+// would be read as HTML with Mermaid's entity codes.
+func escapeMermaid(s string) string {
+	return strings.NewReplacer(`"`, "#quot;", "<", "#lt;", ">", "#gt;", "|", "#124;").Replace(s)
+}
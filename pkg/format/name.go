@@ -0,0 +1,23 @@
+package format
+
+import (
+	"fmt"
+	"io"
+)
+
+// NameFormatter prints every node as kind/name or kind.group/name, one per
+// line, like kubectl's -o name, so the output can be piped into kubectl.
+type NameFormatter struct{}
+
+func NewNameFormatter() *NameFormatter {
+	return &NameFormatter{}
+}
+
+func (nf *NameFormatter) Format(w io.Writer, g *Graph) error {
+	for _, res := range g.Nodes() {
+		if _, err := fmt.Fprintln(w, res.QualifiedName()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -1,14 +1,190 @@
 package format
 
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+)
+
+// Formatter writes a graph to w.
 type Formatter interface {
-	Format(g *Graph) error
+	Format(w io.Writer, g *Graph) error
+}
+
+// DirFormatter is implemented by formatters that can split their output
+// into several files in a directory.
+type DirFormatter interface {
+	FormatDir(dir string, g *Graph) error
+}
+
+// Options configures how a graph is rendered.
+type Options struct {
+	// Color enables ANSI colors and Emoji the emoji that mark table
+	// sections. Both are off by default so output can be embedded and
+	// compared; the CLI turns them on for terminals.
+	Color bool
+	Emoji bool
+	// Width is the terminal width tables are fitted to. Zero leaves them
+	// as wide as their content.
+	Width int
+	// Now is the reference time for relative ages. When zero, ages are
+	// measured against the time the graph was collected.
+	Now time.Time
+	// SortBy orders table rows by a column header such as AGE or by a
+	// JSONPath expression over the row, such as .details.restarts.
+	SortBy string
+	// ShowLabels adds a LABELS column and LabelColumns one column per
+	// label key to tables.
+	ShowLabels   bool
+	LabelColumns []string
+	// Highlight marks the nodes with these IDs in tables, such as the
+	// ones that changed since the previous frame of inspect --watch.
+	Highlight map[string]bool
+}
+
+// now returns the reference time for ages in g.
+func (o Options) now(g *Graph) time.Time {
+	if !o.Now.IsZero() {
+		return o.Now
+	}
+	if !g.Metadata.CollectedAt.IsZero() {
+		return g.Metadata.CollectedAt
+	}
+	return time.Now()
+}
+
+// paint returns s in the given color attributes when colors are enabled.
+func (o Options) paint(s string, attrs ...color.Attribute) string {
+	if !o.Color {
+		return s
+	}
+	c := color.New(attrs...)
+	// fatih/color disables itself when stdout is not a terminal; the caller
+	// has already decided, and w may not be stdout at all.
+	c.EnableColor()
+	return c.Sprint(s)
+}
+
+// icon returns emoji followed by a space when emoji are enabled.
+func (o Options) icon(emoji string) string {
+	if !o.Emoji {
+		return ""
+	}
+	return emoji + " "
+}
+
+// NewFormatter returns the formatter for an -o value such as "json" or
+// "jsonpath={.root}". Names that are not registered are looked up as
+// external formatters on PATH.
+func NewFormatter(spec string, opts Options) (Formatter, error) {
+	name, arg, hasArg := strings.Cut(spec, "=")
+	info, ok := LookupFormatter(name)
+	if !ok {
+		if path, err := lookExternalFormatter(name); err == nil {
+			return NewExecFormatter(name, path, arg, opts), nil
+		}
+		return nil, fmt.Errorf("unknown output format %q, must be one of: %s", spec, strings.Join(FormatterUsages(), ", "))
+	}
+	switch {
+	case info.Arg != "" && arg == "":
+		return nil, fmt.Errorf("%s format specified but no %s given", name, strings.ToLower(info.Arg))
+	case info.Arg == "" && hasArg:
+		return nil, fmt.Errorf("%s format does not take an argument", name)
+	}
+	return info.New(arg, opts)
+}
+
+func init() {
+	builtins := []FormatterInfo{
+		{Name: "table", Description: "Colored tables of the root, each resource type and the relationships", New: func(_ string, opts Options) (Formatter, error) {
+			return NewTableFormatter(opts), nil
+		}},
+		{Name: "tree", Description: "Same as table", New: func(_ string, opts Options) (Formatter, error) {
+			return NewTableFormatter(opts), nil
+		}},
+		{Name: "wide", Description: "Tables with every detail in its own column", New: func(_ string, opts Options) (Formatter, error) {
+			return NewWideTableFormatter(opts), nil
+		}},
+		{Name: "json", Description: "The graph document as JSON", New: func(string, Options) (Formatter, error) {
+			return NewJSONFormatter(), nil
+		}},
+		{Name: "yaml", Description: "The graph document as YAML", New: func(string, Options) (Formatter, error) {
+			return NewYAMLFormatter(), nil
+		}},
+		{Name: "name", Description: "One kind/name per line, for piping into kubectl", New: func(string, Options) (Formatter, error) {
+			return NewNameFormatter(), nil
+		}},
+		{Name: "html", Description: "A standalone interactive HTML page", New: func(_ string, opts Options) (Formatter, error) {
+			return NewHTMLFormatter(opts), nil
+		}},
+		{Name: "svg", Description: "A standalone SVG image", New: func(_ string, opts Options) (Formatter, error) {
+			return NewSVGFormatter(opts), nil
+		}},
+		{Name: "mermaid", Description: "A Mermaid flowchart", New: func(string, Options) (Formatter, error) {
+			return NewMermaidFormatter(), nil
+		}},
+		{Name: "dot", Description: "A Graphviz DOT digraph", New: func(string, Options) (Formatter, error) {
+			return NewDotFormatter(), nil
+		}},
+		{Name: "markdown", Description: "An incident report in Markdown", New: func(_ string, opts Options) (Formatter, error) {
+			return NewMarkdownFormatter(opts), nil
+		}},
+		{Name: "csv", Description: "Nodes and edges as CSV, or nodes.csv and edges.csv with --output-dir", New: func(_ string, opts Options) (Formatter, error) {
+			return NewCSVFormatter(opts), nil
+		}},
+		{Name: "graphml", Description: "GraphML for yEd, Gephi and NetworkX", New: func(string, Options) (Formatter, error) {
+			return NewGraphMLFormatter(), nil
+		}},
+		{Name: "gexf", Description: "GEXF for Gephi", New: func(string, Options) (Formatter, error) {
+			return NewGEXFFormatter(), nil
+		}},
+		{Name: "cypher", Description: "Cypher statements for Neo4j", New: func(string, Options) (Formatter, error) {
+			return NewCypherFormatter(), nil
+		}},
+		{Name: "custom-columns", Arg: "SPEC", Description: "A table of HEADER:JSONPATH columns", New: func(arg string, opts Options) (Formatter, error) {
+			return NewCustomColumnsFormatter(arg, opts)
+		}},
+		{Name: "custom-columns-file", Arg: "FILE", Description: "custom-columns read from a file", New: func(arg string, opts Options) (Formatter, error) {
+			text, err := readTemplateFile(arg)
+			if err != nil {
+				return nil, err
+			}
+			return NewCustomColumnsFormatterFromFile(text, opts)
+		}},
+		{Name: "go-template", Arg: "TEMPLATE", Description: "A Go template over the graph document", New: func(arg string, _ Options) (Formatter, error) {
+			return NewTemplateFormatter(arg)
+		}},
+		{Name: "go-template-file", Arg: "FILE", Description: "go-template read from a file", New: func(arg string, _ Options) (Formatter, error) {
+			text, err := readTemplateFile(arg)
+			if err != nil {
+				return nil, err
+			}
+			return NewTemplateFormatter(text)
+		}},
+		{Name: "jsonpath", Arg: "EXPR", Description: "A JSONPath expression over the graph document", New: func(arg string, _ Options) (Formatter, error) {
+			return NewJSONPathFormatter(arg)
+		}},
+		{Name: "jsonpath-file", Arg: "FILE", Description: "jsonpath read from a file", New: func(arg string, _ Options) (Formatter, error) {
+			text, err := readTemplateFile(arg)
+			if err != nil {
+				return nil, err
+			}
+			return NewJSONPathFormatter(text)
+		}},
+	}
+	for _, info := range builtins {
+		RegisterFormatter(info)
+	}
 }
 
-func NewFormatter(formatType string) Formatter {
-	switch formatType {
-	case "json":
-		return NewJSONFormatter()
-	default:
-		return NewTableFormatter()
+func readTemplateFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("error reading template %s: %w", path, err)
 	}
+	return string(data), nil
 }
@@ -0,0 +1,87 @@
+package format
+
+import (
+	"fmt"
+	"sync"
+)
+
+// FormatterFactory builds a formatter from the argument given after '=' in
+// an -o value, empty when there is none, and the shared options.
+type FormatterFactory func(arg string, opts Options) (Formatter, error)
+
+// FormatterInfo describes an output format.
+type FormatterInfo struct {
+	// Name is what -o selects the format with.
+	Name string
+	// Arg names the argument the format requires after '=', such as
+	// TEMPLATE. Formats without one take no argument.
+	Arg         string
+	Description string
+	New         FormatterFactory
+}
+
+// Usage returns the -o value form of the format, such as json or
+// jsonpath=EXPR.
+func (fi FormatterInfo) Usage() string {
+	if fi.Arg == "" {
+		return fi.Name
+	}
+	return fi.Name + "=" + fi.Arg
+}
+
+var registry = struct {
+	sync.RWMutex
+	byName map[string]FormatterInfo
+	order  []string
+}{byName: make(map[string]FormatterInfo)}
+
+// RegisterFormatter makes a format available to NewFormatter. Programs
+// embedding the package can add their own formats this way; formats are
+// listed in the order they were registered. It panics if the name is
+// already taken.
+func RegisterFormatter(info FormatterInfo) {
+	registry.Lock()
+	defer registry.Unlock()
+	if info.Name == "" || info.New == nil {
+		panic("format: RegisterFormatter needs a name and a factory")
+	}
+	if _, ok := registry.byName[info.Name]; ok {
+		panic(fmt.Sprintf("format: formatter %q registered twice", info.Name))
+	}
+	registry.byName[info.Name] = info
+	registry.order = append(registry.order, info.Name)
+}
+
+// LookupFormatter returns the registered format with the given name.
+func LookupFormatter(name string) (FormatterInfo, bool) {
+	registry.RLock()
+	defer registry.RUnlock()
+	info, ok := registry.byName[name]
+	return info, ok
+}
+
+// Formatters returns the registered formats in registration order.
+func Formatters() []FormatterInfo {
+	registry.RLock()
+	defer registry.RUnlock()
+	infos := make([]FormatterInfo, 0, len(registry.order))
+	for _, name := range registry.order {
+		infos = append(infos, registry.byName[name])
+	}
+	return infos
+}
+
+// FormatterUsages returns the -o values accepted, the registered formats
+// followed by the external formatters found on PATH.
+func FormatterUsages() []string {
+	var usages []string
+	for _, info := range Formatters() {
+		usages = append(usages, info.Usage())
+	}
+	for _, name := range ExternalFormatters() {
+		if _, ok := LookupFormatter(name); !ok {
+			usages = append(usages, name+"[=ARG]")
+		}
+	}
+	return usages
+}
@@ -0,0 +1,84 @@
+package format
+
+import (
+	"bytes"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+)
+
+type upperFormatter struct{ arg string }
+
+func (uf upperFormatter) Format(w io.Writer, g *Graph) error {
+	_, err := io.WriteString(w, strings.ToUpper(uf.arg+" "+g.Root.Name))
+	return err
+}
+
+func TestRegistry(t *testing.T) {
+	info, ok := LookupFormatter("jsonpath")
+	if !ok || info.Usage() != "jsonpath=EXPR" {
+		t.Errorf("LookupFormatter(jsonpath) = %q, %v, want jsonpath=EXPR", info.Usage(), ok)
+	}
+	if _, ok := LookupFormatter("kubectl-graph-test-missing"); ok {
+		t.Error("LookupFormatter found a format that is not registered")
+	}
+	var names []string
+	for _, info := range Formatters() {
+		names = append(names, info.Name)
+	}
+	if !slices.Equal(names[:3], []string{"table", "tree", "wide"}) {
+		t.Errorf("Formatters starts with %v, want the registration order", names)
+	}
+
+	// The registry is global, so the format is only registered on the
+	// first of several runs.
+	if _, ok := LookupFormatter("test-upper"); !ok {
+		RegisterFormatter(FormatterInfo{Name: "test-upper", Arg: "PREFIX", Description: "Upper case", New: func(arg string, _ Options) (Formatter, error) {
+			return upperFormatter{arg: arg}, nil
+		}})
+	}
+	if names := Formatters(); names[len(names)-1].Name != "test-upper" {
+		t.Errorf("the last format is %s, want the one registered last", names[len(names)-1].Name)
+	}
+	if !slices.Contains(FormatterUsages(), "test-upper=PREFIX") {
+		t.Errorf("FormatterUsages = %v, want test-upper=PREFIX", FormatterUsages())
+	}
+	f, err := NewFormatter("test-upper=graph=of", Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := f.Format(&buf, exportGraph()); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := buf.String(), "GRAPH=OF IMAGENET"; got != want {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("registering test-upper twice did not panic")
+		}
+	}()
+	RegisterFormatter(FormatterInfo{Name: "test-upper", New: func(string, Options) (Formatter, error) { return nil, nil }})
+}
+
+func TestNewFormatterErrors(t *testing.T) {
+	tests := []struct {
+		spec string
+		want string
+	}{
+		{"jsonpath", "jsonpath format specified but no expr given"},
+		{"jsonpath=", "jsonpath format specified but no expr given"},
+		{"json=pretty", "json format does not take an argument"},
+		{"kubectl-graph-test-missing", `unknown output format "kubectl-graph-test-missing", must be one of: table, tree, wide, json,`},
+		{"../json", `unknown output format "../json"`},
+	}
+	for _, tt := range tests {
+		_, err := NewFormatter(tt.spec, Options{})
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("NewFormatter(%q) = %v, want an error containing %q", tt.spec, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,200 @@
+package format
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// JSONSchema is the subset of JSON Schema (draft 2020-12) needed to describe
+// a Document.
+type JSONSchema struct {
+	Schema               string                 `json:"$schema,omitempty"`
+	Title                string                 `json:"title,omitempty"`
+	Description          string                 `json:"description,omitempty"`
+	Type                 interface{}            `json:"type,omitempty"`
+	Format               string                 `json:"format,omitempty"`
+	Const                string                 `json:"const,omitempty"`
+	Properties           map[string]*JSONSchema `json:"properties,omitempty"`
+	Required             []string               `json:"required,omitempty"`
+	AdditionalProperties interface{}            `json:"additionalProperties,omitempty"`
+	Items                *JSONSchema            `json:"items,omitempty"`
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// Schema generates the JSON Schema of the serialized graph from the Document
+// types, so the two cannot drift apart.
+func Schema() *JSONSchema {
+	s := schemaFor(reflect.TypeOf(Document{}))
+	s.Schema = "https://json-schema.org/draft/2020-12/schema"
+	s.Title = DocumentKind
+	s.Description = "A Kubernetes resource and its dependencies, as collected by kubectl-graph."
+	s.Properties["apiVersion"].Const = DocumentAPIVersion
+	s.Properties["kind"].Const = DocumentKind
+	return s
+}
+
+func schemaFor(t reflect.Type) *JSONSchema {
+	if t == timeType {
+		return &JSONSchema{Type: "string", Format: "date-time"}
+	}
+	switch t.Kind() {
+	case reflect.Ptr:
+		return schemaFor(t.Elem())
+	case reflect.String:
+		return &JSONSchema{Type: "string"}
+	case reflect.Bool:
+		return &JSONSchema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &JSONSchema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &JSONSchema{Type: "number"}
+	case reflect.Slice, reflect.Array:
+		return &JSONSchema{Type: "array", Items: schemaFor(t.Elem())}
+	case reflect.Map:
+		return &JSONSchema{Type: "object", AdditionalProperties: schemaFor(t.Elem())}
+	case reflect.Interface:
+		// Detail values are the only interface fields in a Document.
+		return &JSONSchema{Type: []string{"string", "integer"}}
+	case reflect.Struct:
+		s := &JSONSchema{
+			Type:                 "object",
+			Properties:           make(map[string]*JSONSchema),
+			Required:             make([]string, 0),
+			AdditionalProperties: false,
+		}
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			name, opts, _ := strings.Cut(field.Tag.Get("json"), ",")
+			if name == "-" || !field.IsExported() {
+				continue
+			}
+			if name == "" {
+				name = field.Name
+			}
+			prop := schemaFor(field.Type)
+			prop.Description = field.Tag.Get("description")
+			s.Properties[name] = prop
+			if !strings.Contains(opts, "omitempty") {
+				s.Required = append(s.Required, name)
+			}
+		}
+		sort.Strings(s.Required)
+		return s
+	}
+	return &JSONSchema{}
+}
+
+// ValidateDocument checks that data is a JSON document matching Schema.
+func ValidateDocument(data []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var value interface{}
+	if err := dec.Decode(&value); err != nil {
+		return fmt.Errorf("invalid graph document: %w", err)
+	}
+	if err := Schema().validate(value, "$"); err != nil {
+		return fmt.Errorf("invalid graph document: %w", err)
+	}
+	return nil
+}
+
+func (s *JSONSchema) validate(value interface{}, path string) error {
+	if !s.typeMatches(value) {
+		return fmt.Errorf("%s: expected %v, got %s", path, s.Type, jsonTypeOf(value))
+	}
+	if s.Const != "" && value != s.Const {
+		return fmt.Errorf("%s: expected %q, got %v", path, s.Const, value)
+	}
+	if s.Format == "date-time" {
+		if str, ok := value.(string); ok {
+			if _, err := time.Parse(time.RFC3339, str); err != nil {
+				return fmt.Errorf("%s: %q is not an RFC 3339 time", path, str)
+			}
+		}
+	}
+	switch v := value.(type) {
+	case []interface{}:
+		if s.Items != nil {
+			for i, item := range v {
+				if err := s.Items.validate(item, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+					return err
+				}
+			}
+		}
+	case map[string]interface{}:
+		for _, name := range s.Required {
+			if _, ok := v[name]; !ok {
+				return fmt.Errorf("%s: missing required field %q", path, name)
+			}
+		}
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			child := path + "." + k
+			if prop, ok := s.Properties[k]; ok {
+				if err := prop.validate(v[k], child); err != nil {
+					return err
+				}
+				continue
+			}
+			switch extra := s.AdditionalProperties.(type) {
+			case bool:
+				if !extra {
+					return fmt.Errorf("%s: unknown field", child)
+				}
+			case *JSONSchema:
+				if err := extra.validate(v[k], child); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func (s *JSONSchema) typeMatches(value interface{}) bool {
+	actual := jsonTypeOf(value)
+	switch t := s.Type.(type) {
+	case string:
+		return t == actual || t == "number" && actual == "integer"
+	case []string:
+		for _, candidate := range t {
+			if candidate == actual {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+func jsonTypeOf(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case json.Number:
+		if _, err := v.Int64(); err == nil {
+			return "integer"
+		}
+		return "number"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return fmt.Sprintf("%T", value)
+}
@@ -0,0 +1,173 @@
+package format
+
+import (
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// SVGFormatter draws the graph as a standalone SVG image using
+// LayeredLayout, so no Graphviz installation is needed.
+type SVGFormatter struct {
+	opts   Options
+	layout LayoutConfig
+}
+
+func NewSVGFormatter(opts Options) *SVGFormatter {
+	return &SVGFormatter{opts: opts, layout: DefaultLayoutConfig}
+}
+
+type statusPalette struct {
+	stroke, fill string
+}
+
+var healthPalettes = map[Health]statusPalette{
+	HealthOK:      {stroke: "#1a7f37", fill: "#dafbe1"},
+	HealthWarning: {stroke: "#9a6700", fill: "#fff8c5"},
+	HealthFailing: {stroke: "#cf222e", fill: "#ffebe9"},
+	HealthUnknown: {stroke: "#57606a", fill: "#f6f8fa"},
+}
+
+func statusPaletteFor(status string) statusPalette {
+	return healthPalettes[StatusHealth(status)]
+}
+
+var resourceTypeBadges = map[ResourceType]string{
+	ResourceTypeDataset:     "DATA",
+	ResourceTypeRuntime:     "RT",
+	ResourceTypePod:         "POD",
+	ResourceTypePVC:         "PVC",
+	ResourceTypePV:          "PV",
+	ResourceTypeService:     "SVC",
+	ResourceTypeDeployment:  "DEPLOY",
+	ResourceTypeStatefulSet: "STS",
+	ResourceTypeDaemonSet:   "DS",
+	ResourceTypeJob:         "JOB",
+	ResourceTypeConfigMap:   "CM",
+	ResourceTypeSecret:      "SECRET",
+	ResourceTypeNode:        "NODE",
+}
+
+func (sf *SVGFormatter) Format(out io.Writer, g *Graph) error {
+	w := bufio.NewWriter(out)
+	sf.write(w, g)
+	return w.Flush()
+}
+
+func (sf *SVGFormatter) write(w io.Writer, g *Graph) {
+	cfg := sf.layout
+	layout := LayeredLayout(g, cfg)
+
+	fmt.Fprintf(w, `<svg xmlns="http://www.w3.org/2000/svg" width="%.0f" height="%.0f" viewBox="0 0 %.0f %.0f" font-family="Helvetica, Arial, sans-serif">`+"\n",
+		layout.Width, layout.Height, layout.Width, layout.Height)
+	title := "Resources"
+	if g.Root != nil {
+		title = fmt.Sprintf("%s: %s", g.Root.Type, g.Root.Name)
+	}
+	fmt.Fprintf(w, "<title>%s</title>\n", escapeXML(title))
+	fmt.Fprint(w, `<defs><marker id="arrow" viewBox="0 0 10 10" refX="10" refY="5" markerWidth="7" markerHeight="7" orient="auto-start-reverse"><path d="M 0 0 L 10 5 L 0 10 z" fill="#6e7781"/></marker></defs>`+"\n")
+	fmt.Fprintf(w, `<rect width="100%%" height="100%%" fill="#ffffff"/>`+"\n")
+
+	fmt.Fprint(w, "<g class=\"edges\">\n")
+	for _, e := range layout.Edges {
+		var d strings.Builder
+		for i, p := range e.Points {
+			if i == 0 {
+				fmt.Fprintf(&d, "M%.1f %.1f", p.X, p.Y)
+			} else {
+				fmt.Fprintf(&d, " L%.1f %.1f", p.X, p.Y)
+			}
+		}
+		fmt.Fprint(w, "<g>")
+		fmt.Fprintf(w, "<title>%s</title>", escapeXML(edgeTitle(e.Edge)))
+		dash := ""
+		if e.Edge.Planned {
+			dash = ` stroke-dasharray="5 3"`
+		}
+		fmt.Fprintf(w, `<path d="%s" fill="none" stroke="#6e7781" stroke-width="1.2"%s marker-end="url(#arrow)"/>`, d.String(), dash)
+		mid := labelPoint(e.Points)
+		fmt.Fprintf(w, `<text x="%.1f" y="%.1f" font-size="10" fill="#57606a" text-anchor="middle" paint-order="stroke" stroke="#ffffff" stroke-width="3">%s</text>`,
+			mid.X, mid.Y, escapeXML(string(e.Edge.Type)))
+		fmt.Fprint(w, "</g>\n")
+	}
+	fmt.Fprint(w, "</g>\n")
+
+	fmt.Fprint(w, "<g class=\"nodes\">\n")
+	for _, n := range layout.Nodes {
+		res := n.Resource
+		palette := statusPaletteFor(res.Status)
+		fmt.Fprintf(w, `<g transform="translate(%.1f %.1f)">`, n.X, n.Y)
+		fmt.Fprintf(w, "<title>%s</title>", escapeXML(fmt.Sprintf("%s (%s)", res.ID(), res.Status)))
+		fmt.Fprint(w, nodeShape(res.Type, cfg.NodeWidth, cfg.NodeHeight, palette))
+		badge := resourceTypeBadges[res.Type]
+		if badge == "" {
+			badge = strings.ToUpper(string(res.Type))
+		}
+		fmt.Fprintf(w, `<text x="12" y="19" font-size="9" font-weight="bold" fill="%s">%s</text>`, palette.stroke, escapeXML(badge))
+		fmt.Fprintf(w, `<text x="%.1f" y="19" font-size="10" fill="#57606a">%s</text>`,
+			12+float64(len(badge))*7, escapeXML(Truncate(res.Status, 22)))
+		fmt.Fprintf(w, `<text x="12" y="36" font-size="12" fill="#1f2328">%s</text>`, escapeXML(Truncate(res.Name, 26)))
+		fmt.Fprint(w, "</g>\n")
+	}
+	fmt.Fprint(w, "</g>\n</svg>\n")
+}
+
+// nodeShape returns the outline for a resource type: a cylinder for
+// datasets, a hexagon for runtimes, stacked boxes for workload
+// controllers, a folded page for claims, a slanted box for volumes and a
+// pill for services.
+func nodeShape(t ResourceType, w, h float64, p statusPalette) string {
+	style := fmt.Sprintf(`fill="%s" stroke="%s" stroke-width="1.5"`, p.fill, p.stroke)
+	switch t {
+	case ResourceTypeDataset:
+		ry := 6.0
+		return fmt.Sprintf(`<path d="M0 %.1f A%.1f %.1f 0 0 1 %.1f %.1f L%.1f %.1f A%.1f %.1f 0 0 1 0 %.1f Z" %s/><path d="M0 %.1f A%.1f %.1f 0 0 0 %.1f %.1f" fill="none" stroke="%s" stroke-width="1.5"/>`,
+			ry, w/2, ry, w, ry, w, h-ry, w/2, ry, h-ry, style, ry, w/2, ry, w, ry, p.stroke)
+	case ResourceTypeRuntime:
+		c := 10.0
+		return fmt.Sprintf(`<polygon points="%.1f,0 %.1f,0 %.1f,%.1f %.1f,%.1f %.1f,%.1f 0,%.1f" %s/>`,
+			c, w-c, w, h/2, w-c, h, c, h, h/2, style)
+	case ResourceTypeDeployment, ResourceTypeStatefulSet, ResourceTypeDaemonSet, ResourceTypeJob:
+		return fmt.Sprintf(`<rect x="4" y="-4" width="%.1f" height="%.1f" rx="3" %s/><rect width="%.1f" height="%.1f" rx="3" %s/>`,
+			w, h, style, w, h, style)
+	case ResourceTypePVC:
+		f := 10.0
+		return fmt.Sprintf(`<path d="M0 0 L%.1f 0 L%.1f %.1f L%.1f %.1f L0 %.1f Z" %s/><path d="M%.1f 0 L%.1f %.1f L%.1f %.1f" fill="none" stroke="%s" stroke-width="1.5"/>`,
+			w-f, w, f, w, h, h, style, w-f, w-f, f, w, f, p.stroke)
+	case ResourceTypePV:
+		s := 8.0
+		return fmt.Sprintf(`<polygon points="%.1f,0 %.1f,0 %.1f,%.1f 0,%.1f" %s/>`, s, w, w-s, h, h, style)
+	case ResourceTypeService:
+		return fmt.Sprintf(`<rect width="%.1f" height="%.1f" rx="%.1f" %s/>`, w, h, h/2, style)
+	default:
+		return fmt.Sprintf(`<rect width="%.1f" height="%.1f" rx="6" %s/>`, w, h, style)
+	}
+}
+
+// labelPoint returns the midpoint of the middle segment of a route.
+func labelPoint(points []Point) Point {
+	if len(points) == 0 {
+		return Point{}
+	}
+	if len(points) == 1 {
+		return points[0]
+	}
+	i := (len(points) - 1) / 2
+	return Point{(points[i].X + points[i+1].X) / 2, (points[i].Y + points[i+1].Y) / 2}
+}
+
+func edgeTitle(e Edge) string {
+	title := fmt.Sprintf("%s -[%s]-> %s", e.From.ID(), e.Type, e.To.ID())
+	if e.Evidence != "" {
+		title += ": " + e.Evidence
+	}
+	return title
+}
+
+func escapeXML(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
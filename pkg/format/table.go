@@ -1,105 +1,247 @@
 package format
 
 import (
+	"bufio"
 	"fmt"
+	"io"
+	"slices"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/fatih/color"
 )
 
-type TableFormatter struct{}
+type TableFormatter struct {
+	opts Options
+	// wide shows every detail in its own column instead of the primary
+	// details in a single DETAILS column.
+	wide bool
+}
+
+func NewTableFormatter(opts Options) *TableFormatter {
+	return &TableFormatter{opts: opts}
+}
 
-func NewTableFormatter() *TableFormatter {
-	return &TableFormatter{}
+// NewWideTableFormatter returns the table used by -o wide.
+func NewWideTableFormatter(opts Options) *TableFormatter {
+	return &TableFormatter{opts: opts, wide: true}
 }
-func (tf *TableFormatter) Format(g *Graph) error {
-	if g.Root == nil {
-		return fmt.Errorf("no root resource found")
+
+func (tf *TableFormatter) Format(out io.Writer, g *Graph) error {
+	now := tf.opts.now(g)
+	tables := []struct {
+		title     string
+		resources []*Resource
+		columns   []column
+		sortKey   func(res *Resource) interface{}
+	}{
+		{title: "Runtime", resources: g.Resources[ResourceTypeRuntime]},
+		{title: "Workloads", resources: g.Workloads()},
+		{title: "Pods", resources: g.Resources[ResourceTypePod]},
+		{title: "PersistentVolumeClaims", resources: g.Resources[ResourceTypePVC]},
+		{title: "PersistentVolumes", resources: g.Resources[ResourceTypePV]},
+		{title: "Services", resources: g.Resources[ResourceTypeService]},
+		{title: "ConfigMaps", resources: g.Resources[ResourceTypeConfigMap]},
+		{title: "Secrets", resources: g.Resources[ResourceTypeSecret]},
+		{title: "Nodes", resources: g.Resources[ResourceTypeNode]},
+	}
+	// A sort column only has to exist in one of the tables; the others keep
+	// their order.
+	var headers []string
+	sortable := tf.opts.SortBy == ""
+	for i := range tables {
+		t := &tables[i]
+		t.columns = tf.columns(t.resources, now)
+		key, err := sortKeyFunc(t.columns, tf.opts.SortBy, now)
+		if err != nil {
+			return err
+		}
+		t.sortKey = key
+		sortable = sortable || key != nil
+		for _, c := range t.columns {
+			if !slices.Contains(headers, c.header) {
+				headers = append(headers, c.header)
+			}
+		}
 	}
-	printHeader(g.Root)
-	printResourceTable("Runtime", g.Resources[ResourceTypeRuntime])
-	printResourceTable("Pods", g.Resources[ResourceTypePod])
-	printResourceTable("PersistentVolumeClaims", g.Resources[ResourceTypePVC])
-	printResourceTable("Services", g.Resources[ResourceTypeService])
+	if !sortable {
+		return unknownSortColumn(tf.opts.SortBy, headers)
+	}
+
+	w := bufio.NewWriter(out)
+	// Query results have no root when it did not match.
+	if g.Root != nil {
+		tf.printHeader(w, g.Root, now)
+	}
+	for _, t := range tables {
+		tf.printResourceTable(w, t.title, sortResources(t.resources, t.sortKey), t.columns)
+	}
+	tf.printEdgeTable(w, g.Edges)
+
+	return w.Flush()
+}
 
-	return nil
+// columns returns the columns of a resource table: NAME, STATUS and AGE,
+// then either the primary details or, for -o wide, one column per detail,
+// then the label columns requested with --show-labels and -L.
+func (tf *TableFormatter) columns(resources []*Resource, now time.Time) []column {
+	var columns []column
+	if tf.opts.Highlight != nil {
+		columns = append(columns, column{header: "", width: 1, value: tf.highlightMarker, colorize: tf.paintMarker})
+	}
+	columns = append(columns, []column{
+		{header: "NAME", width: 40, priority: 1, value: func(res *Resource) string { return res.Name }},
+		{header: "STATUS", width: 15, value: func(res *Resource) string { return res.Status }, colorize: tf.opts.colorizeStatus},
+		{
+			header:    "AGE",
+			width:     10,
+			value:     func(res *Resource) string { return ResourceAge(res, now) },
+			sortValue: func(res *Resource) interface{} { return int64(res.Age(now)) },
+		},
+	}...)
+	if tf.wide {
+		columns = append(columns, detailColumns(resources)...)
+	} else {
+		columns = append(columns, column{header: "DETAILS", priority: 2, value: formatDetails})
+	}
+	return append(columns, labelColumns(tf.opts.ShowLabels, tf.opts.LabelColumns)...)
 }
 
-func printHeader(root *Resource) {
-	fmt.Printf("\n")
-	color.New(color.FgCyan, color.Bold).Printf("📦 %s: %s\n", root.Type, root.Name)
-	fmt.Printf("   Namespace: %s\n", root.Namespace)
-	fmt.Printf("   Status: %s\n", colorizeStatus(root.Status))
-	fmt.Printf("   Age: %s\n", formatAge(root.Age))
+func (tf *TableFormatter) printHeader(w io.Writer, root *Resource, now time.Time) {
+	fmt.Fprintf(w, "\n")
+	title := tf.opts.paint(fmt.Sprintf("%s%s: %s", tf.opts.icon("📦"), root.Type, root.Name), color.FgCyan, color.Bold)
+	if marker := tf.highlightMarker(root); marker != "" {
+		title += " " + tf.paintMarker(marker)
+	}
+	fmt.Fprintln(w, title)
+	fmt.Fprintf(w, "   Namespace: %s\n", root.Namespace)
+	fmt.Fprintf(w, "   Status: %s\n", tf.opts.colorizeStatus(root.Status))
+	fmt.Fprintf(w, "   Age: %s\n", ResourceAge(root, now))
+	if root.DeletionTimestamp != nil {
+		fmt.Fprintf(w, "   Deletion requested: %s ago\n", FormatAge(now.Sub(*root.DeletionTimestamp)))
+	}
 
 	if len(root.Details) > 0 {
-		fmt.Printf("   Details:\n")
-		for k, v := range root.Details {
-			fmt.Printf("     %s: %v\n", k, v)
+		fmt.Fprintf(w, "   Details:\n")
+		for _, d := range sortedDetails(root.Details) {
+			fmt.Fprintf(w, "     %s: %s\n", d.Key, d.String())
 		}
 	}
-	fmt.Printf("\n")
+	fmt.Fprintf(w, "\n")
+}
+
+// highlightMarker returns the marker of highlighted resources.
+func (tf *TableFormatter) highlightMarker(res *Resource) string {
+	if tf.opts.Highlight[res.ID()] {
+		return "*"
+	}
+	return ""
+}
+
+func (tf *TableFormatter) paintMarker(s string) string {
+	return tf.opts.paint(s, color.FgMagenta, color.Bold)
+}
+
+func (tf *TableFormatter) printSectionTitle(w io.Writer, emoji, title string, count int) {
+	fmt.Fprintln(w, tf.opts.paint(fmt.Sprintf("%s%s (%d)", tf.opts.icon(emoji), title, count), color.FgYellow, color.Bold))
+	fmt.Fprintln(w)
 }
 
-func printResourceTable(title string, resources []*Resource) {
+func (tf *TableFormatter) printResourceTable(w io.Writer, title string, resources []*Resource, columns []column) {
 	if len(resources) == 0 {
 		return
 	}
 
-	color.New(color.FgYellow, color.Bold).Printf("🔧 %s (%d)\n", title, len(resources))
-	fmt.Println()
-	fmt.Printf("  %-40s %-15s %-10s %s\n", "NAME", "STATUS", "AGE", "DETAILS")
-	fmt.Printf("  %s\n", strings.Repeat("-", 100))
-	for _, res := range resources {
-		details := formatDetails(res)
-		fmt.Printf("  %-40s %-15s %-10s %s\n",
-			truncate(res.Name, 40),
-			colorizeStatus(res.Status),
-			formatAge(res.Age),
-			details,
-		)
+	tf.printSectionTitle(w, "🔧", title, len(resources))
+	printColumns(w, "  ", columns, resourceRows(columns, resources), true, tf.opts.Width)
+	fmt.Fprintln(w)
+}
+
+func (tf *TableFormatter) printEdgeTable(w io.Writer, edges []Edge) {
+	if len(edges) == 0 {
+		return
 	}
 
-	fmt.Println()
+	tf.printSectionTitle(w, "🔗", "Relationships", len(edges))
+	rows := make([][]string, len(edges))
+	for i, edge := range edges {
+		evidence := edge.Evidence
+		if edge.Planned {
+			evidence += " (not yet present)"
+		}
+		rows[i] = []string{resourceRef(edge.From), string(edge.Type), resourceRef(edge.To), evidence}
+	}
+	printColumns(w, "  ", edgeColumns, rows, true, tf.opts.Width)
+	fmt.Fprintln(w)
+}
+
+var edgeColumns = []column{
+	{header: "FROM", width: 40, priority: 1},
+	{header: "TYPE", width: 16},
+	{header: "TO", width: 40, priority: 1},
+	{header: "EVIDENCE", priority: 2},
 }
 
+func resourceRef(res *Resource) string {
+	return fmt.Sprintf("%s/%s", res.Type, res.Name)
+}
+
+// formatDetails joins the non-empty primary details of a resource.
 func formatDetails(res *Resource) string {
-	switch res.Type {
-	case ResourceTypePod:
-		if restarts, ok := res.Details["restarts"].(int32); ok && restarts > 0 {
-			return fmt.Sprintf("restarts: %d", restarts)
-		}
-	case ResourceTypePVC:
-		if capacity, ok := res.Details["capacity"].(string); ok && capacity != "" {
-			return fmt.Sprintf("capacity: %s", capacity)
-		}
-	case ResourceTypeService:
-		if ports, ok := res.Details["ports"].(string); ok && ports != "" {
-			return fmt.Sprintf("ports: %s", ports)
-		}
-	case ResourceTypeRuntime:
-		if rType, ok := res.Details["type"].(string); ok {
-			return rType
+	parts := make([]string, 0, len(res.Details))
+	for _, d := range sortedDetails(res.Details) {
+		if d.Priority != DetailPriorityPrimary || d.IsZero() {
+			continue
 		}
+		parts = append(parts, fmt.Sprintf("%s: %s", d.Key, d.String()))
 	}
-	return ""
+	return strings.Join(parts, ", ")
 }
 
-func colorizeStatus(status string) string {
-	switch status {
-	case "Running", "Bound", "Active", "Ready":
-		return color.GreenString(status)
-	case "Pending", "Creating":
-		return color.YellowString(status)
-	case "Failed", "Error", "CrashLoopBackOff":
-		return color.RedString(status)
-	default:
-		return status
+// sortedDetails orders details by priority, keeping the converter's order
+// within a priority.
+func sortedDetails(details []Detail) []Detail {
+	sorted := make([]Detail, len(details))
+	copy(sorted, details)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Priority < sorted[j].Priority
+	})
+	return sorted
+}
+
+var healthColors = map[Health]color.Attribute{
+	HealthOK:      color.FgGreen,
+	HealthWarning: color.FgYellow,
+	HealthFailing: color.FgRed,
+}
+
+func (o Options) colorizeStatus(status string) string {
+	if c, ok := healthColors[StatusHealth(status)]; ok {
+		return o.paint(status, c)
+	}
+	return status
+}
+
+// unknownAge is shown for the age of resources without a creation time,
+// such as those read from manifests, as kubectl does.
+const unknownAge = "<unknown>"
+
+// ResourceAge formats the age of res at now, or "<unknown>" when its
+// creation time is not known.
+func ResourceAge(res *Resource, now time.Time) string {
+	if res.CreationTimestamp.IsZero() {
+		return unknownAge
 	}
+	return FormatAge(res.Age(now))
 }
 
-func formatAge(duration time.Duration) string {
+// FormatAge prints a duration in its two largest units, as the AGE
+// columns do.
+func FormatAge(duration time.Duration) string {
+	if duration < 0 {
+		duration = 0
+	}
 	days := int(duration.Hours() / 24)
 	hours := int(duration.Hours()) % 24
 	minutes := int(duration.Minutes()) % 60
@@ -112,10 +254,3 @@ func formatAge(duration time.Duration) string {
 		return fmt.Sprintf("%dm", minutes)
 	}
 }
-
-func truncate(s string, max int) string {
-	if len(s) <= max {
-		return s
-	}
-	return s[:max-3] + "..."
-}
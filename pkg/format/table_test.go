@@ -0,0 +1,27 @@
+package format
+
+import (
+	"testing"
+	"time"
+)
+
+func TestResourceAge(t *testing.T) {
+	now := time.Date(2024, 5, 3, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		created time.Time
+		want    string
+	}{
+		{now.Add(-49 * time.Hour), "2d1h"},
+		{now.Add(-90 * time.Minute), "1h30m"},
+		{now.Add(-30 * time.Second), "0m"},
+		// Clocks may disagree; a resource is never younger than new.
+		{now.Add(time.Minute), "0m"},
+		{time.Time{}, "<unknown>"},
+	}
+	for _, tt := range tests {
+		res := &Resource{CreationTimestamp: tt.created}
+		if got := ResourceAge(res, now); got != tt.want {
+			t.Errorf("ResourceAge(%v) = %q, want %q", tt.created, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package format
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"text/template"
+
+	"k8s.io/client-go/util/jsonpath"
+)
+
+// TemplateFormatter executes a Go template against the graph Document, as
+// kubectl's -o go-template does. Fields use the JSON names, e.g.
+// {{.root}} or {{range .nodes}}{{.name}}{{end}}.
+type TemplateFormatter struct {
+	tmpl *template.Template
+}
+
+// templateFuncs holds the functions kubectl adds to go-template output,
+// plus filter and reject for selecting list items by field value.
+var templateFuncs = template.FuncMap{
+	"exists": exists,
+	"base64decode": func(s string) (string, error) {
+		decoded, err := base64.StdEncoding.DecodeString(s)
+		return string(decoded), err
+	},
+	"filter": func(items []interface{}, field string, value interface{}) []interface{} {
+		return selectItems(items, field, value, true)
+	},
+	"reject": func(items []interface{}, field string, value interface{}) []interface{} {
+		return selectItems(items, field, value, false)
+	},
+}
+
+func NewTemplateFormatter(text string) (*TemplateFormatter, error) {
+	tmpl, err := template.New("output").Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing template %s: %w", text, err)
+	}
+	return &TemplateFormatter{tmpl: tmpl}, nil
+}
+
+func (tf *TemplateFormatter) Format(w io.Writer, g *Graph) error {
+	data, err := documentObject(g)
+	if err != nil {
+		return err
+	}
+	var out bytes.Buffer
+	if err := tf.tmpl.Execute(&out, data); err != nil {
+		return fmt.Errorf("error executing template %q: %w", tf.tmpl.Root.String(), err)
+	}
+	_, err = w.Write(out.Bytes())
+	return err
+}
+
+// JSONPathFormatter evaluates a JSONPath template against the graph
+// Document, as kubectl's -o jsonpath does: text outside braces is printed
+// as is, and missing keys print nothing.
+type JSONPathFormatter struct {
+	jp *jsonpath.JSONPath
+}
+
+func NewJSONPathFormatter(expr string) (*JSONPathFormatter, error) {
+	jp := jsonpath.New("output").AllowMissingKeys(true)
+	if err := jp.Parse(expr); err != nil {
+		return nil, fmt.Errorf("error parsing jsonpath %s: %w", expr, err)
+	}
+	return &JSONPathFormatter{jp: jp}, nil
+}
+
+func (jf *JSONPathFormatter) Format(w io.Writer, g *Graph) error {
+	data, err := documentObject(g)
+	if err != nil {
+		return err
+	}
+	return jf.jp.Execute(w, data)
+}
+
+// documentObject returns the Document as generic maps and slices, which is
+// what templates and JSONPath walk.
+func documentObject(g *Graph) (interface{}, error) {
+	data, err := json.Marshal(NewDocument(g))
+	if err != nil {
+		return nil, err
+	}
+	var obj interface{}
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return nil, err
+	}
+	return obj, nil
+}
+
+func exists(item interface{}, indices ...interface{}) bool {
+	v := reflect.ValueOf(item)
+	for _, index := range indices {
+		for v.Kind() == reflect.Interface || v.Kind() == reflect.Ptr {
+			v = v.Elem()
+		}
+		switch v.Kind() {
+		case reflect.Map:
+			v = v.MapIndex(reflect.ValueOf(index))
+		case reflect.Slice, reflect.Array:
+			i, ok := index.(int)
+			if !ok || i < 0 || i >= v.Len() {
+				return false
+			}
+			v = v.Index(i)
+		default:
+			return false
+		}
+		if !v.IsValid() {
+			return false
+		}
+	}
+	return true
+}
+
+// selectItems keeps the items whose dotted field equals value, or the ones
+// that do not when keep is false.
+func selectItems(items []interface{}, field string, value interface{}, keep bool) []interface{} {
+	selected := make([]interface{}, 0, len(items))
+	for _, item := range items {
+		var current interface{} = item
+		for _, key := range strings.Split(field, ".") {
+			m, ok := current.(map[string]interface{})
+			if !ok {
+				current = nil
+				break
+			}
+			current = m[key]
+		}
+		if (fmt.Sprint(current) == fmt.Sprint(value)) == keep {
+			selected = append(selected, item)
+		}
+	}
+	return selected
+}
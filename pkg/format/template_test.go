@@ -0,0 +1,39 @@
+package format
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestJSONPath(t *testing.T) {
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{"{.root}", "Dataset/default/imagenet"},
+		{"{$.root}", "Dataset/default/imagenet"},
+		{"{.nodes[*].name}", "imagenet web-0 data pv-data"},
+		{"{range .edges[*]}{.type}{\"\\n\"}{end}", "labelSelector\nvolumeMount\nvolumeBinding\n"},
+		{"{.missing}", ""},
+		// Unlike custom columns, templates are not relaxed: text outside
+		// braces is printed as is, as kubectl does.
+		{"root: {.root}", "root: Dataset/default/imagenet"},
+		{".root", ".root"},
+		{"$.root", "$.root"},
+	}
+	for _, tt := range tests {
+		f, err := NewJSONPathFormatter(tt.expr)
+		if err != nil {
+			t.Errorf("NewJSONPathFormatter(%q): %v", tt.expr, err)
+			continue
+		}
+		var buf bytes.Buffer
+		if err := f.Format(&buf, exportGraph()); err != nil {
+			t.Errorf("%q: %v", tt.expr, err)
+			continue
+		}
+		if got := buf.String(); got != tt.want {
+			t.Errorf("%q printed %q, want %q", tt.expr, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package format
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	textwidth "golang.org/x/text/width"
+)
+
+// DisplayWidth returns the number of terminal cells s occupies. East Asian
+// wide and fullwidth characters, which include most emoji, take two cells;
+// combining marks, format characters and ANSI escape sequences take none.
+func DisplayWidth(s string) int {
+	n := 0
+	for i := 0; i < len(s); {
+		if skip := ansiSequenceLen(s[i:]); skip > 0 {
+			i += skip
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		n += runeWidth(r)
+		i += size
+	}
+	return n
+}
+
+func runeWidth(r rune) int {
+	if r == 0 || unicode.IsControl(r) || unicode.In(r, unicode.Mn, unicode.Me, unicode.Cf) {
+		return 0
+	}
+	switch textwidth.LookupRune(r).Kind() {
+	case textwidth.EastAsianWide, textwidth.EastAsianFullwidth:
+		return 2
+	}
+	return 1
+}
+
+// ansiSequenceLen returns the length of the CSI escape sequence, such as a
+// color code, that s starts with, or 0.
+func ansiSequenceLen(s string) int {
+	if !strings.HasPrefix(s, "\x1b[") {
+		return 0
+	}
+	for i := 2; i < len(s); i++ {
+		if s[i] >= 0x40 && s[i] <= 0x7e {
+			return i + 1
+		}
+	}
+	return len(s)
+}
+
+// Truncate shortens s to at most max cells, marking the cut with an
+// ellipsis. It never splits a rune.
+func Truncate(s string, max int) string {
+	if DisplayWidth(s) <= max {
+		return s
+	}
+	if max <= 0 {
+		return ""
+	}
+	var b strings.Builder
+	n := 0
+	for _, r := range s {
+		w := runeWidth(r)
+		if n+w > max-1 {
+			break
+		}
+		b.WriteRune(r)
+		n += w
+	}
+	b.WriteString("…")
+	return b.String()
+}
+
+// PadRight pads s with spaces to n cells.
+func PadRight(s string, n int) string {
+	if pad := n - DisplayWidth(s); pad > 0 {
+		return s + strings.Repeat(" ", pad)
+	}
+	return s
+}
+
+// WrapText splits s into lines of at most max cells, breaking after
+// spaces and punctuation where it can and inside words where it must.
+func WrapText(s string, max int) []string {
+	if max <= 0 || DisplayWidth(s) <= max {
+		return []string{s}
+	}
+	var lines []string
+	for DisplayWidth(s) > max {
+		cut, n, lastBreak := 0, 0, 0
+		for i, r := range s {
+			w := runeWidth(r)
+			if n+w > max {
+				break
+			}
+			n += w
+			cut = i + utf8.RuneLen(r)
+			if strings.ContainsRune(" ,./=", r) {
+				lastBreak = cut
+			}
+		}
+		if lastBreak > 0 {
+			cut = lastBreak
+		}
+		if cut == 0 {
+			// A single rune wider than max.
+			_, cut = utf8.DecodeRuneInString(s)
+		}
+		lines = append(lines, strings.TrimRight(s[:cut], " "))
+		s = strings.TrimLeft(s[cut:], " ")
+	}
+	if s != "" {
+		lines = append(lines, s)
+	}
+	return lines
+}
@@ -0,0 +1,24 @@
+package format
+
+import (
+	"io"
+
+	"sigs.k8s.io/yaml"
+)
+
+// YAMLFormatter writes the same Document as JSONFormatter, as YAML.
+type YAMLFormatter struct{}
+
+func NewYAMLFormatter() *YAMLFormatter {
+	return &YAMLFormatter{}
+}
+
+func (yf *YAMLFormatter) Format(w io.Writer, g *Graph) error {
+	data, err := yaml.Marshal(NewDocument(g))
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(data)
+	return err
+}
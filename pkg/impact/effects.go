@@ -0,0 +1,142 @@
+package impact
+
+import (
+	"7h3-3mp7y-m4n/kubectl-graph/pkg/inventory"
+	"fmt"
+	"slices"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// Effect is what losing one object does to another that survives it.
+type Effect struct {
+	Object string `json:"object"`
+	// Cause is the deleted or unavailable object the effect follows from.
+	Cause  string `json:"cause"`
+	Impact string `json:"impact"`
+}
+
+// effects returns what losing the removed objects does to the objects that
+// remain: Services losing endpoints, pods losing volumes or configuration,
+// Ingresses losing backends, controllers replacing pods, PersistentVolumes
+// reclaimed and Fluid datasets losing their runtime. Unless deleted is set,
+// the removed objects still exist but do not work, so nothing is replaced
+// or reclaimed.
+func effects(inv *inventory.Inventory, removed []*inventory.Object, deleted bool) []Effect {
+	gone := make(map[types.UID]bool)
+	for _, o := range removed {
+		gone[o.GetUID()] = true
+	}
+	var out []Effect
+	seen := make(map[Effect]bool)
+	add := func(o *inventory.Object, cause *inventory.Object, format string, args ...interface{}) {
+		// Effects are reported once per object, whichever cause comes first.
+		e := Effect{Object: o.ID(), Impact: fmt.Sprintf(format, args...)}
+		if seen[e] {
+			return
+		}
+		seen[e] = true
+		e.Cause = cause.ID()
+		out = append(out, e)
+	}
+	for _, o := range removed {
+		ns := o.GetNamespace()
+		switch {
+		case o.Is("", "Pod"):
+			if owner, ok := inv.Controller(o); ok && !gone[owner.GetUID()] && deleted {
+				add(owner, o, "creates a replacement pod")
+			}
+		case o.Is("", "PersistentVolumeClaim"):
+			for _, pod := range inv.OfKind("", "Pod") {
+				if pod.GetNamespace() == ns && !gone[pod.GetUID()] && slices.Contains(inventory.ClaimNames(pod), o.GetName()) {
+					if deleted {
+						add(pod, o, "mounts the claim, which stays Terminating until the pod is gone; replacement pods stay Pending")
+					} else {
+						add(pod, o, "mounts the claim and loses access to its data")
+					}
+				}
+			}
+			if volume, _, _ := unstructured.NestedString(o.Object, "spec", "volumeName"); volume != "" && deleted {
+				pv, ok := inv.Get("", "PersistentVolume", "", volume)
+				if !ok {
+					out = append(out, Effect{Object: "PersistentVolume/" + volume, Cause: o.ID(), Impact: "is reclaimed according to its reclaim policy"})
+					break
+				}
+				switch policy, _, _ := unstructured.NestedString(pv.Object, "spec", "persistentVolumeReclaimPolicy"); policy {
+				case "Delete":
+					add(pv, o, "is deleted along with its data (reclaim policy Delete)")
+				case "Retain":
+					add(pv, o, "becomes Released and keeps its data (reclaim policy Retain)")
+				default:
+					add(pv, o, "is reclaimed with policy %s", policy)
+				}
+			}
+		case o.Is("", "Service"):
+			for _, ing := range inv.OfKind("networking.k8s.io", "Ingress") {
+				if ing.GetNamespace() == ns && !gone[ing.GetUID()] && routesTo(ing, o.GetName()) {
+					add(ing, o, "routes to the Service, so its requests fail with 503")
+				}
+			}
+		case o.Is("", "ConfigMap"), o.Is("", "Secret"):
+			for _, pod := range inv.OfKind("", "Pod") {
+				if pod.GetNamespace() == ns && !gone[pod.GetUID()] && inventory.References(pod, o.GetKind(), o.GetName()) {
+					add(pod, o, "references the %s; it fails to start again on restart", o.GetKind())
+				}
+			}
+		case o.IsFluidRuntime():
+			if ds, ok := inv.Get(inventory.FluidGroup, "Dataset", ns, o.GetName()); ok && !gone[ds.GetUID()] {
+				if deleted {
+					add(ds, o, "loses its runtime and cache; it is no longer bound")
+				} else {
+					add(ds, o, "cannot be read through its runtime or cache")
+				}
+			}
+		case o.Is(inventory.FluidGroup, "Dataset"):
+			for _, rt := range inv.Objects() {
+				if deleted && rt.IsFluidRuntime() && rt.GetNamespace() == ns && rt.GetName() == o.GetName() && !gone[rt.GetUID()] {
+					add(rt, o, "has no dataset left to serve")
+				}
+			}
+			// Fluid names the dataset's claim after it.
+			if pvc, ok := inv.Get("", "PersistentVolumeClaim", ns, o.GetName()); ok && !gone[pvc.GetUID()] {
+				for _, pod := range inv.OfKind("", "Pod") {
+					if pod.GetNamespace() == ns && !gone[pod.GetUID()] && slices.Contains(inventory.ClaimNames(pod), pvc.GetName()) {
+						add(pod, o, "mounts the dataset's claim and loses access to its data")
+					}
+				}
+			}
+		}
+	}
+
+	// Services are judged once, on all the pods they lose.
+	for _, svc := range inv.OfKind("", "Service") {
+		if gone[svc.GetUID()] {
+			continue
+		}
+		pods := inv.Select("", "Pod", svc.GetNamespace(), inventory.Selector(svc))
+		var lost []*inventory.Object
+		for _, p := range pods {
+			if gone[p.GetUID()] {
+				lost = append(lost, p)
+			}
+		}
+		switch {
+		case len(lost) == 0:
+		case len(lost) == len(pods):
+			add(svc, lost[0], "loses all %d endpoints; requests fail until a matching pod is ready", len(pods))
+		default:
+			add(svc, lost[0], "loses %d of %d endpoints", len(lost), len(pods))
+		}
+	}
+	return out
+}
+
+func routesTo(ing *inventory.Object, service string) bool {
+	for _, b := range inventory.IngressBackends(ing) {
+		if b.Service == service {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,166 @@
+package impact
+
+import (
+	"7h3-3mp7y-m4n/kubectl-graph/pkg/inventory"
+	"fmt"
+	"slices"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// Policy is a deletion propagation policy, as given to kubectl delete
+// --cascade.
+type Policy string
+
+const (
+	// PolicyBackground deletes the object at once and lets the garbage
+	// collector delete its dependents afterwards.
+	PolicyBackground Policy = "background"
+	// PolicyForeground deletes the dependents first. The object stays with
+	// the foregroundDeletion finalizer until the dependents that block
+	// owner deletion are gone.
+	PolicyForeground Policy = "foreground"
+	// PolicyOrphan deletes only the object and removes the owner
+	// references to it from its dependents.
+	PolicyOrphan Policy = "orphan"
+)
+
+// ParsePolicy parses a --cascade value.
+func ParsePolicy(s string) (Policy, error) {
+	switch p := Policy(s); p {
+	case PolicyBackground, PolicyForeground, PolicyOrphan:
+		return p, nil
+	}
+	return "", fmt.Errorf("must be one of background, foreground or orphan, got '%s'", s)
+}
+
+// Action is what garbage collection does to an object.
+type Action string
+
+const (
+	ActionDelete Action = "delete"
+	ActionOrphan Action = "orphan"
+	ActionKeep   Action = "keep"
+)
+
+// gcFinalizers are the finalizers the API server and the garbage collector
+// manage themselves; they are part of the propagation, not a hold-up.
+var gcFinalizers = []string{"orphan", "foregroundDeletion"}
+
+// Step is what garbage collection does to one object.
+type Step struct {
+	Object string `json:"object"`
+	Action Action `json:"action"`
+	// Owner is the deleted owner that led to the object, empty for the
+	// object deleted by hand.
+	Owner  string `json:"owner,omitempty"`
+	Reason string `json:"reason"`
+	Depth  int    `json:"depth"`
+	// WaitsFor lists the dependents that must be gone before the object
+	// is removed: with foreground deletion, the ones whose owner reference
+	// sets blockOwnerDeletion.
+	WaitsFor []string `json:"waitsFor,omitempty"`
+	// Finalizers keep the object Terminating until their controllers
+	// remove them.
+	Finalizers []string `json:"finalizers,omitempty"`
+
+	obj *inventory.Object
+}
+
+// simulate returns the steps of garbage collection after target is
+// deleted with policy, in the order of a walk down the owner references
+// from target. A dependent is deleted once all its owners are; owners
+// missing from the inventory, such as cluster-scoped ones that were not
+// loaded, are assumed to exist.
+func simulate(inv *inventory.Inventory, target *inventory.Object, policy Policy) []Step {
+	root := newStep(target, ActionDelete, "", "deleted with --cascade="+string(policy), 0)
+	if target.GetDeletionTimestamp() != nil {
+		root.Reason = "already being deleted"
+	}
+	if policy == PolicyOrphan {
+		steps := []Step{root}
+		for _, dep := range inv.Dependents(target) {
+			steps = append(steps, newStep(dep.Object, ActionOrphan, target.ID(),
+				"owner reference to "+target.Ref()+" is removed", 1))
+		}
+		return steps
+	}
+
+	deleted := map[types.UID]bool{target.GetUID(): true}
+	children := make(map[types.UID][]Step)
+	queue := []Step{root}
+	var kept []inventory.Dependent
+	for len(queue) > 0 {
+		owner := queue[0]
+		queue = queue[1:]
+		for _, dep := range inv.Dependents(owner.obj) {
+			if deleted[dep.Object.GetUID()] {
+				continue
+			}
+			if len(liveOwners(dep.Object, deleted)) > 0 {
+				// Another owner may still be deleted further down.
+				kept = append(kept, dep)
+				continue
+			}
+			deleted[dep.Object.GetUID()] = true
+			step := newStep(dep.Object, ActionDelete, owner.Object, "owned by "+owner.obj.Ref(), owner.Depth+1)
+			children[owner.obj.GetUID()] = append(children[owner.obj.GetUID()], step)
+			queue = append(queue, step)
+		}
+	}
+	seen := make(map[types.UID]bool)
+	for _, dep := range kept {
+		if deleted[dep.Object.GetUID()] || seen[dep.Object.GetUID()] {
+			continue
+		}
+		seen[dep.Object.GetUID()] = true
+		owner, _ := inv.ByUID(dep.Ref.UID)
+		step := newStep(dep.Object, ActionKeep, owner.ID(), "still owned by "+strings.Join(liveOwners(dep.Object, deleted), ", "), 0)
+		children[dep.Ref.UID] = append(children[dep.Ref.UID], step)
+	}
+
+	var steps []Step
+	var walk func(step Step, depth int)
+	walk = func(step Step, depth int) {
+		step.Depth = depth
+		if policy == PolicyForeground && step.Action == ActionDelete {
+			for _, dep := range inv.Dependents(step.obj) {
+				if deleted[dep.Object.GetUID()] && dep.Ref.BlockOwnerDeletion != nil && *dep.Ref.BlockOwnerDeletion {
+					step.WaitsFor = append(step.WaitsFor, dep.Object.Ref())
+				}
+			}
+		}
+		steps = append(steps, step)
+		for _, child := range children[step.obj.GetUID()] {
+			walk(child, depth+1)
+		}
+	}
+	walk(root, 0)
+	return steps
+}
+
+func newStep(o *inventory.Object, action Action, owner, reason string, depth int) Step {
+	step := Step{Object: o.ID(), Action: action, Owner: owner, Reason: reason, Depth: depth, obj: o}
+	if action == ActionDelete {
+		for _, f := range o.GetFinalizers() {
+			if !slices.Contains(gcFinalizers, f) {
+				step.Finalizers = append(step.Finalizers, f)
+			}
+		}
+	}
+	return step
+}
+
+// liveOwners returns the references of the owners of o that are not
+// deleted.
+func liveOwners(o *inventory.Object, deleted map[types.UID]bool) []string {
+	var refs []string
+	for _, ref := range o.GetOwnerReferences() {
+		if deleted[ref.UID] {
+			continue
+		}
+		refs = append(refs, ref.Kind+"/"+ref.Name)
+	}
+	return refs
+}
@@ -0,0 +1,230 @@
+package impact
+
+import (
+	"7h3-3mp7y-m4n/kubectl-graph/pkg/inventory"
+	"7h3-3mp7y-m4n/kubectl-graph/pkg/inventory/inventorytest"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// The Deployment owns a ReplicaSet owning two pods; web-1 is also owned by
+// a StatefulSet that is not deleted. The Service selects both pods.
+const deployment = `apiVersion: apps/v1
+kind: Deployment
+metadata: {name: web, namespace: default, uid: d1}
+---
+apiVersion: apps/v1
+kind: ReplicaSet
+metadata:
+  name: web-5d
+  namespace: default
+  uid: rs1
+  finalizers: [example.com/cleanup, foregroundDeletion]
+  ownerReferences:
+  - {apiVersion: apps/v1, kind: Deployment, name: web, uid: d1, controller: true, blockOwnerDeletion: true}
+---
+apiVersion: v1
+kind: Pod
+metadata:
+  name: web-0
+  namespace: default
+  uid: p0
+  labels: {app: web}
+  ownerReferences:
+  - {apiVersion: apps/v1, kind: ReplicaSet, name: web-5d, uid: rs1, controller: true, blockOwnerDeletion: true}
+spec:
+  volumes:
+  - {name: data, persistentVolumeClaim: {claimName: data}}
+---
+apiVersion: v1
+kind: Pod
+metadata:
+  name: web-1
+  namespace: default
+  uid: p1
+  labels: {app: web}
+  ownerReferences:
+  - {apiVersion: apps/v1, kind: ReplicaSet, name: web-5d, uid: rs1, controller: true}
+  - {apiVersion: apps/v1, kind: StatefulSet, name: other, uid: s1}
+---
+apiVersion: apps/v1
+kind: StatefulSet
+metadata: {name: other, namespace: default, uid: s1}
+---
+apiVersion: v1
+kind: Service
+metadata: {name: web, namespace: default, uid: svc1}
+spec:
+  selector: {app: web}
+---
+apiVersion: v1
+kind: PersistentVolumeClaim
+metadata: {name: data, namespace: default, uid: pvc1}
+spec:
+  volumeName: pv-data
+---
+apiVersion: v1
+kind: PersistentVolume
+metadata: {name: pv-data, uid: pv1}
+spec:
+  persistentVolumeReclaimPolicy: Retain`
+
+func stepStrings(steps []Step) []string {
+	out := make([]string, len(steps))
+	for i, s := range steps {
+		out[i] = fmt.Sprintf("%d %s %s", s.Depth, s.Action, s.Object)
+		if len(s.WaitsFor) > 0 {
+			out[i] += " waits for " + strings.Join(s.WaitsFor, ",")
+		}
+		if len(s.Finalizers) > 0 {
+			out[i] += " held by " + strings.Join(s.Finalizers, ",")
+		}
+	}
+	return out
+}
+
+func TestSimulate(t *testing.T) {
+	tests := []struct {
+		name   string
+		target string
+		policy Policy
+		want   []string
+	}{
+		{
+			name:   "background",
+			target: "Deployment/default/web",
+			policy: PolicyBackground,
+			want: []string{
+				"0 delete Deployment/default/web",
+				"1 delete ReplicaSet/default/web-5d held by example.com/cleanup",
+				"2 delete Pod/default/web-0",
+				"2 keep Pod/default/web-1",
+			},
+		},
+		{
+			name:   "foreground",
+			target: "Deployment/default/web",
+			policy: PolicyForeground,
+			want: []string{
+				"0 delete Deployment/default/web waits for ReplicaSet/web-5d",
+				"1 delete ReplicaSet/default/web-5d waits for Pod/web-0 held by example.com/cleanup",
+				"2 delete Pod/default/web-0",
+				"2 keep Pod/default/web-1",
+			},
+		},
+		{
+			name:   "orphan",
+			target: "ReplicaSet/default/web-5d",
+			policy: PolicyOrphan,
+			want: []string{
+				"0 delete ReplicaSet/default/web-5d held by example.com/cleanup",
+				"1 orphan Pod/default/web-0",
+				"1 orphan Pod/default/web-1",
+			},
+		},
+		{
+			name:   "no dependents",
+			target: "Service/default/web",
+			policy: PolicyBackground,
+			want:   []string{"0 delete Service/default/web"},
+		},
+	}
+	inv := inventory.New(inventorytest.Objects(t, deployment))
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target := inventorytest.Find(t, inv, tt.target)
+			if got := stepStrings(simulate(inv, target, tt.policy)); !slices.Equal(got, tt.want) {
+				t.Errorf("simulate =\n  %s\nwant\n  %s", strings.Join(got, "\n  "), strings.Join(tt.want, "\n  "))
+			}
+		})
+	}
+}
+
+func TestSimulateSharedOwners(t *testing.T) {
+	// web-1 is kept while its ReplicaSet lives.
+	inv := inventory.New(inventorytest.Objects(t, deployment))
+	steps := simulate(inv, inventorytest.Find(t, inv, "StatefulSet/default/other"), PolicyBackground)
+	want := []string{"0 delete StatefulSet/default/other", "1 keep Pod/default/web-1"}
+	if got := stepStrings(steps); !slices.Equal(got, want) {
+		t.Errorf("simulate = %v, want %v", got, want)
+	}
+}
+
+func TestAnalyzeEffects(t *testing.T) {
+	tests := []struct {
+		target string
+		want   []string
+	}{
+		{"Deployment/default/web", []string{"Service/default/web: loses 1 of 2 endpoints"}},
+		{"Pod/default/web-0", []string{
+			"ReplicaSet/default/web-5d: creates a replacement pod",
+			"Service/default/web: loses 1 of 2 endpoints",
+		}},
+		{"PersistentVolumeClaim/default/data", []string{
+			"Pod/default/web-0: mounts the claim, which stays Terminating until the pod is gone; replacement pods stay Pending",
+			"PersistentVolume/pv-data: becomes Released and keeps its data (reclaim policy Retain)",
+		}},
+	}
+	inv := inventory.New(inventorytest.Objects(t, deployment))
+	for _, tt := range tests {
+		t.Run(tt.target, func(t *testing.T) {
+			r := Analyze(inv, inventorytest.Find(t, inv, tt.target), PolicyBackground)
+			var got []string
+			for _, e := range r.Effects {
+				got = append(got, e.Object+": "+e.Impact)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("effects =\n  %s\nwant\n  %s", strings.Join(got, "\n  "), strings.Join(tt.want, "\n  "))
+			}
+		})
+	}
+}
+
+func TestUnavailable(t *testing.T) {
+	tests := []struct {
+		target string
+		want   []string
+	}{
+		// The ReplicaSet keeps its pods, so nothing else is affected.
+		{"ReplicaSet/default/web-5d", nil},
+		// A pod that is not ready is not replaced.
+		{"Pod/default/web-0", []string{"Service/default/web: loses 1 of 2 endpoints"}},
+		// The claim is not deleted, so its volume is not reclaimed.
+		{"PersistentVolumeClaim/default/data", []string{"Pod/default/web-0: mounts the claim and loses access to its data"}},
+	}
+	inv := inventory.New(inventorytest.Objects(t, deployment))
+	for _, tt := range tests {
+		t.Run(tt.target, func(t *testing.T) {
+			r := Unavailable(inv, inventorytest.Find(t, inv, tt.target))
+			if !r.Unavailable || r.Cascade != "" || len(r.GarbageCollection) != 0 {
+				t.Errorf("report = %+v, want no garbage collection", r)
+			}
+			var got []string
+			for _, e := range r.Effects {
+				got = append(got, e.Object+": "+e.Impact)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("effects =\n  %s\nwant\n  %s", strings.Join(got, "\n  "), strings.Join(tt.want, "\n  "))
+			}
+		})
+	}
+}
+
+func TestPrintUnavailable(t *testing.T) {
+	inv := inventory.New(inventorytest.Objects(t, deployment))
+	var buf strings.Builder
+	if err := Unavailable(inv, inventorytest.Find(t, inv, "Pod/default/web-0")).Print(&buf, false); err != nil {
+		t.Fatal(err)
+	}
+	want := `Pod/default/web-0 unavailable
+
+Affected (1)
+  Service/default/web loses 1 of 2 endpoints
+    because Pod/default/web-0 is unavailable
+`
+	if buf.String() != want {
+		t.Errorf("Print =\n%s\nwant\n%s", buf.String(), want)
+	}
+}
@@ -0,0 +1,152 @@
+// Package impact works out what breaks when an object is deleted or
+// unavailable: what the garbage collector deletes along with it, what
+// finalizers hold up, and how the objects that remain are affected.
+package impact
+
+import (
+	"7h3-3mp7y-m4n/kubectl-graph/pkg/format"
+	"7h3-3mp7y-m4n/kubectl-graph/pkg/inventory"
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/fatih/color"
+)
+
+// Report is the result of Analyze or Unavailable, and what impact -o json
+// prints.
+type Report struct {
+	Target string `json:"target"`
+	// Cascade is empty when the target is unavailable rather than deleted.
+	Cascade     Policy `json:"cascade,omitempty"`
+	Unavailable bool   `json:"unavailable,omitempty"`
+	// GarbageCollection lists the target and its dependents in the order
+	// of a walk down the owner references, with what happens to each. It is
+	// empty when the target is unavailable.
+	GarbageCollection []Step `json:"garbageCollection"`
+	// Effects lists how the objects that are not deleted are affected.
+	Effects []Effect `json:"effects"`
+	// Skipped lists the resources that could not be read, which may hide
+	// dependents.
+	Skipped []string `json:"skipped,omitempty"`
+}
+
+// Analyze simulates deleting target from inv with policy.
+func Analyze(inv *inventory.Inventory, target *inventory.Object, policy Policy) *Report {
+	steps := simulate(inv, target, policy)
+	var removed []*inventory.Object
+	for _, step := range steps {
+		if step.Action == ActionDelete {
+			removed = append(removed, step.obj)
+		}
+	}
+	return &Report{
+		Target:            target.ID(),
+		Cascade:           policy,
+		GarbageCollection: steps,
+		Effects:           append([]Effect{}, effects(inv, removed, true)...),
+		Skipped:           inv.Skipped,
+	}
+}
+
+// Unavailable works out what breaks while target exists but does not
+// work, such as a pod that is not ready or a runtime that is down. Nothing
+// is garbage collected, so only target itself is lost.
+func Unavailable(inv *inventory.Inventory, target *inventory.Object) *Report {
+	return &Report{
+		Target:            target.ID(),
+		Unavailable:       true,
+		GarbageCollection: []Step{},
+		Effects:           append([]Effect{}, effects(inv, []*inventory.Object{target}, false)...),
+		Skipped:           inv.Skipped,
+	}
+}
+
+var actionColors = map[Action]color.Attribute{
+	ActionDelete: color.FgRed,
+	ActionOrphan: color.FgYellow,
+	ActionKeep:   color.FgGreen,
+}
+
+// Print writes r for people: the garbage collection as a tree, the
+// finalizers and dependents that hold deletion up, then the effects.
+func (r *Report) Print(out io.Writer, colored bool) error {
+	paint := func(s string, attrs ...color.Attribute) string {
+		if !colored {
+			return s
+		}
+		c := color.New(attrs...)
+		c.EnableColor()
+		return c.Sprint(s)
+	}
+	w := bufio.NewWriter(out)
+	if r.Unavailable {
+		fmt.Fprintln(w, paint(fmt.Sprintf("%s unavailable", r.Target), color.FgCyan, color.Bold))
+		fmt.Fprintln(w)
+		r.printEffects(w, paint, "is unavailable", "Nothing else depends on it.")
+		return w.Flush()
+	}
+	fmt.Fprintln(w, paint(fmt.Sprintf("Deleting %s with --cascade=%s", r.Target, r.Cascade), color.FgCyan, color.Bold))
+	fmt.Fprintln(w)
+
+	fmt.Fprintln(w, paint(fmt.Sprintf("Garbage collection (%d)", len(r.GarbageCollection)), color.FgYellow, color.Bold))
+	width := 0
+	for _, step := range r.GarbageCollection {
+		width = max(width, format.DisplayWidth(stepLabel(step)))
+	}
+	for _, step := range r.GarbageCollection {
+		action := paint(format.PadRight(string(step.Action), 7), actionColors[step.Action])
+		fmt.Fprintf(w, "  %s %s  %s\n", action, format.PadRight(stepLabel(step), width), step.Reason)
+	}
+	fmt.Fprintln(w)
+
+	var holds []string
+	for _, step := range r.GarbageCollection {
+		if len(step.WaitsFor) > 0 {
+			holds = append(holds, fmt.Sprintf("%s waits for %s to be deleted first (blockOwnerDeletion)", step.Object, strings.Join(step.WaitsFor, ", ")))
+		}
+		if len(step.Finalizers) > 0 {
+			holds = append(holds, fmt.Sprintf("%s stays Terminating until these finalizers are removed: %s", step.Object, strings.Join(step.Finalizers, ", ")))
+		}
+	}
+	if len(holds) > 0 {
+		fmt.Fprintln(w, paint(fmt.Sprintf("Held up by (%d)", len(holds)), color.FgYellow, color.Bold))
+		for _, h := range holds {
+			fmt.Fprintf(w, "  %s\n", h)
+		}
+		fmt.Fprintln(w)
+	}
+
+	r.printEffects(w, paint, "is gone", "Nothing else depends on the deleted objects.")
+	return w.Flush()
+}
+
+// printEffects writes the effects, each followed by its cause and state,
+// then the resources that could not be read.
+func (r *Report) printEffects(w io.Writer, paint func(string, ...color.Attribute) string, state, none string) {
+	fmt.Fprintln(w, paint(fmt.Sprintf("Affected (%d)", len(r.Effects)), color.FgYellow, color.Bold))
+	if len(r.Effects) == 0 {
+		fmt.Fprintf(w, "  %s\n", none)
+	}
+	for _, e := range r.Effects {
+		fmt.Fprintf(w, "  %s %s\n", paint(e.Object, color.Bold), e.Impact)
+		fmt.Fprintf(w, "    because %s %s\n", e.Cause, state)
+	}
+
+	if len(r.Skipped) > 0 {
+		fmt.Fprintln(w)
+		fmt.Fprintf(w, "Warning: %d resources could not be read and may hide dependents:\n", len(r.Skipped))
+		for _, s := range r.Skipped {
+			fmt.Fprintf(w, "  %s\n", s)
+		}
+	}
+}
+
+// stepLabel indents the object of step by its depth in the tree.
+func stepLabel(step Step) string {
+	if step.Depth == 0 {
+		return step.Object
+	}
+	return strings.Repeat("  ", step.Depth-1) + "└ " + step.Object
+}
@@ -0,0 +1,283 @@
+// Package inventory loads every object of a namespace, or of the cluster,
+// into memory, for commands that reason about objects the graph
+// collectors do not follow, such as owner references between arbitrary
+// kinds.
+package inventory
+
+import (
+	"7h3-3mp7y-m4n/kubectl-graph/pkg/client"
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/discovery/cached/memory"
+	"k8s.io/client-go/restmapper"
+)
+
+// Object is an object of the inventory and the resource it was listed
+// from.
+type Object struct {
+	*unstructured.Unstructured
+	Resource   schema.GroupVersionResource
+	Namespaced bool
+}
+
+// Ref returns the Kind/name reference of o, as kubectl prints it.
+func (o *Object) Ref() string {
+	return fmt.Sprintf("%s/%s", o.GetKind(), o.GetName())
+}
+
+// ID returns the Kind/namespace/name identifier of o, or Kind/name for
+// cluster-scoped objects, in the form graph nodes use.
+func (o *Object) ID() string {
+	if o.GetNamespace() == "" {
+		return o.Ref()
+	}
+	return fmt.Sprintf("%s/%s/%s", o.GetKind(), o.GetNamespace(), o.GetName())
+}
+
+// Is reports whether o is of the given API group and kind.
+func (o *Object) Is(group, kind string) bool {
+	gvk := o.GroupVersionKind()
+	return gvk.Group == group && gvk.Kind == kind
+}
+
+// Inventory is a set of objects indexed by UID.
+type Inventory struct {
+	objects []*Object
+	byUID   map[types.UID]*Object
+	// Skipped lists the resources that could not be listed, typically for
+	// lack of permission, as "resource: error".
+	Skipped []string
+	loaded  map[schema.GroupResource]bool
+	failed  map[schema.GroupResource]bool
+}
+
+// New returns an inventory of objs.
+func New(objs []*Object) *Inventory {
+	inv := &Inventory{byUID: make(map[types.UID]*Object)}
+	for _, o := range objs {
+		inv.Add(o)
+	}
+	return inv
+}
+
+// Loaded reports whether resource was listed, so that the absence of an
+// object of it means the object does not exist.
+func (inv *Inventory) Loaded(resource schema.GroupResource) bool {
+	return inv.loaded[resource]
+}
+
+// Failed reports whether listing resource failed, in which case the
+// absence of its objects proves nothing.
+func (inv *Inventory) Failed(resource schema.GroupResource) bool {
+	return inv.failed[resource]
+}
+
+// Add adds o to the inventory.
+func (inv *Inventory) Add(o *Object) {
+	inv.objects = append(inv.objects, o)
+	if uid := o.GetUID(); uid != "" {
+		inv.byUID[uid] = o
+	}
+}
+
+// Objects returns every object in the order they were added. Load adds
+// them sorted by namespace, kind and name.
+func (inv *Inventory) Objects() []*Object {
+	return inv.objects
+}
+
+// ByUID returns the object with the given UID.
+func (inv *Inventory) ByUID(uid types.UID) (*Object, bool) {
+	o, ok := inv.byUID[uid]
+	return o, ok
+}
+
+// Lookup returns the object listed from resource in namespace.
+func (inv *Inventory) Lookup(resource schema.GroupResource, namespace, name string) (*Object, bool) {
+	for _, o := range inv.objects {
+		if o.Resource.GroupResource() == resource && o.GetNamespace() == namespace && o.GetName() == name {
+			return o, true
+		}
+	}
+	return nil, false
+}
+
+// Get returns the object of the given group and kind in namespace.
+func (inv *Inventory) Get(group, kind, namespace, name string) (*Object, bool) {
+	for _, o := range inv.objects {
+		if o.Is(group, kind) && o.GetNamespace() == namespace && o.GetName() == name {
+			return o, true
+		}
+	}
+	return nil, false
+}
+
+// OfKind returns the objects of the given group and kind.
+func (inv *Inventory) OfKind(group, kind string) []*Object {
+	var objs []*Object
+	for _, o := range inv.objects {
+		if o.Is(group, kind) {
+			objs = append(objs, o)
+		}
+	}
+	return objs
+}
+
+// Dependents returns the objects listing owner in their owner references,
+// with the reference to it.
+func (inv *Inventory) Dependents(owner *Object) []Dependent {
+	var deps []Dependent
+	for _, o := range inv.objects {
+		for _, ref := range o.GetOwnerReferences() {
+			if ref.UID == owner.GetUID() {
+				deps = append(deps, Dependent{Object: o, Ref: ref})
+			}
+		}
+	}
+	return deps
+}
+
+// Dependent is an object and its owner reference to an owner.
+type Dependent struct {
+	Object *Object
+	Ref    metav1.OwnerReference
+}
+
+// Select returns the objects of the given group and kind in namespace whose
+// labels match selector. An empty selector matches nothing, as for
+// Services.
+func (inv *Inventory) Select(group, kind, namespace string, selector map[string]string) []*Object {
+	if len(selector) == 0 {
+		return nil
+	}
+	sel := labels.SelectorFromSet(selector)
+	var objs []*Object
+	for _, o := range inv.objects {
+		if o.Is(group, kind) && o.GetNamespace() == namespace && sel.Matches(labels.Set(o.GetLabels())) {
+			objs = append(objs, o)
+		}
+	}
+	return objs
+}
+
+// skippedResources are never loaded: they are numerous, short-lived and
+// own nothing.
+var skippedResources = []schema.GroupResource{
+	{Group: "", Resource: "events"},
+	{Group: "events.k8s.io", Resource: "events"},
+}
+
+// Load lists every namespaced resource in namespace, or in all namespaces
+// when it is empty, plus the named cluster-scoped resources, such as
+// "persistentvolumes". Resources that cannot be listed are recorded in
+// Skipped rather than failing the load.
+func Load(ctx context.Context, c *client.Client, namespace string, clusterResources ...string) (*Inventory, error) {
+	return LoadMatching(ctx, c, namespace, func(r schema.GroupResource, namespaced bool) bool {
+		return namespaced || slices.Contains(clusterResources, r.Resource)
+	})
+}
+
+// LoadMatching is Load for the resources match accepts.
+func LoadMatching(ctx context.Context, c *client.Client, namespace string, match func(r schema.GroupResource, namespaced bool) bool) (*Inventory, error) {
+	lists, err := discovery.ServerPreferredResources(c.Client.Discovery())
+	if err != nil && !discovery.IsGroupDiscoveryFailedError(err) {
+		return nil, fmt.Errorf("failed to discover resources: %w", err)
+	}
+	type target struct {
+		gvr        schema.GroupVersionResource
+		namespaced bool
+	}
+	var targets []target
+	for _, list := range lists {
+		gv, err := schema.ParseGroupVersion(list.GroupVersion)
+		if err != nil {
+			continue
+		}
+		for _, r := range list.APIResources {
+			gvr := gv.WithResource(r.Name)
+			switch {
+			case strings.Contains(r.Name, "/"),
+				!slices.Contains(r.Verbs, "list"),
+				slices.Contains(skippedResources, gvr.GroupResource()),
+				!match(gvr.GroupResource(), r.Namespaced):
+				continue
+			}
+			targets = append(targets, target{gvr: gvr, namespaced: r.Namespaced})
+		}
+	}
+
+	var (
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		objs    []*Object
+		skipped []string
+		loaded  = make(map[schema.GroupResource]bool)
+		failed  = make(map[schema.GroupResource]bool)
+	)
+	for _, t := range targets {
+		wg.Go(func() {
+			var list *unstructured.UnstructuredList
+			var err error
+			if t.namespaced {
+				list, err = c.DynamicClient.Resource(t.gvr).Namespace(namespace).List(ctx, metav1.ListOptions{})
+			} else {
+				list, err = c.DynamicClient.Resource(t.gvr).List(ctx, metav1.ListOptions{})
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				skipped = append(skipped, fmt.Sprintf("%s: %v", t.gvr.GroupResource(), err))
+				failed[t.gvr.GroupResource()] = true
+				return
+			}
+			loaded[t.gvr.GroupResource()] = true
+			for i := range list.Items {
+				objs = append(objs, &Object{Unstructured: &list.Items[i], Resource: t.gvr, Namespaced: t.namespaced})
+			}
+		})
+	}
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	sort.Slice(objs, func(i, j int) bool {
+		a, b := objs[i], objs[j]
+		if a.GetNamespace() != b.GetNamespace() {
+			return a.GetNamespace() < b.GetNamespace()
+		}
+		if a.GetKind() != b.GetKind() {
+			return a.GetKind() < b.GetKind()
+		}
+		return a.GetName() < b.GetName()
+	})
+	sort.Strings(skipped)
+	inv := New(objs)
+	inv.Skipped = skipped
+	inv.loaded = loaded
+	inv.failed = failed
+	return inv, nil
+}
+
+// Resolve maps a resource argument as kubectl accepts it, such as "pvc",
+// "deployments" or "deployment.apps", to the resource it names.
+func Resolve(c *client.Client, resource string) (schema.GroupVersionResource, error) {
+	cached := memory.NewMemCacheClient(c.Client.Discovery())
+	mapper := restmapper.NewShortcutExpander(restmapper.NewDeferredDiscoveryRESTMapper(cached), cached, nil)
+	gvr, err := mapper.ResourceFor(schema.ParseGroupResource(strings.ToLower(resource)).WithVersion(""))
+	if err != nil {
+		return schema.GroupVersionResource{}, fmt.Errorf("unknown resource type '%s': %w", resource, err)
+	}
+	return gvr, nil
+}
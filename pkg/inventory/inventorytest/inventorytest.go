@@ -0,0 +1,56 @@
+// Package inventorytest builds inventories from YAML for the tests of the
+// packages that reason about them.
+package inventorytest
+
+import (
+	"7h3-3mp7y-m4n/kubectl-graph/pkg/inventory"
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+)
+
+// Unstructured reads YAML documents separated by "---" into objects.
+func Unstructured(t testing.TB, docs string) []*unstructured.Unstructured {
+	t.Helper()
+	var objs []*unstructured.Unstructured
+	for _, doc := range strings.Split(docs, "\n---\n") {
+		data, err := yaml.YAMLToJSON([]byte(doc))
+		if err != nil {
+			t.Fatal(err)
+		}
+		u := &unstructured.Unstructured{}
+		if err := u.UnmarshalJSON(data); err != nil {
+			t.Fatal(err)
+		}
+		objs = append(objs, u)
+	}
+	return objs
+}
+
+// Objects reads YAML documents separated by "---" into inventory objects,
+// with the resource guessed from the kind.
+func Objects(t testing.TB, docs string) []*inventory.Object {
+	t.Helper()
+	var objs []*inventory.Object
+	for _, u := range Unstructured(t, docs) {
+		gvr, _ := meta.UnsafeGuessKindToResource(u.GroupVersionKind())
+		objs = append(objs, &inventory.Object{Unstructured: u, Resource: gvr, Namespaced: u.GetNamespace() != ""})
+	}
+	return objs
+}
+
+// Find returns the object of inv with the given ID, such as
+// "Pod/default/web-0", and fails the test when there is none.
+func Find(t testing.TB, inv *inventory.Inventory, id string) *inventory.Object {
+	t.Helper()
+	for _, o := range inv.Objects() {
+		if o.ID() == id {
+			return o
+		}
+	}
+	t.Fatalf("no object %s", id)
+	return nil
+}
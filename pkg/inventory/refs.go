@@ -0,0 +1,139 @@
+package inventory
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const (
+	// FluidGroup is the API group of Fluid datasets and runtimes.
+	FluidGroup = "data.fluid.io"
+	// FluidDatasetLabel is the label Fluid puts on the pods, claims and
+	// services of a dataset, set to its name.
+	FluidDatasetLabel = "fluid.io/dataset"
+)
+
+// IsFluidRuntime reports whether o is a Fluid runtime, such as an
+// AlluxioRuntime. A runtime serves the dataset of the same name.
+func (o *Object) IsFluidRuntime() bool {
+	return o.GroupVersionKind().Group == FluidGroup && strings.HasSuffix(o.GetKind(), "Runtime")
+}
+
+// Controller returns the owner of o marked as its controller, when it is
+// in the inventory.
+func (inv *Inventory) Controller(o *Object) (*Object, bool) {
+	for _, ref := range o.GetOwnerReferences() {
+		if ref.Controller != nil && *ref.Controller {
+			return inv.ByUID(ref.UID)
+		}
+	}
+	return nil, false
+}
+
+// Selector returns the label selector of a Service, empty when it selects
+// no pods itself.
+func Selector(svc *Object) map[string]string {
+	selector, _, _ := unstructured.NestedStringMap(svc.Object, "spec", "selector")
+	return selector
+}
+
+// FormatSelector formats a label selector as kubectl accepts it, such as
+// "app=web,tier=frontend".
+func FormatSelector(selector map[string]string) string {
+	pairs := make([]string, 0, len(selector))
+	for k, v := range selector {
+		pairs = append(pairs, k+"="+v)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+// ClaimNames returns the claims the volumes of pod mount, by volume index.
+// Volumes of other kinds have an empty name.
+func ClaimNames(pod *Object) []string {
+	volumes, _, _ := unstructured.NestedSlice(pod.Object, "spec", "volumes")
+	names := make([]string, len(volumes))
+	for i, v := range volumes {
+		vol, _ := v.(map[string]interface{})
+		names[i], _, _ = unstructured.NestedString(vol, "persistentVolumeClaim", "claimName")
+	}
+	return names
+}
+
+// IngressBackend is a Service an Ingress routes to, and the field path of
+// the reference.
+type IngressBackend struct {
+	Service string
+	Path    string
+}
+
+// IngressBackends returns the Services ing routes to.
+func IngressBackends(ing *Object) []IngressBackend {
+	var backends []IngressBackend
+	if name, _, _ := unstructured.NestedString(ing.Object, "spec", "defaultBackend", "service", "name"); name != "" {
+		backends = append(backends, IngressBackend{Service: name, Path: "spec.defaultBackend.service.name"})
+	}
+	rules, _, _ := unstructured.NestedSlice(ing.Object, "spec", "rules")
+	for i, r := range rules {
+		rule, _ := r.(map[string]interface{})
+		paths, _, _ := unstructured.NestedSlice(rule, "http", "paths")
+		for j, p := range paths {
+			path, _ := p.(map[string]interface{})
+			if name, _, _ := unstructured.NestedString(path, "backend", "service", "name"); name != "" {
+				backends = append(backends, IngressBackend{
+					Service: name,
+					Path:    fmt.Sprintf("spec.rules[%d].http.paths[%d].backend.service.name", i, j),
+				})
+			}
+		}
+	}
+	return backends
+}
+
+// References reports whether pod needs the named ConfigMap or Secret, from
+// a volume, envFrom or env, without marking the reference optional.
+func References(pod *Object, kind, name string) bool {
+	volumeSource, volumeField, refField, keyRef := "configMap", "name", "configMapRef", "configMapKeyRef"
+	if kind == "Secret" {
+		volumeSource, volumeField, refField, keyRef = "secret", "secretName", "secretRef", "secretKeyRef"
+	}
+	volumes, _, _ := unstructured.NestedSlice(pod.Object, "spec", "volumes")
+	for _, v := range volumes {
+		if vol, _ := v.(map[string]interface{}); required(vol, name, volumeSource, volumeField) {
+			return true
+		}
+	}
+	for _, field := range []string{"initContainers", "containers"} {
+		containers, _, _ := unstructured.NestedSlice(pod.Object, "spec", field)
+		for _, c := range containers {
+			container, _ := c.(map[string]interface{})
+			envFrom, _, _ := unstructured.NestedSlice(container, "envFrom")
+			for _, e := range envFrom {
+				if m, _ := e.(map[string]interface{}); required(m, name, refField, "name") {
+					return true
+				}
+			}
+			env, _, _ := unstructured.NestedSlice(container, "env")
+			for _, e := range env {
+				if m, _ := e.(map[string]interface{}); required(m, name, "valueFrom", keyRef, "name") {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// required reports whether the reference whose name is at path in m names
+// name and is not optional.
+func required(m map[string]interface{}, name string, path ...string) bool {
+	if value, _, _ := unstructured.NestedString(m, path...); value != name {
+		return false
+	}
+	parent := path[:len(path)-1]
+	optional, _, _ := unstructured.NestedBool(m, append(parent[:len(parent):len(parent)], "optional")...)
+	return !optional
+}
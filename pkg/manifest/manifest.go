@@ -0,0 +1,122 @@
+// Package manifest reads Kubernetes objects from YAML or JSON manifests,
+// such as the output of helm template, to build graphs before anything is
+// applied.
+package manifest
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+)
+
+// extensions are the files read from directories.
+var extensions = []string{".yaml", ".yml", ".json"}
+
+// clusterScoped lists the common cluster-scoped kinds, which are not given
+// the default namespace. Other kinds without a namespace are assumed to be
+// namespaced, as kubectl apply would find out from the cluster.
+var clusterScoped = []schema.GroupKind{
+	{Kind: "Namespace"},
+	{Kind: "Node"},
+	{Kind: "PersistentVolume"},
+	{Group: "storage.k8s.io", Kind: "StorageClass"},
+	{Group: "storage.k8s.io", Kind: "CSIDriver"},
+	{Group: "rbac.authorization.k8s.io", Kind: "ClusterRole"},
+	{Group: "rbac.authorization.k8s.io", Kind: "ClusterRoleBinding"},
+	{Group: "apiextensions.k8s.io", Kind: "CustomResourceDefinition"},
+	{Group: "admissionregistration.k8s.io", Kind: "MutatingWebhookConfiguration"},
+	{Group: "admissionregistration.k8s.io", Kind: "ValidatingWebhookConfiguration"},
+	{Group: "scheduling.k8s.io", Kind: "PriorityClass"},
+}
+
+// Load reads the objects of the given files and directories, walked
+// recursively, or of stdin for "-". Objects without a namespace are put
+// in namespace unless their kind is cluster-scoped, and Lists are
+// flattened.
+func Load(paths []string, stdin io.Reader, namespace string) ([]*unstructured.Unstructured, error) {
+	var objs []*unstructured.Unstructured
+	for _, p := range paths {
+		if p == "-" {
+			read, err := decode(stdin, "stdin", namespace)
+			if err != nil {
+				return nil, err
+			}
+			objs = append(objs, read...)
+			continue
+		}
+		err := filepath.WalkDir(p, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			// Files named explicitly are read whatever their extension.
+			if d.IsDir() || (path != p && !slices.Contains(extensions, strings.ToLower(filepath.Ext(path)))) {
+				return nil
+			}
+			f, err := os.Open(path)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			read, err := decode(f, path, namespace)
+			if err != nil {
+				return err
+			}
+			objs = append(objs, read...)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return objs, nil
+}
+
+// decode reads the documents of r, named source in errors.
+func decode(r io.Reader, source, namespace string) ([]*unstructured.Unstructured, error) {
+	var objs []*unstructured.Unstructured
+	dec := utilyaml.NewYAMLOrJSONDecoder(r, 4096)
+	for i := 1; ; i++ {
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			if errors.Is(err, io.EOF) {
+				return objs, nil
+			}
+			return nil, fmt.Errorf("%s: document %d: %w", source, i, err)
+		}
+		if raw = bytes.TrimSpace(raw); len(raw) == 0 || bytes.Equal(raw, []byte("null")) {
+			continue
+		}
+		// The unstructured scheme keeps integers as int64, as the
+		// unstructured helpers expect.
+		obj, _, err := unstructured.UnstructuredJSONScheme.Decode(raw, nil, nil)
+		if err != nil {
+			return nil, fmt.Errorf("%s: document %d: %w", source, i, err)
+		}
+		switch obj := obj.(type) {
+		case *unstructured.UnstructuredList:
+			for j := range obj.Items {
+				objs = append(objs, withNamespace(&obj.Items[j], namespace))
+			}
+		case *unstructured.Unstructured:
+			objs = append(objs, withNamespace(obj, namespace))
+		}
+	}
+}
+
+func withNamespace(u *unstructured.Unstructured, namespace string) *unstructured.Unstructured {
+	if u.GetNamespace() == "" && !slices.Contains(clusterScoped, u.GroupVersionKind().GroupKind()) {
+		u.SetNamespace(namespace)
+	}
+	return u
+}
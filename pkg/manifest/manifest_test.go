@@ -0,0 +1,155 @@
+package manifest
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+const dataset = `apiVersion: data.fluid.io/v1alpha1
+kind: Dataset
+metadata:
+  name: imagenet
+spec:
+  mounts:
+  - mountPoint: s3://bucket
+---
+# An empty document, as helm template leaves behind.
+---
+apiVersion: data.fluid.io/v1alpha1
+kind: AlluxioRuntime
+metadata:
+  name: imagenet
+  namespace: fluid
+spec:
+  replicas: 2
+`
+
+const list = `{
+  "apiVersion": "v1",
+  "kind": "List",
+  "items": [
+    {"apiVersion": "v1", "kind": "PersistentVolume", "metadata": {"name": "pv-data"}},
+    {"apiVersion": "storage.k8s.io/v1", "kind": "StorageClass", "metadata": {"name": "fast"}},
+    {"apiVersion": "v1", "kind": "Service", "metadata": {"name": "web"}}
+  ]
+}`
+
+func writeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, data := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestLoad(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"dataset.yaml":        dataset,
+		"nested/list.json":    list,
+		"nested/deploy.yml":   "apiVersion: apps/v1\nkind: Deployment\nmetadata: {name: train, namespace: jobs}\n",
+		"README.md":           "# not a manifest",
+		"templates/notes.txt": "not a manifest either",
+		"rendered.out":        "apiVersion: v1\nkind: ConfigMap\nmetadata: {name: settings}\n",
+	})
+	tests := []struct {
+		name  string
+		paths []string
+		stdin string
+		want  []string
+	}{
+		{
+			name:  "directory",
+			paths: []string{dir},
+			want: []string{
+				"Dataset default/imagenet",
+				"AlluxioRuntime fluid/imagenet",
+				"Deployment jobs/train",
+				"PersistentVolume pv-data",
+				"StorageClass fast",
+				"Service default/web",
+			},
+		},
+		{
+			name:  "file with any extension",
+			paths: []string{filepath.Join(dir, "rendered.out")},
+			want:  []string{"ConfigMap default/settings"},
+		},
+		{
+			name:  "stdin and file",
+			paths: []string{"-", filepath.Join(dir, "nested", "deploy.yml")},
+			stdin: "kind: Pod\napiVersion: v1\nmetadata:\n  name: web-0\n",
+			want:  []string{"Pod default/web-0", "Deployment jobs/train"},
+		},
+		{
+			name:  "empty stdin",
+			paths: []string{"-"},
+			stdin: "",
+			want:  []string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			objs, err := Load(tt.paths, strings.NewReader(tt.stdin), "default")
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := make([]string, 0, len(objs))
+			for _, u := range objs {
+				id := u.GetName()
+				if u.GetNamespace() != "" {
+					id = u.GetNamespace() + "/" + id
+				}
+				got = append(got, u.GetKind()+" "+id)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("Load = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadKeepsIntegers(t *testing.T) {
+	// The unstructured helpers expect int64, as the API server returns.
+	objs, err := Load([]string{"-"}, strings.NewReader(dataset), "default")
+	if err != nil {
+		t.Fatal(err)
+	}
+	spec := objs[1].Object["spec"].(map[string]interface{})
+	if _, ok := spec["replicas"].(int64); !ok {
+		t.Errorf("replicas is a %T, want int64", spec["replicas"])
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"bad.yaml":    "apiVersion: v1\nkind: Pod\n---\nmetadata: [\n",
+		"nokind.yaml": "apiVersion: v1\nmetadata: {name: x}\n",
+	})
+	tests := []struct {
+		name string
+		path string
+		want string
+	}{
+		{"invalid yaml", filepath.Join(dir, "bad.yaml"), "bad.yaml: document 2"},
+		{"missing kind", filepath.Join(dir, "nokind.yaml"), "nokind.yaml: document 1"},
+		{"missing file", filepath.Join(dir, "missing.yaml"), "missing.yaml"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Load([]string{tt.path}, nil, "default")
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Load = %v, want an error containing %q", err, tt.want)
+			}
+		})
+	}
+}
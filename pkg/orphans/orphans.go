@@ -0,0 +1,274 @@
+// Package orphans finds leftover Fluid and Kubernetes objects: objects
+// whose owner or target is gone, and objects nothing uses anymore.
+package orphans
+
+import (
+	"7h3-3mp7y-m4n/kubectl-graph/pkg/format"
+	"7h3-3mp7y-m4n/kubectl-graph/pkg/inventory"
+	"bufio"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/fatih/color"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// Item is one leftover object.
+type Item struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Namespace  string `json:"namespace,omitempty"`
+	Name       string `json:"name"`
+	Reason     string `json:"reason"`
+}
+
+// ID returns the Kind/namespace/name identifier of the item.
+func (i Item) ID() string {
+	if i.Namespace == "" {
+		return i.Kind + "/" + i.Name
+	}
+	return i.Kind + "/" + i.Namespace + "/" + i.Name
+}
+
+// Category is a kind of leftover and the objects found of it.
+type Category struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Items       []Item `json:"items"`
+	// Unknown is set when the objects needed to judge the category could
+	// not be listed.
+	Unknown string `json:"unknown,omitempty"`
+}
+
+// Report is the result of Find, and what orphans -o json prints. Every
+// category is listed, empty or not, so that scripts can rely on them.
+type Report struct {
+	// Namespace is the namespace searched, empty for all of them.
+	Namespace  string     `json:"namespace,omitempty"`
+	Categories []Category `json:"categories"`
+	Skipped    []string   `json:"skipped,omitempty"`
+}
+
+var datasetsResource = schema.GroupResource{Group: inventory.FluidGroup, Resource: "datasets"}
+
+// check finds the objects of one category in inv, loaded for namespace.
+// needs lists the resources whose absence the check relies on.
+type check struct {
+	name, description string
+	needs             []schema.GroupResource
+	find              func(inv *inventory.Inventory, namespace string) []Item
+}
+
+var checks = []check{
+	{
+		name:        "datasetPods",
+		description: "Pods labeled for a dataset that does not exist",
+		needs:       []schema.GroupResource{datasetsResource},
+		find: func(inv *inventory.Inventory, namespace string) []Item {
+			return withoutDataset(inv, "Pod")
+		},
+	},
+	{
+		name:        "datasetClaims",
+		description: "PersistentVolumeClaims labeled for a dataset that does not exist",
+		needs:       []schema.GroupResource{datasetsResource},
+		find: func(inv *inventory.Inventory, namespace string) []Item {
+			return withoutDataset(inv, "PersistentVolumeClaim")
+		},
+	},
+	{
+		name:        "runtimesWithoutDataset",
+		description: "Fluid runtimes with no dataset of the same name",
+		needs:       []schema.GroupResource{datasetsResource},
+		find: func(inv *inventory.Inventory, namespace string) []Item {
+			var items []Item
+			for _, o := range inv.Objects() {
+				if !o.IsFluidRuntime() {
+					continue
+				}
+				if _, ok := inv.Get(inventory.FluidGroup, "Dataset", o.GetNamespace(), o.GetName()); !ok {
+					items = append(items, item(o, fmt.Sprintf("dataset '%s' does not exist", o.GetName())))
+				}
+			}
+			return items
+		},
+	},
+	{
+		name:        "emptyReplicaSets",
+		description: "ReplicaSets scaled to zero",
+		find: func(inv *inventory.Inventory, namespace string) []Item {
+			var items []Item
+			for _, o := range inv.OfKind("apps", "ReplicaSet") {
+				replicas, found, _ := unstructured.NestedInt64(o.Object, "spec", "replicas")
+				if !found || replicas != 0 {
+					continue
+				}
+				reason := "scaled to zero and owned by nothing"
+				if owner, ok := inv.Controller(o); ok {
+					reason = fmt.Sprintf("scaled to zero, kept as revision history of %s", owner.Ref())
+				}
+				items = append(items, item(o, reason))
+			}
+			return items
+		},
+	},
+	{
+		name:        "unmatchedServices",
+		description: "Services whose selector matches no pods",
+		needs:       []schema.GroupResource{{Resource: "pods"}},
+		find: func(inv *inventory.Inventory, namespace string) []Item {
+			var items []Item
+			for _, o := range inv.OfKind("", "Service") {
+				// Services without a selector have their endpoints managed
+				// by hand or point outside the cluster.
+				selector := inventory.Selector(o)
+				if len(selector) == 0 || len(inv.Select("", "Pod", o.GetNamespace(), selector)) > 0 {
+					continue
+				}
+				items = append(items, item(o, fmt.Sprintf("selector %s matches no pods", inventory.FormatSelector(selector))))
+			}
+			return items
+		},
+	},
+	{
+		name:        "unmountedClaims",
+		description: "PersistentVolumeClaims no pod mounts",
+		needs:       []schema.GroupResource{{Resource: "pods"}},
+		find: func(inv *inventory.Inventory, namespace string) []Item {
+			mounted := make(map[string]bool)
+			for _, pod := range inv.OfKind("", "Pod") {
+				for _, claim := range inventory.ClaimNames(pod) {
+					mounted[pod.GetNamespace()+"/"+claim] = true
+				}
+			}
+			var items []Item
+			for _, o := range inv.OfKind("", "PersistentVolumeClaim") {
+				if mounted[o.GetNamespace()+"/"+o.GetName()] {
+					continue
+				}
+				phase, _, _ := unstructured.NestedString(o.Object, "status", "phase")
+				items = append(items, item(o, fmt.Sprintf("%s and mounted by no pod", strings.ToLower(orDefault(phase, "unknown")))))
+			}
+			return items
+		},
+	},
+	{
+		name:        "releasedVolumes",
+		description: "PersistentVolumes released by their claim",
+		find: func(inv *inventory.Inventory, namespace string) []Item {
+			var items []Item
+			for _, o := range inv.OfKind("", "PersistentVolume") {
+				if phase, _, _ := unstructured.NestedString(o.Object, "status", "phase"); phase != "Released" {
+					continue
+				}
+				// Volumes are cluster-scoped; a namespace has the ones its
+				// claims released.
+				claimNS, _, _ := unstructured.NestedString(o.Object, "spec", "claimRef", "namespace")
+				if namespace != "" && claimNS != namespace {
+					continue
+				}
+				claim, _, _ := unstructured.NestedString(o.Object, "spec", "claimRef", "name")
+				policy, _, _ := unstructured.NestedString(o.Object, "spec", "persistentVolumeReclaimPolicy")
+				items = append(items, item(o, fmt.Sprintf("released by %s/%s, reclaim policy %s", claimNS, claim, orDefault(policy, "unknown"))))
+			}
+			return items
+		},
+	},
+}
+
+// Find returns the leftovers in inv, which was loaded for namespace.
+func Find(inv *inventory.Inventory, namespace string) *Report {
+	r := &Report{Namespace: namespace, Skipped: inv.Skipped}
+	for _, c := range checks {
+		category := Category{Name: c.name, Description: c.description, Items: []Item{}}
+		if i := slices.IndexFunc(c.needs, inv.Failed); i >= 0 {
+			category.Unknown = fmt.Sprintf("%s could not be listed", c.needs[i])
+		} else {
+			category.Items = append(category.Items, c.find(inv, namespace)...)
+		}
+		r.Categories = append(r.Categories, category)
+	}
+	return r
+}
+
+// withoutDataset returns the objects of kind labeled for a dataset that
+// does not exist.
+func withoutDataset(inv *inventory.Inventory, kind string) []Item {
+	var items []Item
+	for _, o := range inv.OfKind("", kind) {
+		name, ok := o.GetLabels()[inventory.FluidDatasetLabel]
+		if !ok {
+			continue
+		}
+		if _, ok := inv.Get(inventory.FluidGroup, "Dataset", o.GetNamespace(), name); !ok {
+			items = append(items, item(o, fmt.Sprintf("labeled %s=%s but the dataset does not exist", inventory.FluidDatasetLabel, name)))
+		}
+	}
+	return items
+}
+
+func item(o *inventory.Object, reason string) Item {
+	return Item{APIVersion: o.GetAPIVersion(), Kind: o.GetKind(), Namespace: o.GetNamespace(), Name: o.GetName(), Reason: reason}
+}
+
+func orDefault(s, def string) string {
+	if s == "" {
+		return def
+	}
+	return s
+}
+
+// Print writes r for people, one section per category with leftovers.
+func (r *Report) Print(out io.Writer, colored bool) error {
+	paint := func(s string, attrs ...color.Attribute) string {
+		if !colored {
+			return s
+		}
+		c := color.New(attrs...)
+		c.EnableColor()
+		return c.Sprint(s)
+	}
+	w := bufio.NewWriter(out)
+	scope := "all namespaces"
+	if r.Namespace != "" {
+		scope = "namespace " + r.Namespace
+	}
+	total := 0
+	for _, c := range r.Categories {
+		total += len(c.Items)
+	}
+	fmt.Fprintln(w, paint(fmt.Sprintf("Leftover resources in %s (%d)", scope, total), color.FgCyan, color.Bold))
+	fmt.Fprintln(w)
+	for _, c := range r.Categories {
+		switch {
+		case c.Unknown != "":
+			fmt.Fprintln(w, paint(c.Description, color.FgYellow, color.Bold))
+			fmt.Fprintf(w, "  Not checked: %s\n\n", c.Unknown)
+			continue
+		case len(c.Items) == 0:
+			continue
+		}
+		fmt.Fprintln(w, paint(fmt.Sprintf("%s (%d)", c.Description, len(c.Items)), color.FgYellow, color.Bold))
+		width := 0
+		for _, i := range c.Items {
+			width = max(width, format.DisplayWidth(i.ID()))
+		}
+		for _, i := range c.Items {
+			fmt.Fprintf(w, "  %s  %s\n", format.PadRight(i.ID(), width), i.Reason)
+		}
+		fmt.Fprintln(w)
+	}
+	if total == 0 {
+		fmt.Fprintln(w, "No leftovers found.")
+	}
+	if len(r.Skipped) > 0 {
+		fmt.Fprintf(w, "Warning: %d resources could not be read:\n", len(r.Skipped))
+		for _, s := range r.Skipped {
+			fmt.Fprintf(w, "  %s\n", s)
+		}
+	}
+	return w.Flush()
+}
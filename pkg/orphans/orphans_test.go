@@ -0,0 +1,250 @@
+package orphans
+
+import (
+	"7h3-3mp7y-m4n/kubectl-graph/pkg/client"
+	"7h3-3mp7y-m4n/kubectl-graph/pkg/inventory"
+	"7h3-3mp7y-m4n/kubectl-graph/pkg/inventory/inventorytest"
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// The dataset imagenet has its runtime, pod and claim. Everything named
+// "gone" is left over from a deleted dataset.
+const leftovers = `apiVersion: data.fluid.io/v1alpha1
+kind: Dataset
+metadata: {name: imagenet, namespace: default}
+---
+apiVersion: data.fluid.io/v1alpha1
+kind: AlluxioRuntime
+metadata: {name: imagenet, namespace: default}
+---
+apiVersion: data.fluid.io/v1alpha1
+kind: AlluxioRuntime
+metadata: {name: gone, namespace: default}
+---
+apiVersion: v1
+kind: Pod
+metadata:
+  name: imagenet-worker-0
+  namespace: default
+  labels: {fluid.io/dataset: imagenet, app: web}
+spec:
+  volumes:
+  - {name: data, persistentVolumeClaim: {claimName: imagenet}}
+---
+apiVersion: v1
+kind: Pod
+metadata:
+  name: gone-worker-0
+  namespace: default
+  labels: {fluid.io/dataset: gone}
+---
+apiVersion: v1
+kind: PersistentVolumeClaim
+metadata:
+  name: imagenet
+  namespace: default
+  labels: {fluid.io/dataset: imagenet}
+status: {phase: Bound}
+---
+apiVersion: v1
+kind: PersistentVolumeClaim
+metadata:
+  name: gone
+  namespace: default
+  labels: {fluid.io/dataset: gone}
+status: {phase: Bound}
+---
+apiVersion: v1
+kind: PersistentVolumeClaim
+metadata: {name: scratch, namespace: default}
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata: {name: web, namespace: default, uid: d1}
+---
+apiVersion: apps/v1
+kind: ReplicaSet
+metadata:
+  name: web-old
+  namespace: default
+  ownerReferences:
+  - {apiVersion: apps/v1, kind: Deployment, name: web, uid: d1, controller: true}
+spec: {replicas: 0}
+---
+apiVersion: apps/v1
+kind: ReplicaSet
+metadata: {name: stray, namespace: default}
+spec: {replicas: 0}
+---
+apiVersion: apps/v1
+kind: ReplicaSet
+metadata: {name: web-new, namespace: default}
+spec: {replicas: 2}
+---
+apiVersion: v1
+kind: Service
+metadata: {name: web, namespace: default}
+spec: {selector: {app: web}}
+---
+apiVersion: v1
+kind: Service
+metadata: {name: lonely, namespace: default}
+spec: {selector: {app: nothing}}
+---
+apiVersion: v1
+kind: Service
+metadata: {name: external, namespace: default}
+spec: {type: ExternalName, externalName: example.com}
+---
+apiVersion: v1
+kind: PersistentVolume
+metadata: {name: pv-default}
+spec:
+  persistentVolumeReclaimPolicy: Retain
+  claimRef: {namespace: default, name: old}
+status: {phase: Released}
+---
+apiVersion: v1
+kind: PersistentVolume
+metadata: {name: pv-other}
+spec:
+  claimRef: {namespace: other, name: old}
+status: {phase: Released}
+---
+apiVersion: v1
+kind: PersistentVolume
+metadata: {name: pv-bound}
+spec:
+  claimRef: {namespace: default, name: imagenet}
+status: {phase: Bound}`
+
+// items returns the items of each category as "ID: reason", and the
+// reason categories are unknown as "unknown: reason".
+func items(r *Report) map[string][]string {
+	out := make(map[string][]string)
+	for _, c := range r.Categories {
+		if c.Unknown != "" {
+			out[c.Name] = []string{"unknown: " + c.Unknown}
+			continue
+		}
+		out[c.Name] = []string{}
+		for _, item := range c.Items {
+			out[c.Name] = append(out[c.Name], item.ID()+": "+item.Reason)
+		}
+	}
+	return out
+}
+
+func TestFind(t *testing.T) {
+	inv := inventory.New(inventorytest.Objects(t, leftovers))
+	tests := []struct {
+		category string
+		want     []string
+	}{
+		{"datasetPods", []string{
+			"Pod/default/gone-worker-0: labeled fluid.io/dataset=gone but the dataset does not exist",
+		}},
+		{"datasetClaims", []string{
+			"PersistentVolumeClaim/default/gone: labeled fluid.io/dataset=gone but the dataset does not exist",
+		}},
+		{"runtimesWithoutDataset", []string{
+			"AlluxioRuntime/default/gone: dataset 'gone' does not exist",
+		}},
+		{"emptyReplicaSets", []string{
+			"ReplicaSet/default/web-old: scaled to zero, kept as revision history of Deployment/web",
+			"ReplicaSet/default/stray: scaled to zero and owned by nothing",
+		}},
+		{"unmatchedServices", []string{
+			"Service/default/lonely: selector app=nothing matches no pods",
+		}},
+		{"unmountedClaims", []string{
+			"PersistentVolumeClaim/default/gone: bound and mounted by no pod",
+			"PersistentVolumeClaim/default/scratch: unknown and mounted by no pod",
+		}},
+		{"releasedVolumes", []string{
+			"PersistentVolume/pv-default: released by default/old, reclaim policy Retain",
+		}},
+	}
+	got := items(Find(inv, "default"))
+	if len(got) != len(tests) {
+		t.Errorf("%d categories, want %d", len(got), len(tests))
+	}
+	for _, tt := range tests {
+		t.Run(tt.category, func(t *testing.T) {
+			if !slices.Equal(got[tt.category], tt.want) {
+				t.Errorf("items =\n  %s\nwant\n  %s", strings.Join(got[tt.category], "\n  "), strings.Join(tt.want, "\n  "))
+			}
+		})
+	}
+}
+
+func TestFindReleasedVolumesInAllNamespaces(t *testing.T) {
+	inv := inventory.New(inventorytest.Objects(t, leftovers))
+	want := []string{
+		"PersistentVolume/pv-default: released by default/old, reclaim policy Retain",
+		"PersistentVolume/pv-other: released by other/old, reclaim policy unknown",
+	}
+	if got := items(Find(inv, ""))["releasedVolumes"]; !slices.Equal(got, want) {
+		t.Errorf("items = %v, want %v", got, want)
+	}
+}
+
+func TestFindUnknown(t *testing.T) {
+	tests := []struct {
+		failing string
+		want    map[string]string
+	}{
+		{
+			failing: "pods",
+			want: map[string]string{
+				"unmatchedServices": "pods could not be listed",
+				"unmountedClaims":   "pods could not be listed",
+			},
+		},
+		{
+			failing: "datasets",
+			want: map[string]string{
+				"datasetPods":            "datasets.data.fluid.io could not be listed",
+				"datasetClaims":          "datasets.data.fluid.io could not be listed",
+				"runtimesWithoutDataset": "datasets.data.fluid.io could not be listed",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.failing, func(t *testing.T) {
+			c, err := client.NewInMemory(inventorytest.Unstructured(t, leftovers))
+			if err != nil {
+				t.Fatal(err)
+			}
+			c.DynamicClient.(*dynamicfake.FakeDynamicClient).PrependReactor("list", tt.failing,
+				func(k8stesting.Action) (bool, runtime.Object, error) {
+					return true, nil, errors.New("forbidden")
+				})
+			inv, err := inventory.Load(context.Background(), c, "default", "persistentvolumes")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			r := Find(inv, "default")
+			if len(r.Skipped) != 1 || !strings.HasPrefix(r.Skipped[0], tt.failing) {
+				t.Errorf("skipped = %v, want %s", r.Skipped, tt.failing)
+			}
+			for _, c := range r.Categories {
+				if c.Unknown != tt.want[c.Name] {
+					t.Errorf("%s unknown = %q, want %q", c.Name, c.Unknown, tt.want[c.Name])
+				}
+				if c.Unknown != "" && len(c.Items) != 0 {
+					t.Errorf("%s lists %d items although it is unknown", c.Name, len(c.Items))
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,187 @@
+package query
+
+import (
+	"strings"
+
+	"7h3-3mp7y-m4n/kubectl-graph/pkg/format"
+)
+
+type nodeSet map[*format.Resource]bool
+
+// Eval runs the query against g and returns the matching resources in the
+// order they appear in g.Nodes.
+func (q *Query) Eval(g *format.Graph) []*format.Resource {
+	matched := evalPipeline(g, q.root)
+	result := make([]*format.Resource, 0, len(matched))
+	for _, node := range g.Nodes() {
+		if matched[node] {
+			result = append(result, node)
+		}
+	}
+	return result
+}
+
+func evalPipeline(g *format.Graph, pl *pipeline) nodeSet {
+	set := evalPath(g, pl.path)
+	for _, preds := range pl.filters {
+		set = filter(set, preds)
+	}
+	return set
+}
+
+// evalPath walks the path from right to left so that every term only keeps
+// the nodes connected to a surviving node of the term after it.
+func evalPath(g *format.Graph, pth *path) nodeSet {
+	last := len(pth.terms) - 1
+	current := evalTerm(g, pth.terms[last])
+	for i := last - 1; i >= 0; i-- {
+		candidates := evalTerm(g, pth.terms[i])
+		a := pth.arrows[i]
+		next := make(nodeSet)
+		for _, edge := range g.Edges {
+			if a.edgeType != "" && !strings.EqualFold(string(edge.Type), a.edgeType) {
+				continue
+			}
+			if a.incoming {
+				if candidates[edge.To] && current[edge.From] {
+					next[edge.To] = true
+				}
+			} else if candidates[edge.From] && current[edge.To] {
+				next[edge.From] = true
+			}
+		}
+		current = next
+	}
+	return current
+}
+
+func evalTerm(g *format.Graph, t *term) nodeSet {
+	set := make(nodeSet)
+	switch {
+	case t.fn != "":
+		inner := evalPipeline(g, t.inner)
+		switch t.fn {
+		case "children":
+			set = step(g, inner, true)
+		case "parents":
+			set = step(g, inner, false)
+		case "descendants":
+			set = reach(g, inner, true)
+		case "ancestors":
+			set = reach(g, inner, false)
+		}
+	default:
+		for _, node := range g.Nodes() {
+			if t.any || node.Type == t.kind {
+				set[node] = true
+			}
+		}
+	}
+	return filter(set, t.predicates)
+}
+
+// step returns the direct neighbours of from, following edges forwards or
+// backwards.
+func step(g *format.Graph, from nodeSet, forward bool) nodeSet {
+	next := make(nodeSet)
+	for _, edge := range g.Edges {
+		if forward && from[edge.From] {
+			next[edge.To] = true
+		} else if !forward && from[edge.To] {
+			next[edge.From] = true
+		}
+	}
+	return next
+}
+
+// reach returns every node reachable from start in one or more steps.
+func reach(g *format.Graph, start nodeSet, forward bool) nodeSet {
+	seen := make(nodeSet)
+	frontier := start
+	for len(frontier) > 0 {
+		next := make(nodeSet)
+		for node := range step(g, frontier, forward) {
+			if !seen[node] {
+				seen[node] = true
+				next[node] = true
+			}
+		}
+		frontier = next
+	}
+	return seen
+}
+
+func filter(set nodeSet, preds []predicate) nodeSet {
+	if len(preds) == 0 {
+		return set
+	}
+	out := make(nodeSet)
+	for node := range set {
+		if matchesAll(node, preds) {
+			out[node] = true
+		}
+	}
+	return out
+}
+
+func matchesAll(res *format.Resource, preds []predicate) bool {
+	for _, pred := range preds {
+		if !pred.matches(res) {
+			return false
+		}
+	}
+	return true
+}
+
+func (pred predicate) matches(res *format.Resource) bool {
+	actual := attribute(res, pred.attr)
+	var equal bool
+	switch pred.attr {
+	case "kind", "type":
+		want, ok := format.ParseResourceType(pred.value)
+		equal = ok && res.Type == want || strings.EqualFold(actual, pred.value)
+	case "status":
+		equal = strings.EqualFold(actual, pred.value)
+	default:
+		equal = actual == pred.value
+	}
+	switch pred.op {
+	case "!=":
+		return !equal
+	case "=~":
+		return pred.re.MatchString(actual)
+	case "!~":
+		return !pred.re.MatchString(actual)
+	default:
+		return equal
+	}
+}
+
+func attribute(res *format.Resource, attr string) string {
+	switch attr {
+	case "kind", "type":
+		return string(res.Type)
+	case "name":
+		return res.Name
+	case "namespace":
+		return res.Namespace
+	case "status":
+		return res.Status
+	}
+	if key, ok := cutPrefix(attr, "label.", "labels."); ok {
+		return res.Labels[key]
+	}
+	if key, ok := cutPrefix(attr, "detail.", "details."); ok {
+		return res.DetailString(key)
+	}
+	return ""
+}
+
+func cutPrefix(s string, prefixes ...string) (string, bool) {
+	for _, prefix := range prefixes {
+		if rest, ok := strings.CutPrefix(s, prefix); ok {
+			return rest, true
+		}
+	}
+	return "", false
+}
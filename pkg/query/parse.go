@@ -0,0 +1,328 @@
+package query
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"7h3-3mp7y-m4n/kubectl-graph/pkg/format"
+)
+
+// Query is a parsed graph expression.
+//
+// The grammar is:
+//
+//	query     = pipeline
+//	pipeline  = path { "|" predicate { "," predicate } }
+//	path      = term { arrow term }
+//	arrow     = "<-" | "<-" edgeType "-" | "->" | "-" [edgeType] "->"
+//	term      = ( kind | "*" | func "(" pipeline ")" ) [ "[" predicate { "," predicate } "]" ]
+//	func      = "descendants" | "ancestors" | "children" | "parents"
+//	predicate = attribute ( "=" | "==" | "!=" | "=~" | "!~" ) value
+//
+// Attributes are kind, name, namespace, status, label.<key> and
+// details.<key>. A path returns the nodes matched by its leftmost term that
+// are connected through every following arrow, so
+// 'Pod[status!=Running] <-labelSelector- Dataset' lists pods that are not
+// running and are selected by a dataset, and 'Pod <- Dataset' lists the
+// pods pointed to by a dataset through any relationship. The edge type of an
+// incoming arrow is written without spaces, so in
+// 'PVC <- Pod -volumeMount-> PVC' the "-" after Pod starts the next arrow.
+type Query struct {
+	root *pipeline
+}
+
+type pipeline struct {
+	path    *path
+	filters [][]predicate
+}
+
+type path struct {
+	terms  []*term
+	arrows []arrow
+}
+
+type arrow struct {
+	incoming bool
+	edgeType string
+}
+
+type term struct {
+	kind       format.ResourceType
+	any        bool
+	fn         string
+	inner      *pipeline
+	predicates []predicate
+}
+
+type predicate struct {
+	attr  string
+	op    string
+	value string
+	re    *regexp.Regexp
+}
+
+var functions = map[string]bool{
+	"descendants": true,
+	"ancestors":   true,
+	"children":    true,
+	"parents":     true,
+}
+
+// Parse parses a query expression.
+func Parse(expr string) (*Query, error) {
+	p := &parser{input: expr}
+	pl, err := p.parsePipeline()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpace()
+	if !p.eof() {
+		return nil, p.errorf("unexpected %q", p.rest())
+	}
+	return &Query{root: pl}, nil
+}
+
+type parser struct {
+	input string
+	pos   int
+}
+
+func (p *parser) eof() bool {
+	return p.pos >= len(p.input)
+}
+
+func (p *parser) rest() string {
+	return p.input[p.pos:]
+}
+
+func (p *parser) errorf(msg string, args ...interface{}) error {
+	return fmt.Errorf("query: at offset %d: %s", p.pos, fmt.Sprintf(msg, args...))
+}
+
+func (p *parser) skipSpace() {
+	for !p.eof() && unicode.IsSpace(rune(p.input[p.pos])) {
+		p.pos++
+	}
+}
+
+func (p *parser) consume(token string) bool {
+	p.skipSpace()
+	if strings.HasPrefix(p.rest(), token) {
+		p.pos += len(token)
+		return true
+	}
+	return false
+}
+
+func (p *parser) parsePipeline() (*pipeline, error) {
+	pth, err := p.parsePath()
+	if err != nil {
+		return nil, err
+	}
+	pl := &pipeline{path: pth}
+	for p.consume("|") {
+		preds, err := p.parsePredicates()
+		if err != nil {
+			return nil, err
+		}
+		pl.filters = append(pl.filters, preds)
+	}
+	return pl, nil
+}
+
+func (p *parser) parsePath() (*path, error) {
+	t, err := p.parseTerm()
+	if err != nil {
+		return nil, err
+	}
+	pth := &path{terms: []*term{t}}
+	for {
+		a, ok, err := p.parseArrow()
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return pth, nil
+		}
+		t, err := p.parseTerm()
+		if err != nil {
+			return nil, err
+		}
+		pth.arrows = append(pth.arrows, a)
+		pth.terms = append(pth.terms, t)
+	}
+}
+
+func (p *parser) parseArrow() (arrow, bool, error) {
+	switch {
+	case p.consume("<-"):
+		// A bare "<-" is followed by the next term, as "->" is, so an
+		// identifier names an edge type only when a "-" closes it right
+		// away, as in "<-labelSelector-".
+		if strings.HasPrefix(p.rest(), "-") {
+			return arrow{}, false, p.errorf("expected an edge type between '<-' and '-'")
+		}
+		start := p.pos
+		edgeType := p.readName()
+		if edgeType == "" || !strings.HasPrefix(p.rest(), "-") || strings.HasPrefix(p.rest(), "->") {
+			p.pos = start
+			return arrow{incoming: true}, true, nil
+		}
+		p.pos++
+		return arrow{incoming: true, edgeType: edgeType}, true, nil
+	case p.consume("->"):
+		return arrow{}, true, nil
+	case p.consume("-"):
+		edgeType := p.readIdent()
+		if !p.consume("->") {
+			return arrow{}, false, p.errorf("expected '->' to close outgoing edge")
+		}
+		return arrow{edgeType: edgeType}, true, nil
+	}
+	return arrow{}, false, nil
+}
+
+func (p *parser) parseTerm() (*term, error) {
+	t := &term{}
+	if p.consume("*") {
+		t.any = true
+	} else {
+		name := p.readIdent()
+		if name == "" {
+			p.skipSpace()
+			if p.eof() {
+				return nil, p.errorf("unexpected end of query")
+			}
+			return nil, p.errorf("expected a kind or function, got %q", p.rest())
+		}
+		if functions[strings.ToLower(name)] && p.consume("(") {
+			inner, err := p.parsePipeline()
+			if err != nil {
+				return nil, err
+			}
+			if !p.consume(")") {
+				return nil, p.errorf("expected ')' after %s(", name)
+			}
+			t.fn = strings.ToLower(name)
+			t.inner = inner
+		} else {
+			kind, ok := format.ParseResourceType(name)
+			if !ok {
+				return nil, fmt.Errorf("query: unknown kind %q", name)
+			}
+			t.kind = kind
+		}
+	}
+	if p.consume("[") {
+		preds, err := p.parsePredicates()
+		if err != nil {
+			return nil, err
+		}
+		if !p.consume("]") {
+			return nil, p.errorf("expected ']'")
+		}
+		t.predicates = preds
+	}
+	return t, nil
+}
+
+func (p *parser) parsePredicates() ([]predicate, error) {
+	preds := make([]predicate, 0)
+	for {
+		pred, err := p.parsePredicate()
+		if err != nil {
+			return nil, err
+		}
+		preds = append(preds, pred)
+		if !p.consume(",") {
+			return preds, nil
+		}
+	}
+}
+
+func (p *parser) parsePredicate() (predicate, error) {
+	p.skipSpace()
+	start := p.pos
+	for !p.eof() && !strings.ContainsRune("=!~,|[]() \t\n", rune(p.input[p.pos])) {
+		p.pos++
+	}
+	attr := p.input[start:p.pos]
+	if attr == "" {
+		return predicate{}, p.errorf("expected an attribute name")
+	}
+	var op string
+	for _, candidate := range []string{"==", "!=", "=~", "!~", "="} {
+		if p.consume(candidate) {
+			op = candidate
+			break
+		}
+	}
+	if op == "" {
+		return predicate{}, p.errorf("expected an operator after %q", attr)
+	}
+	if op == "==" {
+		op = "="
+	}
+	value, err := p.readValue()
+	if err != nil {
+		return predicate{}, err
+	}
+	pred := predicate{attr: normalizeAttribute(attr), op: op, value: value}
+	if op == "=~" || op == "!~" {
+		re, err := regexp.Compile(value)
+		if err != nil {
+			return predicate{}, fmt.Errorf("query: invalid pattern %q: %w", value, err)
+		}
+		pred.re = re
+	}
+	return pred, nil
+}
+
+// normalizeAttribute lowercases the attribute name while keeping label and
+// detail keys as written, since those are case-sensitive.
+func normalizeAttribute(attr string) string {
+	if prefix, key, ok := strings.Cut(attr, "."); ok {
+		return strings.ToLower(prefix) + "." + key
+	}
+	return strings.ToLower(attr)
+}
+
+func (p *parser) readIdent() string {
+	p.skipSpace()
+	return p.readName()
+}
+
+// readName reads an identifier at the current position, without skipping
+// spaces before it.
+func (p *parser) readName() string {
+	start := p.pos
+	for !p.eof() {
+		c := rune(p.input[p.pos])
+		if !unicode.IsLetter(c) && !unicode.IsDigit(c) && c != '_' {
+			break
+		}
+		p.pos++
+	}
+	return p.input[start:p.pos]
+}
+
+func (p *parser) readValue() (string, error) {
+	p.skipSpace()
+	if !p.eof() && (p.input[p.pos] == '"' || p.input[p.pos] == '\'') {
+		quote := p.input[p.pos]
+		end := strings.IndexByte(p.input[p.pos+1:], quote)
+		if end < 0 {
+			return "", p.errorf("unterminated string")
+		}
+		value := p.input[p.pos+1 : p.pos+1+end]
+		p.pos += end + 2
+		return value, nil
+	}
+	start := p.pos
+	for !p.eof() && !strings.ContainsRune(",|[]() \t\n", rune(p.input[p.pos])) {
+		p.pos++
+	}
+	return p.input[start:p.pos], nil
+}
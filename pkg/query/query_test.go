@@ -0,0 +1,157 @@
+package query
+
+import (
+	"7h3-3mp7y-m4n/kubectl-graph/pkg/format"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// testGraph is a dataset with a runtime, two pods sharing a claim and a
+// service.
+func testGraph() *format.Graph {
+	dataset := &format.Resource{Type: format.ResourceTypeDataset, Name: "imagenet", Namespace: "default", Status: "Bound"}
+	runtime := &format.Resource{Type: format.ResourceTypeRuntime, Name: "imagenet", Namespace: "default", Status: "Ready"}
+	worker0 := &format.Resource{
+		Type: format.ResourceTypePod, Name: "imagenet-worker-0", Namespace: "default", Status: "Running",
+		Labels:  map[string]string{"app": "web"},
+		Details: []format.Detail{format.IntDetail("restarts", 0, "", format.DetailPriorityPrimary)},
+	}
+	worker1 := &format.Resource{
+		Type: format.ResourceTypePod, Name: "imagenet-worker-1", Namespace: "default", Status: "CrashLoopBackOff",
+		Details: []format.Detail{format.IntDetail("restarts", 7, "", format.DetailPriorityPrimary)},
+	}
+	pvc := &format.Resource{Type: format.ResourceTypePVC, Name: "imagenet", Namespace: "default", Status: "Bound"}
+	svc := &format.Resource{Type: format.ResourceTypeService, Name: "imagenet-master-0", Namespace: "default", Status: "Active"}
+
+	g := format.NewGraph(dataset)
+	for _, res := range []*format.Resource{runtime, worker0, worker1, pvc, svc} {
+		g.AddResource(res)
+	}
+	g.AddEdge(dataset, runtime, format.EdgeTypeRuntimeBinding, "metadata.name=imagenet")
+	g.AddEdge(dataset, pvc, format.EdgeTypeLabelSelector, "fluid.io/dataset=imagenet")
+	g.AddEdge(dataset, worker0, format.EdgeTypeLabelSelector, "fluid.io/dataset=imagenet")
+	g.AddEdge(dataset, worker1, format.EdgeTypeLabelSelector, "fluid.io/dataset=imagenet")
+	g.AddEdge(dataset, svc, format.EdgeTypeLabelSelector, "fluid.io/dataset=imagenet")
+	g.AddEdge(worker0, pvc, format.EdgeTypeVolumeMount, "spec.volumes[0].persistentVolumeClaim.claimName")
+	g.AddEdge(worker1, pvc, format.EdgeTypeVolumeMount, "spec.volumes[0].persistentVolumeClaim.claimName")
+	return g
+}
+
+func ids(resources []*format.Resource) []string {
+	out := make([]string, 0, len(resources))
+	for _, res := range resources {
+		out = append(out, string(res.Type)+"/"+res.Name)
+	}
+	return out
+}
+
+func TestEval(t *testing.T) {
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"Pod", []string{"Pod/imagenet-worker-0", "Pod/imagenet-worker-1"}},
+		{"pods", []string{"Pod/imagenet-worker-0", "Pod/imagenet-worker-1"}},
+		{"Pod[status!=Running]", []string{"Pod/imagenet-worker-1"}},
+		{"Pod[status=running]", []string{"Pod/imagenet-worker-0"}},
+		{"Pod[details.restarts=7]", []string{"Pod/imagenet-worker-1"}},
+		{"Pod[label.app=web]", []string{"Pod/imagenet-worker-0"}},
+		{"*[name=~'^imagenet-worker']", []string{"Pod/imagenet-worker-0", "Pod/imagenet-worker-1"}},
+		{"*[name!~worker, kind!=Dataset]", []string{"Runtime/imagenet", "PersistentVolumeClaim/imagenet", "Service/imagenet-master-0"}},
+		{"Pod[status!=Running] <-labelSelector- Dataset", []string{"Pod/imagenet-worker-1"}},
+		{"Pod <- Dataset", []string{"Pod/imagenet-worker-0", "Pod/imagenet-worker-1"}},
+		{"Pod <-runtimeBinding- Dataset", []string{}},
+		{"Pod <- Runtime", []string{}},
+		{"Pod -volumeMount-> PVC", []string{"Pod/imagenet-worker-0", "Pod/imagenet-worker-1"}},
+		{"Pod[status=Running] -> PVC", []string{"Pod/imagenet-worker-0"}},
+		{"Dataset -> Pod -> PVC", []string{"Dataset/imagenet"}},
+		{"PVC <- Pod[status=CrashLoopBackOff] <- Dataset", []string{"PersistentVolumeClaim/imagenet"}},
+		{"PVC <- Pod -volumeMount-> PVC", []string{"PersistentVolumeClaim/imagenet"}},
+		{"PVC <-Pod -> PVC", []string{"PersistentVolumeClaim/imagenet"}},
+		{"PVC <-volumeMount- Pod[status=Running]", []string{"PersistentVolumeClaim/imagenet"}},
+		{"children(Dataset) | kind=PVC", []string{"PersistentVolumeClaim/imagenet"}},
+		{"parents(PVC)", []string{"Dataset/imagenet", "Pod/imagenet-worker-0", "Pod/imagenet-worker-1"}},
+		{"descendants(Pod)", []string{"PersistentVolumeClaim/imagenet"}},
+		{"ancestors(PVC) | kind=Pod, status=Running", []string{"Pod/imagenet-worker-0"}},
+		{"StatefulSet", []string{}},
+	}
+	g := testGraph()
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			q, err := Parse(tt.query)
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			if got := ids(q.Eval(g)); !slices.Equal(got, tt.want) {
+				t.Errorf("Eval = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"", "unexpected end of query"},
+		{"Pod <-", "unexpected end of query"},
+		{"Pod <-- Dataset", "expected an edge type between '<-' and '-'"},
+		{"Pod <- labelSelector- Dataset", `unknown kind "labelSelector"`},
+		{"Pod ->", "unexpected end of query"},
+		{"Pod -labelSelector", "expected '->' to close outgoing edge"},
+		{"Widget", `unknown kind "Widget"`},
+		{"Pod[status]", "expected an operator"},
+		{"Pod[status=Running", "expected ']'"},
+		{"Pod[name=~'(']", "invalid pattern"},
+		{"descendants(Pod", "expected ')'"},
+		{"Pod Runtime", "unexpected"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			_, err := Parse(tt.query)
+			if err == nil {
+				t.Fatalf("Parse(%q) succeeded, want an error containing %q", tt.query, tt.want)
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Parse(%q) = %v, want an error containing %q", tt.query, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestSubgraphRoot(t *testing.T) {
+	g := testGraph()
+	for _, tt := range []struct {
+		query string
+		root  bool
+		nodes int
+		edges int
+	}{
+		{"Pod[status!=Running]", false, 1, 0},
+		{"Pod | status=Running", false, 1, 0},
+		{"*[kind=Dataset] -> PVC", true, 1, 0},
+		{"Dataset -> Pod", true, 1, 0},
+		{"*[kind=PVC] <- *", false, 1, 0},
+		{"descendants(Dataset) | kind!=Service", false, 4, 2},
+		{"*", true, 6, 7},
+	} {
+		t.Run(tt.query, func(t *testing.T) {
+			q, err := Parse(tt.query)
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			sub := g.Subgraph(q.Eval(g))
+			if (sub.Root != nil) != tt.root {
+				t.Errorf("root kept = %v, want %v", sub.Root != nil, tt.root)
+			}
+			if got := len(sub.Nodes()); got != tt.nodes {
+				t.Errorf("%d nodes %v, want %d", got, ids(sub.Nodes()), tt.nodes)
+			}
+			if got := len(sub.Edges); got != tt.edges {
+				t.Errorf("%d edges, want %d", got, tt.edges)
+			}
+		})
+	}
+}
@@ -0,0 +1,205 @@
+package relations
+
+import (
+	"7h3-3mp7y-m4n/kubectl-graph/pkg/format"
+	"7h3-3mp7y-m4n/kubectl-graph/pkg/inventory"
+	"bufio"
+	"fmt"
+	"io"
+
+	"github.com/fatih/color"
+)
+
+// Hop is one relationship on a path.
+type Hop struct {
+	From     string          `json:"from"`
+	To       string          `json:"to"`
+	Type     format.EdgeType `json:"type"`
+	Evidence string          `json:"evidence"`
+	// Reverse is set when the relationship points from To back to From, as
+	// when a path goes from a pod up to its ReplicaSet.
+	Reverse bool `json:"reverse,omitempty"`
+}
+
+// BrokenRef is a reference near the two objects that leads nowhere.
+type BrokenRef struct {
+	Object string          `json:"object"`
+	Type   format.EdgeType `json:"type"`
+	Reason string          `json:"reason"`
+}
+
+// Report is the result of Path, and what path -o json prints.
+type Report struct {
+	From  string `json:"from"`
+	To    string `json:"to"`
+	Found bool   `json:"found"`
+	Hops  []Hop  `json:"hops"`
+	// Reachable counts the other objects connected to From and to To, when
+	// no path was found.
+	Reachable map[string]int `json:"reachable,omitempty"`
+	// Breaks lists the broken references among the objects connected to
+	// From or To, when no path was found.
+	Breaks  []BrokenRef `json:"breaks,omitempty"`
+	Skipped []string    `json:"skipped,omitempty"`
+}
+
+// cost weighs relationships by how directly they are recorded. Selectors
+// are matched here rather than recorded by the cluster, so a path through
+// the objects the cluster maintains, such as an EndpointSlice between a
+// Service and its pods, is preferred over a selector match.
+func cost(t format.EdgeType) int {
+	switch t {
+	case format.EdgeTypeServiceSelector, format.EdgeTypeLabelSelector:
+		return 3
+	}
+	return 1
+}
+
+type arc struct {
+	to  *inventory.Object
+	rel *Relation
+	// reverse is set when the arc follows rel against its direction.
+	reverse bool
+}
+
+// Path returns the cheapest chain of relationships between from and to in
+// inv, following relationships in either direction. When there is none,
+// the report lists where the chains starting from both objects break.
+func Path(inv *inventory.Inventory, from, to *inventory.Object) *Report {
+	rels, breaks := Find(inv)
+	adj := make(map[*inventory.Object][]arc)
+	for i := range rels {
+		r := &rels[i]
+		adj[r.From] = append(adj[r.From], arc{to: r.To, rel: r})
+		adj[r.To] = append(adj[r.To], arc{to: r.From, rel: r, reverse: true})
+	}
+
+	r := &Report{From: from.ID(), To: to.ID(), Hops: []Hop{}, Skipped: inv.Skipped}
+	prev := shortest(adj, from)
+	if _, ok := prev[to]; !ok {
+		fromSide := component(adj, from)
+		toSide := component(adj, to)
+		r.Reachable = map[string]int{r.From: len(fromSide) - 1, r.To: len(toSide) - 1}
+		for _, b := range breaks {
+			if fromSide[b.From] || toSide[b.From] {
+				r.Breaks = append(r.Breaks, BrokenRef{Object: b.From.ID(), Type: b.Type, Reason: b.Reason})
+			}
+		}
+		return r
+	}
+	r.Found = true
+	for o := to; o != from; {
+		a := prev[o]
+		hop := Hop{To: o.ID(), Type: a.rel.Type, Evidence: a.rel.Evidence, Reverse: a.reverse}
+		o = a.rel.From
+		if a.reverse {
+			o = a.rel.To
+		}
+		hop.From = o.ID()
+		r.Hops = append([]Hop{hop}, r.Hops...)
+	}
+	return r
+}
+
+// shortest runs Dijkstra's algorithm from start over adj and returns the
+// arc each reached object was reached by. Costs are small integers, so
+// the queue is a list of buckets indexed by distance.
+func shortest(adj map[*inventory.Object][]arc, start *inventory.Object) map[*inventory.Object]arc {
+	prev := map[*inventory.Object]arc{start: {}}
+	dist := map[*inventory.Object]int{start: 0}
+	done := make(map[*inventory.Object]bool)
+	buckets := [][]*inventory.Object{{start}}
+	for d := 0; d < len(buckets); d++ {
+		for i := 0; i < len(buckets[d]); i++ {
+			o := buckets[d][i]
+			if done[o] {
+				continue
+			}
+			done[o] = true
+			for _, a := range adj[o] {
+				nd := d + cost(a.rel.Type)
+				if old, ok := dist[a.to]; ok && old <= nd {
+					continue
+				}
+				dist[a.to] = nd
+				prev[a.to] = arc{to: o, rel: a.rel, reverse: a.reverse}
+				for len(buckets) <= nd {
+					buckets = append(buckets, nil)
+				}
+				buckets[nd] = append(buckets[nd], a.to)
+			}
+		}
+	}
+	return prev
+}
+
+// component returns the objects connected to start, start included.
+func component(adj map[*inventory.Object][]arc, start *inventory.Object) map[*inventory.Object]bool {
+	seen := map[*inventory.Object]bool{start: true}
+	queue := []*inventory.Object{start}
+	for len(queue) > 0 {
+		o := queue[0]
+		queue = queue[1:]
+		for _, a := range adj[o] {
+			if !seen[a.to] {
+				seen[a.to] = true
+				queue = append(queue, a.to)
+			}
+		}
+	}
+	return seen
+}
+
+// Print writes r for people: the chain from top to bottom with the kind
+// and evidence of each relationship, or where the chains break.
+func (r *Report) Print(out io.Writer, colored bool) error {
+	paint := func(s string, attrs ...color.Attribute) string {
+		if !colored {
+			return s
+		}
+		c := color.New(attrs...)
+		c.EnableColor()
+		return c.Sprint(s)
+	}
+	w := bufio.NewWriter(out)
+	if r.Found {
+		fmt.Fprintln(w, paint(fmt.Sprintf("Path from %s to %s (%d)", r.From, r.To, len(r.Hops)), color.FgCyan, color.Bold))
+		fmt.Fprintln(w)
+		fmt.Fprintf(w, "  %s\n", paint(r.From, color.Bold))
+		for _, h := range r.Hops {
+			edge := fmt.Sprintf("%s %s", paint(string(h.Type), color.FgYellow), h.Evidence)
+			if h.Reverse {
+				fmt.Fprintf(w, "  ▲\n  │ %s (points from %s)\n", edge, h.To)
+			} else {
+				fmt.Fprintf(w, "  │ %s\n  ▼\n", edge)
+			}
+			fmt.Fprintf(w, "  %s\n", paint(h.To, color.Bold))
+		}
+	} else {
+		fmt.Fprintln(w, paint(fmt.Sprintf("No path from %s to %s", r.From, r.To), color.FgRed, color.Bold))
+		fmt.Fprintln(w)
+		fmt.Fprintf(w, "  Objects connected to %s: %d\n", r.From, r.Reachable[r.From])
+		fmt.Fprintf(w, "  Objects connected to %s: %d\n", r.To, r.Reachable[r.To])
+		fmt.Fprintln(w, "  None of them are shared.")
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, paint(fmt.Sprintf("Where the chain breaks (%d)", len(r.Breaks)), color.FgYellow, color.Bold))
+		if len(r.Breaks) == 0 {
+			fmt.Fprintln(w, "  No broken references were found; nothing relates the two objects.")
+		}
+		width := 0
+		for _, b := range r.Breaks {
+			width = max(width, format.DisplayWidth(b.Object))
+		}
+		for _, b := range r.Breaks {
+			fmt.Fprintf(w, "  %s  %s: %s\n", format.PadRight(b.Object, width), b.Type, b.Reason)
+		}
+	}
+	if len(r.Skipped) > 0 {
+		fmt.Fprintln(w)
+		fmt.Fprintf(w, "Warning: %d resources could not be read and may hide relationships:\n", len(r.Skipped))
+		for _, s := range r.Skipped {
+			fmt.Fprintf(w, "  %s\n", s)
+		}
+	}
+	return w.Flush()
+}
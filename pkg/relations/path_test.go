@@ -0,0 +1,192 @@
+package relations
+
+import (
+	"7h3-3mp7y-m4n/kubectl-graph/pkg/format"
+	"7h3-3mp7y-m4n/kubectl-graph/pkg/inventory"
+	"7h3-3mp7y-m4n/kubectl-graph/pkg/inventory/inventorytest"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// An Ingress routes to a Service whose EndpointSlice targets a pod of a
+// ReplicaSet; the pod mounts a claim. The Service "lonely" selects nothing
+// and the ConfigMap is unrelated to anything.
+const cluster = `apiVersion: networking.k8s.io/v1
+kind: Ingress
+metadata: {name: web, namespace: default, uid: ing1}
+spec:
+  rules:
+  - http:
+      paths:
+      - {path: /, backend: {service: {name: web, port: {number: 80}}}}
+---
+apiVersion: v1
+kind: Service
+metadata: {name: web, namespace: default, uid: svc1}
+spec:
+  selector: {app: web}
+---
+apiVersion: discovery.k8s.io/v1
+kind: EndpointSlice
+metadata:
+  name: web-abc
+  namespace: default
+  uid: eps1
+  labels: {kubernetes.io/service-name: web}
+  ownerReferences:
+  - {apiVersion: v1, kind: Service, name: web, uid: svc1}
+endpoints:
+- targetRef: {kind: Pod, name: web-0}
+  conditions: {ready: true}
+---
+apiVersion: apps/v1
+kind: ReplicaSet
+metadata: {name: web-5d, namespace: default, uid: rs1}
+---
+apiVersion: v1
+kind: Pod
+metadata:
+  name: web-0
+  namespace: default
+  uid: p0
+  labels: {app: web}
+  ownerReferences:
+  - {apiVersion: apps/v1, kind: ReplicaSet, name: web-5d, uid: rs1, controller: true}
+spec:
+  volumes:
+  - {name: data, persistentVolumeClaim: {claimName: data}}
+---
+apiVersion: v1
+kind: PersistentVolumeClaim
+metadata: {name: data, namespace: default, uid: pvc1}
+spec:
+  volumeName: pv-data
+---
+apiVersion: v1
+kind: Service
+metadata: {name: lonely, namespace: default, uid: svc2}
+spec:
+  selector: {app: lonely}
+---
+apiVersion: v1
+kind: ConfigMap
+metadata: {name: settings, namespace: default, uid: cm1}`
+
+func hopStrings(hops []Hop) []string {
+	out := make([]string, len(hops))
+	for i, h := range hops {
+		out[i] = fmt.Sprintf("%s -%s-> %s", h.From, h.Type, h.To)
+		if h.Reverse {
+			out[i] += " (reverse)"
+		}
+	}
+	return out
+}
+
+func TestPath(t *testing.T) {
+	tests := []struct {
+		name     string
+		from, to string
+		want     []string
+	}{
+		{
+			// The EndpointSlice the cluster maintains is preferred over the
+			// Service's selector.
+			name: "ingress to pod",
+			from: "Ingress/default/web",
+			to:   "Pod/default/web-0",
+			want: []string{
+				"Ingress/default/web -ingressBackend-> Service/default/web",
+				"Service/default/web -ownerReference-> EndpointSlice/default/web-abc",
+				"EndpointSlice/default/web-abc -endpointTarget-> Pod/default/web-0",
+			},
+		},
+		{
+			name: "pod to owner",
+			from: "Pod/default/web-0",
+			to:   "ReplicaSet/default/web-5d",
+			want: []string{"Pod/default/web-0 -ownerReference-> ReplicaSet/default/web-5d (reverse)"},
+		},
+		{
+			name: "owner to claim",
+			from: "ReplicaSet/default/web-5d",
+			to:   "PersistentVolumeClaim/default/data",
+			want: []string{
+				"ReplicaSet/default/web-5d -ownerReference-> Pod/default/web-0",
+				"Pod/default/web-0 -volumeMount-> PersistentVolumeClaim/default/data",
+			},
+		},
+		{
+			name: "same object",
+			from: "Service/default/web",
+			to:   "Service/default/web",
+			want: []string{},
+		},
+	}
+	inv := inventory.New(inventorytest.Objects(t, cluster))
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := Path(inv, inventorytest.Find(t, inv, tt.from), inventorytest.Find(t, inv, tt.to))
+			if !r.Found {
+				t.Fatalf("no path found, breaks: %v", r.Breaks)
+			}
+			if got := hopStrings(r.Hops); !slices.Equal(got, tt.want) {
+				t.Errorf("hops =\n  %s\nwant\n  %s", strings.Join(got, "\n  "), strings.Join(tt.want, "\n  "))
+			}
+		})
+	}
+}
+
+func TestPathNotFound(t *testing.T) {
+	inv := inventory.New(inventorytest.Objects(t, cluster))
+	r := Path(inv, inventorytest.Find(t, inv, "Service/default/lonely"), inventorytest.Find(t, inv, "Pod/default/web-0"))
+	if r.Found {
+		t.Fatalf("found path %v", hopStrings(r.Hops))
+	}
+	if got := r.Reachable["Service/default/lonely"]; got != 0 {
+		t.Errorf("lonely reaches %d objects, want 0", got)
+	}
+	if got := r.Reachable["Pod/default/web-0"]; got != 5 {
+		t.Errorf("web-0 reaches %d objects, want 5", got)
+	}
+	want := []BrokenRef{{
+		Object: "Service/default/lonely",
+		Type:   format.EdgeTypeServiceSelector,
+		Reason: "selector app=lonely matches no pods",
+	}}
+	if !slices.Equal(r.Breaks, want) {
+		t.Errorf("breaks = %v, want %v", r.Breaks, want)
+	}
+}
+
+func TestFindBreaks(t *testing.T) {
+	// The missing volume is only a break once PersistentVolumes are known
+	// to be loaded, which inventory.New does not record.
+	inv := inventory.New(inventorytest.Objects(t, cluster+`
+---
+apiVersion: v1
+kind: Pod
+metadata: {name: orphan, namespace: default, uid: p1}
+spec:
+  volumes:
+  - {name: data, persistentVolumeClaim: {claimName: missing}}
+---
+apiVersion: data.fluid.io/v1alpha1
+kind: Dataset
+metadata: {name: imagenet, namespace: default, uid: ds1}`))
+	_, breaks := Find(inv)
+	var got []string
+	for _, b := range breaks {
+		got = append(got, fmt.Sprintf("%s %s: %s", b.From.ID(), b.Type, b.Reason))
+	}
+	want := []string{
+		"Service/default/lonely serviceSelector: selector app=lonely matches no pods",
+		"Pod/default/orphan volumeMount: claim 'missing' does not exist (spec.volumes[0].persistentVolumeClaim.claimName)",
+		"Dataset/default/imagenet runtimeBinding: no runtime is named 'imagenet'",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("breaks =\n  %s\nwant\n  %s", strings.Join(got, "\n  "), strings.Join(want, "\n  "))
+	}
+}
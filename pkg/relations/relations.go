@@ -0,0 +1,154 @@
+// Package relations finds the relationships between the objects of an
+// inventory, and the references that lead nowhere, to explain how two
+// objects are connected.
+package relations
+
+import (
+	"7h3-3mp7y-m4n/kubectl-graph/pkg/format"
+	"7h3-3mp7y-m4n/kubectl-graph/pkg/inventory"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// serviceNameLabel names the Service of an EndpointSlice.
+const serviceNameLabel = "kubernetes.io/service-name"
+
+// Relation is a directed relationship between two objects.
+type Relation struct {
+	From, To *inventory.Object
+	Type     format.EdgeType
+	Evidence string
+}
+
+// Break is a reference that leads nowhere, such as a selector that matches
+// no pods.
+type Break struct {
+	From   *inventory.Object
+	Type   format.EdgeType
+	Reason string
+}
+
+// Find returns the relationships between the objects of inv, in the order
+// of its objects, and the broken references it comes across.
+func Find(inv *inventory.Inventory) ([]Relation, []Break) {
+	var rels []Relation
+	var breaks []Break
+	rel := func(from, to *inventory.Object, t format.EdgeType, evidence string) {
+		rels = append(rels, Relation{From: from, To: to, Type: t, Evidence: evidence})
+	}
+	broken := func(from *inventory.Object, t format.EdgeType, format string, args ...interface{}) {
+		breaks = append(breaks, Break{From: from, Type: t, Reason: fmt.Sprintf(format, args...)})
+	}
+
+	for _, o := range inv.Objects() {
+		ns := o.GetNamespace()
+		for i, ref := range o.GetOwnerReferences() {
+			if owner, ok := inv.ByUID(ref.UID); ok {
+				rel(owner, o, format.EdgeTypeOwnerReference, fmt.Sprintf("metadata.ownerReferences[%d]", i))
+			}
+		}
+
+		switch {
+		case o.Is("networking.k8s.io", "Ingress"):
+			for _, b := range inventory.IngressBackends(o) {
+				if svc, ok := inv.Get("", "Service", ns, b.Service); ok {
+					rel(o, svc, format.EdgeTypeIngressBackend, b.Path)
+				} else {
+					broken(o, format.EdgeTypeIngressBackend, "backend Service '%s' does not exist (%s)", b.Service, b.Path)
+				}
+			}
+
+		case o.Is("", "Service"):
+			selector := inventory.Selector(o)
+			if len(selector) == 0 {
+				break
+			}
+			pods := inv.Select("", "Pod", ns, selector)
+			for _, pod := range pods {
+				rel(o, pod, format.EdgeTypeServiceSelector, "spec.selector "+inventory.FormatSelector(selector))
+			}
+			if len(pods) == 0 {
+				broken(o, format.EdgeTypeServiceSelector, "selector %s matches no pods", inventory.FormatSelector(selector))
+			}
+
+		case o.Is("discovery.k8s.io", "EndpointSlice"):
+			if name := o.GetLabels()[serviceNameLabel]; name != "" && !ownedBy(o, "Service", name) {
+				if svc, ok := inv.Get("", "Service", ns, name); ok {
+					rel(svc, o, format.EdgeTypeLabelSelector, serviceNameLabel+"="+name)
+				}
+			}
+			endpoints, _, _ := unstructured.NestedSlice(o.Object, "endpoints")
+			for i, e := range endpoints {
+				endpoint, _ := e.(map[string]interface{})
+				kind, _, _ := unstructured.NestedString(endpoint, "targetRef", "kind")
+				name, _, _ := unstructured.NestedString(endpoint, "targetRef", "name")
+				if kind != "Pod" || name == "" {
+					continue
+				}
+				evidence := fmt.Sprintf("endpoints[%d].targetRef", i)
+				if ready, found, _ := unstructured.NestedBool(endpoint, "conditions", "ready"); found && !ready {
+					evidence += " (not ready)"
+				}
+				if pod, ok := inv.Get("", "Pod", ns, name); ok {
+					rel(o, pod, format.EdgeTypeEndpointTarget, evidence)
+				} else {
+					broken(o, format.EdgeTypeEndpointTarget, "%s names Pod '%s', which does not exist", evidence, name)
+				}
+			}
+
+		case o.Is("", "Pod"):
+			for i, claim := range inventory.ClaimNames(o) {
+				if claim == "" {
+					continue
+				}
+				path := fmt.Sprintf("spec.volumes[%d].persistentVolumeClaim.claimName", i)
+				if pvc, ok := inv.Get("", "PersistentVolumeClaim", ns, claim); ok {
+					rel(o, pvc, format.EdgeTypeVolumeMount, path)
+				} else {
+					broken(o, format.EdgeTypeVolumeMount, "claim '%s' does not exist (%s)", claim, path)
+				}
+			}
+
+		case o.Is("", "PersistentVolumeClaim"):
+			name, _, _ := unstructured.NestedString(o.Object, "spec", "volumeName")
+			if name == "" {
+				broken(o, format.EdgeTypeVolumeBinding, "claim is not bound to a volume")
+			} else if pv, ok := inv.Get("", "PersistentVolume", "", name); ok {
+				rel(o, pv, format.EdgeTypeVolumeBinding, "spec.volumeName")
+			} else if inv.Loaded(schema.GroupResource{Resource: "persistentvolumes"}) {
+				broken(o, format.EdgeTypeVolumeBinding, "volume '%s' does not exist (spec.volumeName)", name)
+			}
+
+		case o.Is(inventory.FluidGroup, "Dataset"):
+			found := false
+			for _, rt := range inv.Objects() {
+				if rt.IsFluidRuntime() && rt.GetNamespace() == ns && rt.GetName() == o.GetName() {
+					rel(o, rt, format.EdgeTypeRuntimeBinding, "metadata.name="+o.GetName())
+					found = true
+				}
+			}
+			if !found {
+				broken(o, format.EdgeTypeRuntimeBinding, "no runtime is named '%s'", o.GetName())
+			}
+			selector := map[string]string{inventory.FluidDatasetLabel: o.GetName()}
+			for _, kind := range []string{"Pod", "PersistentVolumeClaim", "Service"} {
+				for _, labeled := range inv.Select("", kind, ns, selector) {
+					rel(o, labeled, format.EdgeTypeLabelSelector, inventory.FluidDatasetLabel+"="+o.GetName())
+				}
+			}
+		}
+	}
+	return rels, breaks
+}
+
+// ownedBy reports whether o has an owner reference to the named object.
+func ownedBy(o *inventory.Object, kind, name string) bool {
+	for _, ref := range o.GetOwnerReferences() {
+		if ref.Kind == kind && ref.Name == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,203 @@
+// Package server implements 'kubectl graph serve': graphs collected on
+// request and returned in any of several formats over HTTP, for dashboards
+// and tools that cannot run the plugin.
+package server
+
+import (
+	"7h3-3mp7y-m4n/kubectl-graph/pkg/client"
+	"7h3-3mp7y-m4n/kubectl-graph/pkg/collector"
+	"7h3-3mp7y-m4n/kubectl-graph/pkg/format"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"html/template"
+	"net/http"
+	"net/url"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// GraphPath is the pattern of the graph endpoint.
+const GraphPath = "/api/v1/graph/{type}/{namespace}/{name}"
+
+// collectTimeout bounds each collection, like the 30 seconds of inspect.
+const collectTimeout = 30 * time.Second
+
+// contentTypes lists the formats the graph endpoint serves. Formatters
+// taking an argument are left out, as are external ones: a request must
+// not pick a program or a template to run.
+var contentTypes = map[string]string{
+	"json":     "application/json",
+	"yaml":     "application/yaml",
+	"dot":      "text/vnd.graphviz; charset=utf-8",
+	"svg":      "image/svg+xml",
+	"mermaid":  "text/plain; charset=utf-8",
+	"html":     "text/html; charset=utf-8",
+	"markdown": "text/markdown; charset=utf-8",
+	"csv":      "text/csv; charset=utf-8",
+	"graphml":  "application/xml",
+	"gexf":     "application/xml",
+	"cypher":   "text/plain; charset=utf-8",
+	"name":     "text/plain; charset=utf-8",
+}
+
+// Formats returns the values the format query parameter accepts, in the
+// order the formatters are registered.
+func Formats() []string {
+	var formats []string
+	for _, info := range format.Formatters() {
+		if _, ok := contentTypes[info.Name]; ok {
+			formats = append(formats, info.Name)
+		}
+	}
+	return formats
+}
+
+// Server is an http.Handler serving:
+//
+//	GET /                                      an index page with a form
+//	GET /graph?type=&namespace=&name=&format=  a redirect to the graph endpoint
+//	GET /api/v1/graph/{type}/{namespace}/{name}?format=json
+type Server struct {
+	client      *client.Client
+	toolVersion string
+	mux         *http.ServeMux
+}
+
+// New returns a server collecting with c. toolVersion is recorded in the
+// metadata of the graphs it serves.
+func New(c *client.Client, toolVersion string) *Server {
+	s := &Server{client: c, toolVersion: toolVersion, mux: http.NewServeMux()}
+	s.mux.HandleFunc("GET /{$}", s.handleIndex)
+	s.mux.HandleFunc("GET /graph", s.handleForm)
+	s.mux.HandleFunc("GET "+GraphPath, s.handleGraph)
+	return s
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+func (s *Server) handleGraph(w http.ResponseWriter, r *http.Request) {
+	name := r.URL.Query().Get("format")
+	if name == "" {
+		name = "json"
+	}
+	contentType, ok := contentTypes[name]
+	if !ok {
+		http.Error(w, fmt.Sprintf("unsupported format '%s', use one of %v", name, Formats()), http.StatusBadRequest)
+		return
+	}
+	formatter, err := format.NewFormatter(name, format.Options{})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	c, err := collector.New(s.client, r.PathValue("type"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), collectTimeout)
+	defer cancel()
+	g, err := c.Collect(ctx, r.PathValue("namespace"), r.PathValue("name"))
+	if err != nil {
+		http.Error(w, err.Error(), statusForError(err))
+		return
+	}
+	g.Metadata.Cluster = s.client.Cluster
+	g.Metadata.Context = s.client.Context
+	g.Metadata.ToolVersion = s.toolVersion
+
+	// Formatting into a buffer first turns a formatter error into a 500
+	// rather than a truncated 200.
+	var buf bytes.Buffer
+	if err := formatter.Format(&buf, g); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Write(buf.Bytes())
+}
+
+// statusForError maps a collection error to an HTTP status.
+func statusForError(err error) int {
+	switch {
+	case apierrors.IsNotFound(err):
+		return http.StatusNotFound
+	case apierrors.IsForbidden(err), apierrors.IsUnauthorized(err):
+		return http.StatusForbidden
+	case apierrors.IsTimeout(err), apierrors.IsServerTimeout(err), errors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout
+	default:
+		return http.StatusBadGateway
+	}
+}
+
+// handleForm redirects the index page's form to the graph endpoint.
+func (s *Server) handleForm(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	for _, key := range []string{"type", "namespace", "name"} {
+		if q.Get(key) == "" {
+			http.Error(w, fmt.Sprintf("missing %s", key), http.StatusBadRequest)
+			return
+		}
+	}
+	http.Redirect(w, r, GraphURL(q.Get("type"), q.Get("namespace"), q.Get("name"), q.Get("format")), http.StatusFound)
+}
+
+// GraphURL returns the path and query of the graph endpoint for the named
+// resource, without a format parameter when format is empty.
+func GraphURL(resourceType, namespace, name, format string) string {
+	path := fmt.Sprintf("/api/v1/graph/%s/%s/%s",
+		url.PathEscape(resourceType), url.PathEscape(namespace), url.PathEscape(name))
+	if format == "" {
+		return path
+	}
+	return path + "?format=" + url.QueryEscape(format)
+}
+
+var indexTemplate = template.Must(template.New("index").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>kubectl graph</title>
+<style>
+body { font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", Helvetica, Arial, sans-serif; margin: 2em auto; max-width: 48em; color: #1f2328; }
+label { display: inline-block; width: 7em; }
+input, select { margin: 0.2em 0; padding: 0.2em; }
+code { background: #f6f8fa; padding: 0.1em 0.3em; }
+</style>
+</head>
+<body>
+<h1>kubectl graph</h1>
+<p>Collect the dependency graph of a resource{{with .Context}} in context <code>{{.}}</code>{{end}}.</p>
+<form action="/graph" method="get">
+<div><label for="type">Type</label><input id="type" name="type" value="dataset" required></div>
+<div><label for="namespace">Namespace</label><input id="namespace" name="namespace" value="default" required></div>
+<div><label for="name">Name</label><input id="name" name="name" required></div>
+<div><label for="format">Format</label><select id="format" name="format">{{range .Formats}}<option{{if eq . "svg"}} selected{{end}}>{{.}}</option>{{end}}</select></div>
+<div><label></label><input type="submit" value="Show graph"></div>
+</form>
+<h2>API</h2>
+<p><code>GET {{.GraphPath}}?format=FORMAT</code></p>
+<p>FORMAT is one of {{range $i, $f := .Formats}}{{if $i}}, {{end}}<code>{{$f}}</code>{{end}}; the default is <code>json</code>.
+Unknown resources return 404.</p>
+</body>
+</html>
+`))
+
+func (s *Server) handleIndex(w http.ResponseWriter, r *http.Request) {
+	data := struct {
+		Context   string
+		Formats   []string
+		GraphPath string
+	}{s.client.Context, Formats(), GraphPath}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := indexTemplate.Execute(w, data); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
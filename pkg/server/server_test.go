@@ -0,0 +1,117 @@
+package server
+
+import (
+	"7h3-3mp7y-m4n/kubectl-graph/pkg/client"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func newTestServer() *Server {
+	dataset := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "data.fluid.io/v1alpha1",
+		"kind":       "Dataset",
+		"metadata":   map[string]interface{}{"name": "imagenet", "namespace": "default"},
+		"status":     map[string]interface{}{"phase": "Bound"},
+	}}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "imagenet-worker-0",
+			Namespace: "default",
+			Labels:    map[string]string{"fluid.io/dataset": "imagenet"},
+		},
+		Status: corev1.PodStatus{Phase: corev1.PodRunning},
+	}
+	listKinds := map[schema.GroupVersionResource]string{
+		{Group: "data.fluid.io", Version: "v1alpha1", Resource: "datasets"}:        "DatasetList",
+		{Group: "data.fluid.io", Version: "v1alpha1", Resource: "alluxioruntimes"}: "AlluxioRuntimeList",
+	}
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), listKinds, dataset)
+	c := client.NewFromClients(fake.NewClientset(pod), dynamicClient)
+	c.Context = "test"
+	return New(c, "v0.0.0-test")
+}
+
+func TestGraph(t *testing.T) {
+	tests := []struct {
+		name        string
+		url         string
+		status      int
+		contentType string
+		contains    string
+	}{
+		{"default json", "/api/v1/graph/dataset/default/imagenet", http.StatusOK, "application/json", `"root": "Dataset/default/imagenet"`},
+		{"json", "/api/v1/graph/dataset/default/imagenet?format=json", http.StatusOK, "application/json", `"name": "imagenet-worker-0"`},
+		{"dot", "/api/v1/graph/dataset/default/imagenet?format=dot", http.StatusOK, "text/vnd.graphviz; charset=utf-8", "digraph"},
+		{"svg", "/api/v1/graph/dataset/default/imagenet?format=svg", http.StatusOK, "image/svg+xml", "<svg"},
+		{"mermaid", "/api/v1/graph/dataset/default/imagenet?format=mermaid", http.StatusOK, "text/plain; charset=utf-8", "flowchart LR"},
+		{"unknown format", "/api/v1/graph/dataset/default/imagenet?format=table", http.StatusBadRequest, "", "unsupported format 'table'"},
+		{"template format", "/api/v1/graph/dataset/default/imagenet?format=go-template%3D%7B%7B.%7D%7D", http.StatusBadRequest, "", "unsupported format"},
+		{"unknown type", "/api/v1/graph/deployment/default/imagenet", http.StatusBadRequest, "", "not supported"},
+		{"missing dataset", "/api/v1/graph/dataset/default/missing?format=svg", http.StatusNotFound, "", "not found"},
+		{"form", "/graph?type=dataset&namespace=default&name=imagenet&format=svg", http.StatusFound, "", ""},
+		{"form without name", "/graph?type=dataset&namespace=default", http.StatusBadRequest, "", "missing name"},
+	}
+	s := newTestServer()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.url, nil))
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d; body:\n%s", rec.Code, tt.status, rec.Body)
+			}
+			if tt.contentType != "" {
+				if got := rec.Header().Get("Content-Type"); got != tt.contentType {
+					t.Errorf("Content-Type = %q, want %q", got, tt.contentType)
+				}
+			}
+			if !strings.Contains(rec.Body.String(), tt.contains) {
+				t.Errorf("body does not contain %q:\n%s", tt.contains, rec.Body)
+			}
+		})
+	}
+}
+
+func TestFormRedirect(t *testing.T) {
+	rec := httptest.NewRecorder()
+	newTestServer().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/graph?type=dataset&namespace=my+ns&name=a/b&format=svg", nil))
+	want := "/api/v1/graph/dataset/my%20ns/a%2Fb?format=svg"
+	if got := rec.Header().Get("Location"); got != want {
+		t.Errorf("Location = %q, want %q", got, want)
+	}
+}
+
+func TestIndex(t *testing.T) {
+	rec := httptest.NewRecorder()
+	newTestServer().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	if got := rec.Header().Get("Content-Type"); got != "text/html; charset=utf-8" {
+		t.Errorf("Content-Type = %q", got)
+	}
+	body := rec.Body.String()
+	for _, want := range []string{`<form action="/graph"`, "<code>test</code>", "<option selected>svg</option>", GraphPath} {
+		if !strings.Contains(body, want) {
+			t.Errorf("index does not contain %q", want)
+		}
+	}
+	if strings.Contains(body, "<option>table</option>") {
+		t.Error("index offers the table format, which is not served")
+	}
+
+	rec = httptest.NewRecorder()
+	newTestServer().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/missing", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("GET /missing: status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
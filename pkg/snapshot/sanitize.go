@@ -0,0 +1,117 @@
+package snapshot
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// Redacted replaces the values Sanitize removes.
+const Redacted = "REDACTED"
+
+// lastAppliedAnnotation holds a copy of the object as applied, secrets
+// included.
+const lastAppliedAnnotation = "kubectl.kubernetes.io/last-applied-configuration"
+
+// sensitiveWords mark keys and names whose values are credentials, such
+// as AWS_SECRET_ACCESS_KEY or fs.oss.accessKeySecret.
+var sensitiveWords = []string{"password", "passwd", "secret", "token", "credential", "accesskey", "apikey", "privatekey"}
+
+// sensitive reports whether a key or name holds a credential. Keys ending
+// in "name" or "ref", such as secretName or secretKeyRef, refer to a
+// credential by name and are kept.
+func sensitive(key string) bool {
+	key = strings.ToLower(strings.NewReplacer("_", "", "-", "", ".", "").Replace(key))
+	if strings.HasSuffix(key, "name") || strings.HasSuffix(key, "ref") || strings.HasSuffix(key, "refs") {
+		return false
+	}
+	for _, w := range sensitiveWords {
+		if strings.Contains(key, w) {
+			return true
+		}
+	}
+	return false
+}
+
+// Sanitize removes what should not leave the cluster from u: managed
+// fields, the last applied configuration, the data of Secrets, and the
+// string values of keys and name/value pairs that look like credentials,
+// such as container env entries. It returns the fields it redacted.
+func Sanitize(u *unstructured.Unstructured) []string {
+	unstructured.RemoveNestedField(u.Object, "metadata", "managedFields")
+	var redacted []string
+	if annotations := u.GetAnnotations(); annotations[lastAppliedAnnotation] != "" {
+		delete(annotations, lastAppliedAnnotation)
+		if len(annotations) == 0 {
+			annotations = nil
+		}
+		u.SetAnnotations(annotations)
+		redacted = append(redacted, "metadata.annotations."+lastAppliedAnnotation)
+	}
+	if u.GetAPIVersion() == "v1" && u.GetKind() == "Secret" {
+		for _, field := range []string{"data", "stringData"} {
+			data, _, _ := unstructured.NestedMap(u.Object, field)
+			keys := make([]string, 0, len(data))
+			for k := range data {
+				data[k] = Redacted
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			for _, k := range keys {
+				redacted = append(redacted, field+"."+k)
+			}
+			if len(data) > 0 {
+				_ = unstructured.SetNestedMap(u.Object, data, field)
+			}
+		}
+	}
+	for _, field := range []string{"spec", "status"} {
+		if v, ok := u.Object[field]; ok {
+			redacted = append(redacted, redact(v, field)...)
+		}
+	}
+	id := u.GetKind() + "/" + u.GetName()
+	if u.GetNamespace() != "" {
+		id = u.GetKind() + "/" + u.GetNamespace() + "/" + u.GetName()
+	}
+	for i, field := range redacted {
+		redacted[i] = id + ": " + field
+	}
+	return redacted
+}
+
+// redact replaces sensitive string values below v, which is at path, and
+// returns the paths it replaced.
+func redact(v interface{}, path string) []string {
+	var redacted []string
+	switch v := v.(type) {
+	case map[string]interface{}:
+		// Name/value pairs, as in env and Fluid's options lists.
+		if name, ok := v["name"].(string); ok && sensitive(name) {
+			if _, ok := v["value"].(string); ok {
+				v["value"] = Redacted
+				redacted = append(redacted, path+".value")
+			}
+		}
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if s, ok := v[k].(string); ok && s != Redacted && sensitive(k) {
+				v[k] = Redacted
+				redacted = append(redacted, path+"."+k)
+				continue
+			}
+			redacted = append(redacted, redact(v[k], path+"."+k)...)
+		}
+	case []interface{}:
+		for i, item := range v {
+			redacted = append(redacted, redact(item, fmt.Sprintf("%s[%d]", path, i))...)
+		}
+	}
+	return redacted
+}
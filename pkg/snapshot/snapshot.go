@@ -0,0 +1,287 @@
+// Package snapshot reads and writes offline archives of a graph: the
+// graph itself, the sanitized objects it was built from and their events,
+// so that it can be inspected again without access to the cluster.
+//
+// An archive is a gzipped tar file laid out as
+//
+//	metadata.json                       Metadata
+//	graph.json                          the graph, as written by -o json
+//	objects/NAMESPACE/KIND/NAME.yaml    one file per object in the graph
+//	events/NAMESPACE/NAME.yaml          events about those objects
+//
+// Cluster-scoped objects are stored under the namespace "_cluster".
+package snapshot
+
+import (
+	"7h3-3mp7y-m4n/kubectl-graph/pkg/client"
+	"7h3-3mp7y-m4n/kubectl-graph/pkg/format"
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// APIVersion is the version of the archive layout.
+	APIVersion = "kubectl-graph.io/v1alpha1"
+	// Kind is the kind of metadata.json.
+	Kind = "Snapshot"
+
+	clusterScope = "_cluster"
+)
+
+// Metadata describes what a snapshot holds and where it was taken.
+type Metadata struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	// ResourceType, Namespace and Name are the arguments the graph was
+	// collected with.
+	ResourceType  string    `json:"resourceType"`
+	Namespace     string    `json:"namespace"`
+	Name          string    `json:"name"`
+	Cluster       string    `json:"cluster,omitempty"`
+	Context       string    `json:"context,omitempty"`
+	ServerVersion string    `json:"serverVersion,omitempty"`
+	CollectedAt   time.Time `json:"collectedAt"`
+	ToolVersion   string    `json:"toolVersion,omitempty"`
+	Objects       int       `json:"objects"`
+	Events        int       `json:"events"`
+	// Redacted lists the fields Sanitize replaced, as "ID: field".
+	Redacted []string `json:"redacted,omitempty"`
+}
+
+// Snapshot is the content of an archive.
+type Snapshot struct {
+	Metadata Metadata
+	Graph    *format.Graph
+	// Objects and Events are sanitized.
+	Objects []*unstructured.Unstructured
+	Events  []*unstructured.Unstructured
+}
+
+// Take builds a snapshot of g, which was collected for resourceType,
+// namespace and name with c. The objects of g are sanitized, and the
+// events about them are read from the cluster.
+func Take(ctx context.Context, c *client.Client, g *format.Graph, resourceType, namespace, name string) (*Snapshot, error) {
+	s := &Snapshot{
+		Metadata: Metadata{
+			APIVersion:   APIVersion,
+			Kind:         Kind,
+			ResourceType: resourceType,
+			Namespace:    namespace,
+			Name:         name,
+			Cluster:      g.Metadata.Cluster,
+			Context:      g.Metadata.Context,
+			CollectedAt:  g.Metadata.CollectedAt,
+			ToolVersion:  g.Metadata.ToolVersion,
+		},
+		Graph: g,
+	}
+	if v, err := c.Client.Discovery().ServerVersion(); err == nil {
+		s.Metadata.ServerVersion = v.GitVersion
+	}
+
+	uids := make(map[types.UID]bool)
+	namespaces := make(map[string]bool)
+	for _, res := range g.Nodes() {
+		if res.Object == nil {
+			continue
+		}
+		u := &unstructured.Unstructured{Object: runtime.DeepCopyJSON(res.Object)}
+		s.Metadata.Redacted = append(s.Metadata.Redacted, Sanitize(u)...)
+		s.Objects = append(s.Objects, u)
+		uids[u.GetUID()] = true
+		if u.GetNamespace() != "" {
+			namespaces[u.GetNamespace()] = true
+		}
+	}
+
+	for ns := range namespaces {
+		list, err := c.Client.CoreV1().Events(ns).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list events in namespace '%s': %w", ns, err)
+		}
+		for i := range list.Items {
+			e := &list.Items[i]
+			if !uids[e.InvolvedObject.UID] {
+				continue
+			}
+			obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(e)
+			if err != nil {
+				return nil, err
+			}
+			u := &unstructured.Unstructured{Object: obj}
+			u.SetAPIVersion("v1")
+			u.SetKind("Event")
+			unstructured.RemoveNestedField(u.Object, "metadata", "managedFields")
+			s.Events = append(s.Events, u)
+		}
+	}
+	sortEvents(s.Events)
+	s.Metadata.Objects = len(s.Objects)
+	s.Metadata.Events = len(s.Events)
+	return s, nil
+}
+
+// Client returns a client serving the objects and events of s from
+// memory, for the collectors to run against.
+func (s *Snapshot) Client() (*client.Client, error) {
+	objs := append(append([]*unstructured.Unstructured{}, s.Objects...), s.Events...)
+	return client.NewInMemory(objs)
+}
+
+// Write writes s to w as a gzipped tar archive.
+func (s *Snapshot) Write(w io.Writer) error {
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	add := func(name string, data []byte) error {
+		hdr := &tar.Header{
+			Name:    name,
+			Mode:    0o644,
+			Size:    int64(len(data)),
+			ModTime: s.Metadata.CollectedAt,
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		_, err := tw.Write(data)
+		return err
+	}
+
+	metadata, err := json.MarshalIndent(s.Metadata, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := add("metadata.json", append(metadata, '\n')); err != nil {
+		return err
+	}
+	var graph bytes.Buffer
+	if err := format.NewJSONFormatter().Format(&graph, s.Graph); err != nil {
+		return err
+	}
+	if err := add("graph.json", graph.Bytes()); err != nil {
+		return err
+	}
+	for _, u := range s.Objects {
+		data, err := yaml.Marshal(u.Object)
+		if err != nil {
+			return err
+		}
+		if err := add(path.Join("objects", scope(u), u.GetKind(), u.GetName()+".yaml"), data); err != nil {
+			return err
+		}
+	}
+	for _, u := range s.Events {
+		data, err := yaml.Marshal(u.Object)
+		if err != nil {
+			return err
+		}
+		if err := add(path.Join("events", scope(u), u.GetName()+".yaml"), data); err != nil {
+			return err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+// Read reads an archive written by Write.
+func Read(r io.Reader) (*Snapshot, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("not a snapshot archive: %w", err)
+	}
+	defer gz.Close()
+	tr := tar.NewReader(gz)
+	s := &Snapshot{}
+	var hasMetadata bool
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, err
+		}
+		name := path.Clean(hdr.Name)
+		switch {
+		case name == "metadata.json":
+			if err := json.Unmarshal(data, &s.Metadata); err != nil {
+				return nil, fmt.Errorf("invalid metadata.json: %w", err)
+			}
+			hasMetadata = true
+		case name == "graph.json":
+			doc, err := format.ReadDocument(bytes.NewReader(data))
+			if err != nil {
+				return nil, fmt.Errorf("invalid graph.json: %w", err)
+			}
+			if s.Graph, err = doc.Graph(); err != nil {
+				return nil, fmt.Errorf("invalid graph.json: %w", err)
+			}
+		case strings.HasPrefix(name, "objects/"), strings.HasPrefix(name, "events/"):
+			// Decoding through JSON keeps integers as int64, as the
+			// unstructured helpers expect.
+			data, err := yaml.YAMLToJSON(data)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s: %w", name, err)
+			}
+			u := &unstructured.Unstructured{}
+			if err := u.UnmarshalJSON(data); err != nil {
+				return nil, fmt.Errorf("invalid %s: %w", name, err)
+			}
+			if strings.HasPrefix(name, "events/") {
+				s.Events = append(s.Events, u)
+			} else {
+				s.Objects = append(s.Objects, u)
+			}
+		}
+	}
+	if !hasMetadata {
+		return nil, errors.New("not a snapshot archive: metadata.json is missing")
+	}
+	if s.Metadata.APIVersion != APIVersion || s.Metadata.Kind != Kind {
+		return nil, fmt.Errorf("unsupported snapshot %s %s, expected %s %s",
+			s.Metadata.APIVersion, s.Metadata.Kind, APIVersion, Kind)
+	}
+	return s, nil
+}
+
+func scope(u *unstructured.Unstructured) string {
+	if u.GetNamespace() == "" {
+		return clusterScope
+	}
+	return u.GetNamespace()
+}
+
+// sortEvents orders events by time, then name, for stable archives.
+func sortEvents(objs []*unstructured.Unstructured) {
+	sort.SliceStable(objs, func(i, j int) bool {
+		a, b := objs[i].GetCreationTimestamp(), objs[j].GetCreationTimestamp()
+		if !a.Equal(&b) {
+			return a.Before(&b)
+		}
+		return objs[i].GetName() < objs[j].GetName()
+	})
+}
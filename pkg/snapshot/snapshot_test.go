@@ -0,0 +1,337 @@
+package snapshot
+
+import (
+	"7h3-3mp7y-m4n/kubectl-graph/pkg/client"
+	"7h3-3mp7y-m4n/kubectl-graph/pkg/format"
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	"sigs.k8s.io/yaml"
+)
+
+func decode(t *testing.T, doc string) *unstructured.Unstructured {
+	t.Helper()
+	data, err := yaml.YAMLToJSON([]byte(doc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	u := &unstructured.Unstructured{}
+	if err := u.UnmarshalJSON(data); err != nil {
+		t.Fatal(err)
+	}
+	return u
+}
+
+func TestSanitize(t *testing.T) {
+	tests := []struct {
+		name     string
+		in       string
+		want     string
+		redacted []string
+	}{
+		{
+			name: "secret data",
+			in: `apiVersion: v1
+kind: Secret
+metadata: {name: creds, namespace: default}
+data: {password: cGFzcw==, user: dXNlcg==}
+stringData: {token: abc}`,
+			want: `apiVersion: v1
+kind: Secret
+metadata: {name: creds, namespace: default}
+data: {password: REDACTED, user: REDACTED}
+stringData: {token: REDACTED}`,
+			redacted: []string{
+				"Secret/default/creds: data.password",
+				"Secret/default/creds: data.user",
+				"Secret/default/creds: stringData.token",
+			},
+		},
+		{
+			name: "managed fields and last applied configuration",
+			in: `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+  namespace: default
+  managedFields: [{manager: kubectl}]
+  annotations: {kubectl.kubernetes.io/last-applied-configuration: '{}'}
+data: {password: kept}`,
+			want: `apiVersion: v1
+kind: ConfigMap
+metadata: {name: settings, namespace: default}
+data: {password: kept}`,
+			redacted: []string{"ConfigMap/default/settings: metadata.annotations.kubectl.kubernetes.io/last-applied-configuration"},
+		},
+		{
+			name: "env and options",
+			in: `apiVersion: data.fluid.io/v1alpha1
+kind: Dataset
+metadata: {name: imagenet, namespace: default}
+spec:
+  mounts:
+  - mountPoint: oss://bucket
+    options: {fs.oss.accessKeyId: AKID, fs.oss.accessKeySecret: shh, fs.oss.endpoint: oss.example.com}
+    encryptOptions:
+    - name: fs.oss.accessKeySecret
+      valueFrom: {secretKeyRef: {name: oss-creds, key: secret}}
+  env:
+  - {name: AWS_SECRET_ACCESS_KEY, value: shh}
+  - {name: LOG_LEVEL, value: debug}`,
+			want: `apiVersion: data.fluid.io/v1alpha1
+kind: Dataset
+metadata: {name: imagenet, namespace: default}
+spec:
+  mounts:
+  - mountPoint: oss://bucket
+    options: {fs.oss.accessKeyId: REDACTED, fs.oss.accessKeySecret: REDACTED, fs.oss.endpoint: oss.example.com}
+    encryptOptions:
+    - name: fs.oss.accessKeySecret
+      valueFrom: {secretKeyRef: {name: oss-creds, key: secret}}
+  env:
+  - {name: AWS_SECRET_ACCESS_KEY, value: REDACTED}
+  - {name: LOG_LEVEL, value: debug}`,
+			redacted: []string{
+				"Dataset/default/imagenet: spec.env[0].value",
+				"Dataset/default/imagenet: spec.mounts[0].options.fs.oss.accessKeyId",
+				"Dataset/default/imagenet: spec.mounts[0].options.fs.oss.accessKeySecret",
+			},
+		},
+		{
+			name: "cluster-scoped",
+			in: `apiVersion: v1
+kind: PersistentVolume
+metadata: {name: pv-data}
+spec:
+  csi: {driver: example.com, nodePublishSecretRef: {name: creds}, volumeAttributes: {token: abc}}`,
+			want: `apiVersion: v1
+kind: PersistentVolume
+metadata: {name: pv-data}
+spec:
+  csi: {driver: example.com, nodePublishSecretRef: {name: creds}, volumeAttributes: {token: REDACTED}}`,
+			redacted: []string{"PersistentVolume/pv-data: spec.csi.volumeAttributes.token"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u := decode(t, tt.in)
+			redacted := Sanitize(u)
+			if !slices.Equal(redacted, tt.redacted) {
+				t.Errorf("redacted =\n  %s\nwant\n  %s", strings.Join(redacted, "\n  "), strings.Join(tt.redacted, "\n  "))
+			}
+			got, _ := yaml.Marshal(u.Object)
+			want, _ := yaml.Marshal(decode(t, tt.want).Object)
+			if !bytes.Equal(got, want) {
+				t.Errorf("sanitized object =\n%s\nwant\n%s", got, want)
+			}
+		})
+	}
+}
+
+func TestSensitive(t *testing.T) {
+	for key, want := range map[string]bool{
+		"password":                true,
+		"AWS_SECRET_ACCESS_KEY":   true,
+		"fs.oss.accessKeySecret":  true,
+		"api-key":                 true,
+		"secretName":              false,
+		"secretKeyRef":            false,
+		"nodePublishSecretRef":    false,
+		"tokenRefs":               false,
+		"fs.oss.endpoint":         false,
+		"serviceAccountTokenName": false,
+	} {
+		if got := sensitive(key); got != want {
+			t.Errorf("sensitive(%q) = %v, want %v", key, got, want)
+		}
+	}
+}
+
+// testSnapshot takes a snapshot of a dataset graph, with one event about
+// the dataset and one about an object outside the graph.
+func testSnapshot(t *testing.T) *Snapshot {
+	t.Helper()
+	dataset := decode(t, `apiVersion: data.fluid.io/v1alpha1
+kind: Dataset
+metadata:
+  name: imagenet
+  namespace: default
+  uid: ds1
+  creationTimestamp: "2024-05-01T10:00:00Z"
+  managedFields: [{manager: fluid}]
+spec:
+  mounts:
+  - {mountPoint: "s3://bucket", options: {aws.secretKey: shh}}
+status: {phase: Bound}`)
+	collectedAt := time.Date(2024, 5, 3, 12, 0, 0, 0, time.UTC)
+	root := &format.Resource{
+		Type:              format.ResourceTypeDataset,
+		Kind:              "Dataset",
+		Name:              "imagenet",
+		Namespace:         "default",
+		Status:            "Bound",
+		CreationTimestamp: dataset.GetCreationTimestamp().Time,
+		Object:            dataset.Object,
+	}
+	g := format.NewGraph(root)
+	g.Metadata = format.Metadata{Cluster: "kind", Context: "kind-kind", CollectedAt: collectedAt, ToolVersion: "v1.0.0"}
+
+	event := func(name string, at time.Time) *corev1.Event {
+		return &corev1.Event{
+			ObjectMeta:     metav1.ObjectMeta{Name: name, Namespace: "default", CreationTimestamp: metav1.NewTime(at)},
+			InvolvedObject: corev1.ObjectReference{Kind: "Dataset", Name: "imagenet", UID: "ds1"},
+			Reason:         "Bound",
+		}
+	}
+	other := event("other.1", collectedAt)
+	other.InvolvedObject.UID = "other"
+	clientset := fake.NewClientset(
+		event("imagenet.2", collectedAt.Add(-time.Minute)),
+		event("imagenet.1", collectedAt.Add(-time.Hour)),
+		other,
+	)
+	c := client.NewFromClients(clientset, dynamicfake.NewSimpleDynamicClient(runtime.NewScheme()))
+	s, err := Take(context.Background(), c, g, "dataset", "default", "imagenet")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestTake(t *testing.T) {
+	s := testSnapshot(t)
+	if s.Metadata.Objects != 1 || s.Metadata.Events != 2 {
+		t.Fatalf("took %d objects and %d events, want 1 and 2", s.Metadata.Objects, s.Metadata.Events)
+	}
+	if got := []string{s.Events[0].GetName(), s.Events[1].GetName()}; !slices.Equal(got, []string{"imagenet.1", "imagenet.2"}) {
+		t.Errorf("events = %v, want them oldest first", got)
+	}
+	if want := []string{"Dataset/default/imagenet: spec.mounts[0].options.aws.secretKey"}; !slices.Equal(s.Metadata.Redacted, want) {
+		t.Errorf("redacted = %v, want %v", s.Metadata.Redacted, want)
+	}
+	// The graph keeps the objects it was collected with; only the archived
+	// copies are sanitized.
+	if _, found, _ := unstructured.NestedSlice(s.Graph.Root.Object, "metadata", "managedFields"); !found {
+		t.Error("Take sanitized the graph's own object")
+	}
+}
+
+func TestWriteRead(t *testing.T) {
+	s := testSnapshot(t)
+	var buf bytes.Buffer
+	if err := s.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	names := archiveNames(t, buf.Bytes())
+	want := []string{
+		"metadata.json",
+		"graph.json",
+		"objects/default/Dataset/imagenet.yaml",
+		"events/default/imagenet.1.yaml",
+		"events/default/imagenet.2.yaml",
+	}
+	if !slices.Equal(names, want) {
+		t.Errorf("archive holds %v, want %v", names, want)
+	}
+
+	read, err := Read(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if read.Metadata.Name != "imagenet" || read.Metadata.ResourceType != "dataset" || read.Metadata.Context != "kind-kind" {
+		t.Errorf("metadata = %+v", read.Metadata)
+	}
+	if !read.Metadata.CollectedAt.Equal(s.Metadata.CollectedAt) {
+		t.Errorf("collectedAt = %v, want %v", read.Metadata.CollectedAt, s.Metadata.CollectedAt)
+	}
+	if read.Graph == nil || read.Graph.Root == nil || read.Graph.Root.ID() != "Dataset/default/imagenet" {
+		t.Fatalf("graph root = %v", read.Graph)
+	}
+	if len(read.Objects) != 1 || len(read.Events) != 2 {
+		t.Fatalf("read %d objects and %d events, want 1 and 2", len(read.Objects), len(read.Events))
+	}
+	mounts, _, _ := unstructured.NestedSlice(read.Objects[0].Object, "spec", "mounts")
+	if got, _, _ := unstructured.NestedString(mounts[0].(map[string]interface{}), "options", "aws.secretKey"); got != Redacted {
+		t.Errorf("archived secret = %q, want %q", got, Redacted)
+	}
+
+	// The objects and events are served again by the in-memory client.
+	c, err := read.Client()
+	if err != nil {
+		t.Fatal(err)
+	}
+	events, err := c.Client.CoreV1().Events("default").List(context.Background(), metav1.ListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events.Items) != 2 {
+		t.Errorf("client serves %d events, want 2", len(events.Items))
+	}
+}
+
+func TestReadErrors(t *testing.T) {
+	archive := func(files map[string]string) []byte {
+		var buf bytes.Buffer
+		gz := gzip.NewWriter(&buf)
+		tw := tar.NewWriter(gz)
+		for name, data := range files {
+			tw.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: int64(len(data))})
+			tw.Write([]byte(data))
+		}
+		tw.Close()
+		gz.Close()
+		return buf.Bytes()
+	}
+	tests := []struct {
+		name string
+		data []byte
+		want string
+	}{
+		{"not gzip", []byte("apiVersion: v1"), "not a snapshot archive"},
+		{"no metadata", archive(map[string]string{"objects/default/Pod/a.yaml": "kind: Pod"}), "metadata.json is missing"},
+		{"invalid metadata", archive(map[string]string{"metadata.json": "{"}), "invalid metadata.json"},
+		{"other kind", archive(map[string]string{"metadata.json": `{"apiVersion": "v1", "kind": "List"}`}), "unsupported snapshot v1 List"},
+		{"invalid object", archive(map[string]string{
+			"metadata.json":              `{"apiVersion": "kubectl-graph.io/v1alpha1", "kind": "Snapshot"}`,
+			"objects/default/Pod/a.yaml": "kind: Pod\nmetadata: [",
+		}), "invalid objects/default/Pod/a.yaml"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Read(bytes.NewReader(tt.data))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Read = %v, want an error containing %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func archiveNames(t *testing.T, data []byte) []string {
+	t.Helper()
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr := tar.NewReader(gz)
+	var names []string
+	for {
+		hdr, err := tr.Next()
+		if err != nil {
+			break
+		}
+		names = append(names, hdr.Name)
+	}
+	return names
+}